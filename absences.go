@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"trackyou/config"
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// dayOffLabel names why day has no target: its holiday or absence, or just
+// "day off".
+func dayOffLabel(schedule models.WorkSchedule, day time.Time) string {
+	if h, ok := schedule.HolidayOn(day); ok && h.Name != "" {
+		return h.Name
+	}
+	if absences := schedule.AbsencesOn(day); len(absences) > 0 {
+		return models.AbsenceLabel(absences[0].Type)
+	}
+	return "day off"
+}
+
+// makeAbsencesView returns the Absences tab: the allowance left of each
+// absence type in one year and the absences recorded in it.
+func (a *App) makeAbsencesView() fyne.CanvasObject {
+	a.absenceYearLabel = widget.NewLabel("")
+	a.absenceYearLabel.TextStyle = fyne.TextStyle{Bold: true}
+	a.absenceBalances = container.NewGridWithColumns(5)
+	a.absenceRows = container.NewVBox()
+
+	previous := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		a.absenceYear--
+		a.refreshAbsencesView()
+	})
+	next := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() {
+		a.absenceYear++
+		a.refreshAbsencesView()
+	})
+	current := widget.NewButton("This Year", func() {
+		a.absenceYear = 0
+		a.refreshAbsencesView()
+	})
+	allowances := widget.NewButton("Allowances…", a.showAllowancesDialog)
+	record := widget.NewButtonWithIcon("Record Absence…", theme.ContentAddIcon(), a.showAbsenceDialog)
+
+	header := container.NewVBox(
+		container.NewHBox(previous, a.absenceYearLabel, next, current, layout.NewSpacer(), allowances, record),
+		a.absenceBalances,
+		widget.NewSeparator(),
+	)
+	return container.NewBorder(header, nil, nil, nil, container.NewVScroll(a.absenceRows))
+}
+
+// refreshAbsencesView redraws the Absences tab for the year it shows.
+func (a *App) refreshAbsencesView() {
+	if a.absenceRows == nil {
+		return
+	}
+	year := models.PeriodOf(models.PeriodYear, time.Now(), time.Monday).Shift(a.absenceYear)
+	a.absenceYearLabel.SetText(year.Label())
+
+	a.mu.RLock()
+	schedule, policies := a.schedule, a.prefs.AbsencePolicies
+	a.mu.RUnlock()
+
+	a.absenceBalances.RemoveAll()
+	for _, text := range []string{"Type", "Allowance", "Carried Over", "Taken", "Left"} {
+		label := widget.NewLabel(text)
+		label.TextStyle = fyne.TextStyle{Bold: true}
+		a.absenceBalances.Add(label)
+	}
+	for _, absenceType := range models.AbsenceTypes {
+		b := models.ComputeAbsenceBalance(schedule, absenceType, policies[absenceType], year.Start.Year())
+		left := "–"
+		if b.Allowance > 0 || b.CarriedOver > 0 {
+			left = formatDays(b.Remaining())
+		}
+		for _, text := range []string{models.AbsenceLabel(absenceType), formatDays(b.Allowance), formatDays(b.CarriedOver), formatDays(b.Taken), left} {
+			a.absenceBalances.Add(widget.NewLabel(text))
+		}
+	}
+	a.absenceBalances.Refresh()
+
+	a.absenceRows.RemoveAll()
+	for _, absence := range schedule.Absences {
+		if year.Contains(absence.Date) {
+			a.absenceRows.Add(a.absenceRow(absence))
+		}
+	}
+	if len(a.absenceRows.Objects) == 0 {
+		empty := widget.NewLabel("No absences recorded in this year.")
+		empty.Importance = widget.LowImportance
+		a.absenceRows.Add(empty)
+	}
+	a.absenceRows.Refresh()
+}
+
+// formatDays formats a number of days without a unit, e.g. "2.5".
+func formatDays(days float64) string {
+	return strconv.FormatFloat(days, 'f', -1, 64)
+}
+
+// absenceRow shows an absence with a button to remove it.
+func (a *App) absenceRow(absence models.Absence) fyne.CanvasObject {
+	text := absence.Date.Format("Mon, Jan 2") + " · " + models.AbsenceLabel(absence.Type)
+	if absence.Half {
+		text += " (half day)"
+	}
+	if absence.Note != "" {
+		text += " · " + absence.Note
+	}
+	remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		if err := a.removeAbsence(absence.ID); err != nil {
+			a.showDialogError(err)
+		}
+	})
+	remove.Importance = widget.LowImportance
+	return container.NewHBox(widget.NewLabel(text), layout.NewSpacer(), remove)
+}
+
+// showAbsenceDialog asks for a day or range of days away.
+func (a *App) showAbsenceDialog() {
+	fromEntry := widget.NewEntry()
+	fromEntry.SetPlaceHolder("YYYY-MM-DD")
+	fromEntry.SetText(time.Now().Format(time.DateOnly))
+	toEntry := widget.NewEntry()
+	toEntry.SetPlaceHolder("YYYY-MM-DD (empty = one day)")
+
+	labels := make([]string, len(models.AbsenceTypes))
+	for i, absenceType := range models.AbsenceTypes {
+		labels[i] = models.AbsenceLabel(absenceType)
+	}
+	typeSelect := widget.NewSelect(labels, nil)
+	typeSelect.SetSelected(labels[0])
+	halfCheck := widget.NewCheck("Half day", nil)
+	noteEntry := widget.NewEntry()
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("From", fromEntry),
+		widget.NewFormItem("To", toEntry),
+		widget.NewFormItem("Type", typeSelect),
+		widget.NewFormItem("", halfCheck),
+		widget.NewFormItem("Note", noteEntry),
+	}
+	dialog.ShowForm("Record Absence", "Record", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		absenceType := models.AbsenceTypes[typeSelect.SelectedIndex()]
+		if err := a.addAbsences(fromEntry.Text, toEntry.Text, absenceType, halfCheck.Checked, noteEntry.Text); err != nil {
+			a.showDialogError(err)
+		}
+	}, a.window)
+}
+
+// addAbsences records an absence of absenceType on each workday from first
+// through last, given as YYYY-MM-DD. An empty last records first alone,
+// whether it is a workday or not.
+func (a *App) addAbsences(first, last, absenceType string, half bool, note string) error {
+	from, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(first), time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid first day %q, expected YYYY-MM-DD", first)
+	}
+	to := from
+	if strings.TrimSpace(last) != "" {
+		to, err = time.ParseInLocation(time.DateOnly, strings.TrimSpace(last), time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid last day %q, expected YYYY-MM-DD", last)
+		}
+	}
+	if to.Before(from) {
+		return models.ErrInvalidPeriod
+	}
+
+	a.mu.RLock()
+	schedule := a.schedule
+	a.mu.RUnlock()
+	single := from.Equal(to)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !single && !schedule.Workday(day) {
+			continue
+		}
+		absence := models.Absence{Date: day, Type: absenceType, Half: half, Note: strings.TrimSpace(note)}
+		if err := a.db.AddAbsence(&absence); err != nil {
+			return err
+		}
+	}
+	a.reloadPreferences()
+	return nil
+}
+
+// removeAbsence deletes a recorded absence.
+func (a *App) removeAbsence(id int64) error {
+	if err := a.db.DeleteAbsence(id); err != nil {
+		return err
+	}
+	a.reloadPreferences()
+	return nil
+}
+
+// showAllowancesDialog edits the yearly allowance and carry-over rule of
+// each absence type.
+func (a *App) showAllowancesDialog() {
+	a.mu.RLock()
+	policies := a.prefs.AbsencePolicies
+	a.mu.RUnlock()
+
+	type policyEntries struct{ allowance, carryOver *widget.Entry }
+	entries := make(map[string]policyEntries, len(models.AbsenceTypes))
+	var items []*widget.FormItem
+	for _, absenceType := range models.AbsenceTypes {
+		e := policyEntries{widget.NewEntry(), widget.NewEntry()}
+		e.allowance.SetText(formatDays(policies[absenceType].Allowance))
+		e.carryOver.SetText(formatDays(policies[absenceType].CarryOver))
+		entries[absenceType] = e
+
+		label := models.AbsenceLabel(absenceType)
+		for _, field := range []struct {
+			label, key string
+			entry      *widget.Entry
+		}{
+			{label + " (days/year)", config.AbsenceAllowanceKey(absenceType), e.allowance},
+			{label + " Carry-Over (days)", config.AbsenceCarryOverKey(absenceType), e.carryOver},
+		} {
+			// Values set in config.toml are edited in the file instead.
+			if a.preferenceFromFile(field.key) {
+				field.entry.Disable()
+			}
+			item := widget.NewFormItem(field.label, field.entry)
+			item.HintText = a.preferenceHint(field.key)
+			items = append(items, item)
+		}
+	}
+
+	dialog.ShowForm("Absence Allowances", "Save", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		defer a.reloadPreferences()
+		for _, absenceType := range models.AbsenceTypes {
+			e := entries[absenceType]
+			if e.allowance.Disabled() && e.carryOver.Disabled() {
+				continue
+			}
+			// Values set in config.toml keep their saved values underneath.
+			policy, _ := a.db.GetAbsencePolicy(absenceType)
+			for _, field := range []struct {
+				entry *widget.Entry
+				days  *float64
+			}{
+				{e.allowance, &policy.Allowance},
+				{e.carryOver, &policy.CarryOver},
+			} {
+				if field.entry.Disabled() {
+					continue
+				}
+				days, err := strconv.ParseFloat(strings.TrimSpace(field.entry.Text), 64)
+				if err != nil {
+					a.showDialogError(fmt.Errorf("invalid number of days %q", field.entry.Text))
+					return
+				}
+				*field.days = days
+			}
+			if err := a.db.SetAbsencePolicy(absenceType, policy); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+	}, a.window)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"trackyou/models"
+	"trackyou/ui"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// allProjects is the Activity tab's filter option counting every project.
+const allProjects = "All Projects"
+
+// activityHint is shown below the heatmap while no day is hovered.
+const activityHint = "Hover over a day to see its time, or click it to open its entries in the Log."
+
+// makeActivityView returns the Activity tab: a heatmap of the time tracked
+// per day over the past year, for one project or all of them.
+func (a *App) makeActivityView() fyne.CanvasObject {
+	a.activityTotals = widget.NewLabel("")
+	a.activityDay = widget.NewLabel(activityHint)
+	a.activityDay.Importance = widget.LowImportance
+	a.activityHeatmap = container.NewStack()
+	a.activityProject = widget.NewSelect([]string{allProjects}, func(string) {
+		a.refreshActivityView()
+	})
+	a.activityProject.SetSelected(allProjects)
+
+	header := container.NewHBox(widget.NewLabel("Project"), a.activityProject, layout.NewSpacer(), a.activityTotals)
+	return container.NewBorder(header, nil, nil, nil,
+		container.NewVBox(container.NewHScroll(a.activityHeatmap), a.activityDay))
+}
+
+// refreshActivityView redraws the heatmap of the Activity tab.
+func (a *App) refreshActivityView() {
+	if a.activityHeatmap == nil {
+		return
+	}
+	if names, err := a.db.GetProjectNames(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load project names: %v\n", err)
+	} else {
+		a.activityProject.SetOptions(append([]string{allProjects}, names...))
+	}
+	project := a.activityProject.Selected
+	if project == allProjects {
+		project = ""
+	}
+
+	a.mu.RLock()
+	first, last := models.HeatmapRange(time.Now(), a.prefs.WeekStart)
+	days := models.ComputeHeatmap(a.tasks, a.schedule, first, last, project)
+	a.mu.RUnlock()
+
+	var total time.Duration
+	var tracked int
+	for _, day := range days {
+		total += day.Tracked
+		if day.Tracked > 0 {
+			tracked++
+		}
+	}
+	unit := "days"
+	if tracked == 1 {
+		unit = "day"
+	}
+	a.activityTotals.SetText(fmt.Sprintf("%s on %d %s in the past year", models.FormatEstimate(total), tracked, unit))
+
+	heatmap := ui.NewHeatmap(days)
+	heatmap.OnHovered = func(day models.HeatmapDay, ok bool) {
+		if !ok {
+			a.activityDay.SetText(activityHint)
+			return
+		}
+		a.activityDay.SetText(fmt.Sprintf("%s: %s of %s", day.Date.Format("Monday, January 2, 2006"),
+			models.FormatEstimate(day.Tracked), models.FormatEstimate(day.Goal)))
+	}
+	heatmap.OnTapped = func(day models.HeatmapDay) {
+		a.showLogDay(day.Date)
+	}
+	a.activityHeatmap.Objects = []fyne.CanvasObject{heatmap}
+	a.activityHeatmap.Refresh()
+}
+
+// showLogDay switches to the Log and scrolls to the entries started on day,
+// or on the closest day before it.
+func (a *App) showLogDay(day time.Time) {
+	a.mu.RLock()
+	i := models.DayHeaderIndex(a.flatItems, day)
+	a.mu.RUnlock()
+	a.tabs.SelectIndex(0)
+	if i >= 0 {
+		a.taskList.ScrollTo(i)
+	}
+}
@@ -0,0 +1,551 @@
+// Package api exposes TrackYou's timer, tasks and summaries over a small
+// loopback-only HTTP API protected by a bearer token.
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"trackyou/models"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// Sentinel errors a Backend can wrap so handlers can pick a status code.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflict")
+	ErrInvalid  = errors.New("invalid request")
+)
+
+// Status describes the timer state reported by GET /status.
+type Status struct {
+	Current     *models.Task
+	TotalToday  time.Duration
+	WorkdayGoal float64
+}
+
+// Backend is the application logic the API drives. The GUI implements it so
+// both share the same code paths for starting, stopping and editing tasks.
+type Backend interface {
+	Status() Status
+	StartTimer(projectName, description string, estimate time.Duration) (*models.Task, error)
+	StopTimer() (*models.Task, error)
+	Tasks(from, to time.Time) ([]*models.Task, error)
+	Task(id int64) (*models.Task, error)
+	CreateTask(task *models.Task) error
+	UpdateTask(task *models.Task) error
+	DeleteTask(id int64) error
+	ProjectNames() ([]string, error)
+	// WeekStart is the first day of the week, which starts the default
+	// summary window.
+	WeekStart() time.Weekday
+	// Summaries totals the projects over [from, to) in day buckets, rounded
+	// by the configured rounding rules when rounded is set.
+	Summaries(from, to time.Time, rounded bool) (models.Summary, error)
+}
+
+// Server serves the REST API for a Backend.
+type Server struct {
+	backend Backend
+	token   string
+	now     func() time.Time
+	http    *http.Server
+}
+
+// NewServer creates a server that authenticates requests against token.
+func NewServer(backend Backend, token string) *Server {
+	return &Server{backend: backend, token: token, now: time.Now}
+}
+
+// NewToken returns a random hex-encoded bearer token.
+func NewToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// LoopbackAddr returns the listen address for port on the IPv4 loopback
+// interface. The API is never bound to external interfaces.
+func LoopbackAddr(port int) string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+}
+
+// Start begins listening on the loopback port and serves requests in the
+// background until Shutdown is called.
+func (s *Server) Start(port int) error {
+	listener, err := net.Listen("tcp", LoopbackAddr(port))
+	if err != nil {
+		return err
+	}
+	s.http = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := s.http.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "API server stopped: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops a server started with Start.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.http == nil {
+		return nil
+	}
+	return s.http.Shutdown(ctx)
+}
+
+// Handler returns the routed, authenticated HTTP handler.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.yaml", s.handleOpenAPI)
+	mux.Handle("GET /status", s.authenticated(s.handleStatus))
+	mux.Handle("POST /timer/start", s.authenticated(s.handleTimerStart))
+	mux.Handle("POST /timer/stop", s.authenticated(s.handleTimerStop))
+	mux.Handle("GET /tasks", s.authenticated(s.handleListTasks))
+	mux.Handle("POST /tasks", s.authenticated(s.handleCreateTask))
+	mux.Handle("GET /tasks/{id}", s.authenticated(s.handleGetTask))
+	mux.Handle("PUT /tasks/{id}", s.authenticated(s.handleUpdateTask))
+	mux.Handle("DELETE /tasks/{id}", s.authenticated(s.handleDeleteTask))
+	mux.Handle("GET /projects", s.authenticated(s.handleProjects))
+	mux.Handle("GET /summaries", s.authenticated(s.handleSummaries))
+	return mux
+}
+
+func (s *Server) authenticated(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="trackyou"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next(w, r)
+	})
+}
+
+// taskJSON is the wire representation of a task.
+type taskJSON struct {
+	ID              int64     `json:"id"`
+	Project         string    `json:"project"`
+	Description     string    `json:"description"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds int64     `json:"duration_seconds"`
+	EstimateSeconds int64     `json:"estimate_seconds,omitempty"`
+}
+
+func newTaskJSON(task *models.Task) taskJSON {
+	return taskJSON{
+		ID:              task.ID,
+		Project:         task.ProjectName,
+		Description:     task.Description,
+		Start:           task.StartTime,
+		End:             task.EndTime,
+		DurationSeconds: seconds(task.Duration),
+		EstimateSeconds: seconds(task.Estimate),
+	}
+}
+
+type statusJSON struct {
+	Running           bool      `json:"running"`
+	Paused            bool      `json:"paused"`
+	Task              *taskJSON `json:"task,omitempty"`
+	ElapsedSeconds    int64     `json:"elapsed_seconds"`
+	TotalTodaySeconds int64     `json:"total_today_seconds"`
+	WorkdayGoalHours  float64   `json:"workday_goal_hours"`
+}
+
+type timerStartRequest struct {
+	Project         string `json:"project"`
+	Description     string `json:"description"`
+	EstimateSeconds int64  `json:"estimate_seconds"`
+}
+
+type taskRequest struct {
+	Project     string     `json:"project"`
+	Description string     `json:"description"`
+	Start       time.Time  `json:"start"`
+	End         *time.Time `json:"end"`
+}
+
+type summaryJSON struct {
+	Project                string  `json:"project"`
+	DurationSeconds        int64   `json:"duration_seconds"`
+	DailySeconds           []int64 `json:"daily_seconds"`
+	Percentage             float64 `json:"percentage"`
+	EstimatedSeconds       int64   `json:"estimated_seconds,omitempty"`
+	EstimatedActualSeconds int64   `json:"estimated_actual_seconds,omitempty"`
+
+	// Set when comparing with another window.
+	PreviousSeconds *int64   `json:"previous_seconds,omitempty"`
+	ChangeSeconds   *int64   `json:"change_seconds,omitempty"`
+	ChangePercent   *float64 `json:"change_percent,omitempty"` // unset for new projects
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(openAPISpec)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	status := s.backend.Status()
+	resp := statusJSON{
+		Running:           status.Current != nil,
+		TotalTodaySeconds: seconds(status.TotalToday),
+		WorkdayGoalHours:  status.WorkdayGoal,
+	}
+	if status.Current != nil {
+		task := newTaskJSON(status.Current)
+		resp.Task = &task
+		resp.Paused = status.Current.IsPaused()
+		resp.ElapsedSeconds = seconds(status.Current.Elapsed(s.now()))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleTimerStart(w http.ResponseWriter, r *http.Request) {
+	var req timerStartRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.EstimateSeconds < 0 {
+		writeError(w, http.StatusBadRequest, errors.New("estimate_seconds must not be negative"))
+		return
+	}
+	estimate := time.Duration(req.EstimateSeconds) * time.Second
+	task, err := s.backend.StartTimer(strings.TrimSpace(req.Project), req.Description, estimate)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newTaskJSON(task))
+}
+
+func (s *Server) handleTimerStop(w http.ResponseWriter, _ *http.Request) {
+	task, err := s.backend.StopTimer()
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newTaskJSON(task))
+}
+
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseRange(r, time.Time{})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	tasks, err := s.backend.Tasks(from, to)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	resp := make([]taskJSON, 0, len(tasks))
+	for _, task := range tasks {
+		resp = append(resp, newTaskJSON(task))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
+	task, err := decodeTask(w, r, &models.Task{})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.backend.CreateTask(task); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newTaskJSON(task))
+}
+
+func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	task, err := s.backend.Task(id)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newTaskJSON(task))
+}
+
+func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	existing, err := s.backend.Task(id)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	updated := *existing
+	task, err := decodeTask(w, r, &updated)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.backend.UpdateTask(task); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newTaskJSON(task))
+}
+
+func (s *Server) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.backend.DeleteTask(id); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleProjects(w http.ResponseWriter, _ *http.Request) {
+	names, err := s.backend.ProjectNames()
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	if names == nil {
+		names = []string{}
+	}
+	writeJSON(w, http.StatusOK, names)
+}
+
+func (s *Server) handleSummaries(w http.ResponseWriter, r *http.Request) {
+	now := s.now()
+	from, to, err := parseRange(r, now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	thisWeek := from.IsZero()
+	if thisWeek {
+		from = models.StartOfWeek(now, s.backend.WeekStart())
+	}
+	if !to.After(from) {
+		writeError(w, http.StatusBadRequest, errors.New("to must be after from"))
+		return
+	}
+	rounded, err := parseBoolParam(r, "rounded")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	comparison, err := models.ParseComparison(r.URL.Query().Get("compare"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	summary, err := s.backend.Summaries(from, to, rounded)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	// One bucket per day of the window; the current week keeps its days
+	// still to come.
+	days := len(summary.Buckets)
+	if thisWeek {
+		days = 7
+	}
+	resp := make([]summaryJSON, 0, len(summary.Projects))
+	for _, series := range summary.Projects {
+		daily := make([]int64, days)
+		for i, d := range series.Buckets {
+			if i < days {
+				daily[i] = seconds(d)
+			}
+		}
+		resp = append(resp, summaryJSON{
+			Project:                series.ProjectName,
+			DurationSeconds:        seconds(series.Duration),
+			DailySeconds:           daily,
+			Percentage:             series.Percentage,
+			EstimatedSeconds:       seconds(series.Estimated),
+			EstimatedActualSeconds: seconds(series.EstimatedActual),
+		})
+	}
+	if comparison == models.CompareNone {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	// The window is compared as the custom period of its days, or as the
+	// current week by default, at the same time into it.
+	window := models.Period{Kind: models.PeriodCustom, Start: from, End: to}
+	if thisWeek {
+		window = models.Period{Kind: models.PeriodWeek, Start: from, End: from.AddDate(0, 0, 7)}
+	}
+	compared := window.Compared(comparison)
+	before, err := s.backend.Summaries(compared.Start, compared.Start.Add(to.Sub(from)), rounded)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	deltas := models.CompareProjects(summary.Projects, before.Projects)
+	for i, d := range deltas {
+		if i >= len(resp) {
+			resp = append(resp, summaryJSON{Project: d.ProjectName, DailySeconds: make([]int64, days)})
+		}
+		previous := seconds(d.Previous)
+		change := seconds(d.Current) - previous
+		resp[i].PreviousSeconds, resp[i].ChangeSeconds = &previous, &change
+		if percent, ok := d.Percent(); ok {
+			resp[i].ChangePercent = &percent
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// decodeTask applies a task request body onto task. A missing end time leaves
+// the existing end in place, or makes a zero-length entry for new tasks.
+func decodeTask(w http.ResponseWriter, r *http.Request, task *models.Task) (*models.Task, error) {
+	var req taskRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return nil, err
+	}
+	project := strings.TrimSpace(req.Project)
+	if project == "" {
+		return nil, errors.New("project is required")
+	}
+	if req.Start.IsZero() {
+		return nil, errors.New("start is required")
+	}
+	task.ProjectName = project
+	task.Description = req.Description
+	task.StartTime = req.Start
+	switch {
+	case req.End != nil:
+		task.EndTime = *req.End
+	case task.EndTime.IsZero():
+		task.EndTime = req.Start
+	}
+	if task.EndTime.Before(task.StartTime) {
+		return nil, errors.New("end must be after or equal to start")
+	}
+	task.UpdateDuration()
+	return task, nil
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+func parseID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid task id %q", r.PathValue("id"))
+	}
+	return id, nil
+}
+
+// parseRange reads the from and to query parameters. Each accepts RFC 3339 or
+// a local YYYY-MM-DD date; a date-only to includes that whole day. Missing
+// values are returned as zero, except to which falls back to defaultTo.
+func parseRange(r *http.Request, defaultTo time.Time) (from, to time.Time, err error) {
+	query := r.URL.Query()
+	if value := query.Get("from"); value != "" {
+		if from, _, err = parseTimeParam(value); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	to = defaultTo
+	if value := query.Get("to"); value != "" {
+		var dateOnly bool
+		if to, dateOnly, err = parseTimeParam(value); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to: %w", err)
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+	}
+	return from, to, nil
+}
+
+// parseBoolParam reads an optional true/false query parameter.
+func parseBoolParam(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %q is not true or false", name, value)
+	}
+	return b, nil
+}
+
+func parseTimeParam(value string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+func seconds(d time.Duration) int64 {
+	if d < 0 {
+		return 0
+	}
+	return int64(d.Round(time.Second) / time.Second)
+}
+
+func writeBackendError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrConflict):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, ErrInvalid):
+		writeError(w, http.StatusBadRequest, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,471 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"trackyou/models"
+)
+
+const testToken = "secret-token"
+
+// fakeBackend is an in-memory Backend for handler tests.
+type fakeBackend struct {
+	current     *models.Task
+	tasks       []*models.Task
+	nextID      int64
+	lastFrom    time.Time
+	lastTo      time.Time
+	lastRounded bool
+	// summary replaces the summary of tasks when set, and summariesFrom
+	// both for windows starting at its keys.
+	summary       *models.Summary
+	summariesFrom map[time.Time]models.Summary
+}
+
+func (f *fakeBackend) Status() Status {
+	return Status{Current: f.current, TotalToday: 90 * time.Minute, WorkdayGoal: 8}
+}
+
+func (f *fakeBackend) StartTimer(projectName, description string, estimate time.Duration) (*models.Task, error) {
+	if f.current != nil {
+		return nil, fmt.Errorf("%w: already running", ErrConflict)
+	}
+	if projectName == "" {
+		return nil, fmt.Errorf("%w: project name is required", ErrInvalid)
+	}
+	f.current = models.NewTask(projectName, description)
+	f.current.Estimate = estimate
+	return f.current, nil
+}
+
+func (f *fakeBackend) StopTimer() (*models.Task, error) {
+	if f.current == nil {
+		return nil, fmt.Errorf("%w: not running", ErrConflict)
+	}
+	task := f.current
+	task.StopTask()
+	f.current = nil
+	if err := f.CreateTask(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (f *fakeBackend) Tasks(from, to time.Time) ([]*models.Task, error) {
+	f.lastFrom, f.lastTo = from, to
+	var tasks []*models.Task
+	for _, t := range f.tasks {
+		if t.Overlaps(from, to) {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, nil
+}
+
+func (f *fakeBackend) Task(id int64) (*models.Task, error) {
+	for _, t := range f.tasks {
+		if t.ID == id {
+			task := *t
+			return &task, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (f *fakeBackend) CreateTask(task *models.Task) error {
+	f.nextID++
+	task.ID = f.nextID
+	f.tasks = append(f.tasks, task)
+	return nil
+}
+
+func (f *fakeBackend) UpdateTask(task *models.Task) error {
+	for i, t := range f.tasks {
+		if t.ID == task.ID {
+			f.tasks[i] = task
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (f *fakeBackend) DeleteTask(id int64) error {
+	for i, t := range f.tasks {
+		if t.ID == id {
+			f.tasks = append(f.tasks[:i], f.tasks[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (f *fakeBackend) ProjectNames() ([]string, error) {
+	return []string{"Beta", "Alpha"}, nil
+}
+
+func (f *fakeBackend) WeekStart() time.Weekday {
+	return time.Monday
+}
+
+func (f *fakeBackend) Summaries(from, to time.Time, rounded bool) (models.Summary, error) {
+	f.lastFrom, f.lastTo, f.lastRounded = from, to, rounded
+	if summary, ok := f.summariesFrom[from]; ok {
+		return summary, nil
+	}
+	if f.summary != nil {
+		return *f.summary, nil
+	}
+	return models.ComputeSummary(f.tasks, from, to, models.BucketDay, f.WeekStart(), models.RoundingRules{}), nil
+}
+
+func doRequest(t *testing.T, handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeBody[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.NewDecoder(rec.Body).Decode(&v); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+func TestServer_RequiresBearerToken(t *testing.T) {
+	handler := NewServer(&fakeBackend{}, testToken).Handler()
+
+	for _, header := range []string{"", "Bearer wrong", testToken} {
+		req := httptest.NewRequest(http.MethodGet, "/status", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected 401, got %d", header, rec.Code)
+		}
+	}
+
+	emptyToken := NewServer(&fakeBackend{}, "").Handler()
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	emptyToken.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 when no token is configured, got %d", rec.Code)
+	}
+}
+
+func TestServer_OpenAPIIsPublic(t *testing.T) {
+	handler := NewServer(&fakeBackend{}, testToken).Handler()
+	req := httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "openapi: 3") {
+		t.Fatalf("unexpected spec body: %q", rec.Body.String()[:20])
+	}
+}
+
+func TestServer_TimerLifecycle(t *testing.T) {
+	backend := &fakeBackend{}
+	server := NewServer(backend, testToken)
+	handler := server.Handler()
+
+	rec := doRequest(t, handler, http.MethodGet, "/status", "")
+	status := decodeBody[statusJSON](t, rec)
+	if status.Running || status.Task != nil {
+		t.Fatalf("expected idle status, got %+v", status)
+	}
+	if status.TotalTodaySeconds != 5400 || status.WorkdayGoalHours != 8 {
+		t.Fatalf("unexpected totals: %+v", status)
+	}
+
+	rec = doRequest(t, handler, http.MethodPost, "/timer/start", `{"project":" API ","description":"from curl","estimate_seconds":2700}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	started := decodeBody[taskJSON](t, rec)
+	if started.Project != "API" || started.Description != "from curl" || started.EstimateSeconds != 2700 {
+		t.Fatalf("unexpected started task: %+v", started)
+	}
+
+	rec = doRequest(t, handler, http.MethodPost, "/timer/start", `{"project":"Other"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for second start, got %d", rec.Code)
+	}
+
+	server.now = func() time.Time { return backend.current.StartTime.Add(42 * time.Second) }
+	rec = doRequest(t, handler, http.MethodGet, "/status", "")
+	status = decodeBody[statusJSON](t, rec)
+	if !status.Running || status.Task == nil || status.ElapsedSeconds != 42 {
+		t.Fatalf("expected running status with 42s elapsed, got %+v", status)
+	}
+
+	rec = doRequest(t, handler, http.MethodPost, "/timer/stop", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if stopped := decodeBody[taskJSON](t, rec); stopped.ID != 1 {
+		t.Fatalf("expected saved task id 1, got %d", stopped.ID)
+	}
+
+	rec = doRequest(t, handler, http.MethodPost, "/timer/stop", "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 when nothing is running, got %d", rec.Code)
+	}
+}
+
+func TestServer_TimerStart_Validation(t *testing.T) {
+	handler := NewServer(&fakeBackend{}, testToken).Handler()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing project", `{"description":"x"}`},
+		{"malformed json", `{"project":`},
+		{"unknown field", `{"project":"A","extra":true}`},
+		{"negative estimate", `{"project":"A","estimate_seconds":-60}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := doRequest(t, handler, http.MethodPost, "/timer/start", tc.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestServer_TaskCRUD(t *testing.T) {
+	backend := &fakeBackend{}
+	handler := NewServer(backend, testToken).Handler()
+
+	rec := doRequest(t, handler, http.MethodPost, "/tasks",
+		`{"project":"Alpha","description":"write","start":"2024-03-04T09:00:00Z","end":"2024-03-04T10:30:00Z"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	created := decodeBody[taskJSON](t, rec)
+	if created.ID != 1 || created.DurationSeconds != 5400 {
+		t.Fatalf("unexpected created task: %+v", created)
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/tasks/1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec = doRequest(t, handler, http.MethodPut, "/tasks/1",
+		`{"project":"Beta","description":"review","start":"2024-03-04T09:00:00Z"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	updated := decodeBody[taskJSON](t, rec)
+	if updated.Project != "Beta" || updated.DurationSeconds != 5400 {
+		t.Fatalf("expected project change with end kept, got %+v", updated)
+	}
+
+	rec = doRequest(t, handler, http.MethodPut, "/tasks/1",
+		`{"project":"Beta","start":"2024-03-04T11:00:00Z","end":"2024-03-04T10:00:00Z"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for end before start, got %d", rec.Code)
+	}
+
+	rec = doRequest(t, handler, http.MethodDelete, "/tasks/1", "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		rec = doRequest(t, handler, method, "/tasks/1", "")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s after delete: expected 404, got %d", method, rec.Code)
+		}
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/tasks/abc", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for non-numeric id, got %d", rec.Code)
+	}
+}
+
+func TestServer_ListTasks_DateRange(t *testing.T) {
+	backend := &fakeBackend{}
+	handler := NewServer(backend, testToken).Handler()
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 9, 0, 0, 0, time.Local) }
+	for _, d := range []int{3, 4, 5} {
+		backend.CreateTask(&models.Task{ProjectName: "P", StartTime: day(d), EndTime: day(d).Add(time.Hour), Duration: time.Hour})
+	}
+
+	rec := doRequest(t, handler, http.MethodGet, "/tasks?from=2024-03-04&to=2024-03-04", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	tasks := decodeBody[[]taskJSON](t, rec)
+	if len(tasks) != 1 || !tasks[0].Start.Equal(day(4)) {
+		t.Fatalf("expected only the March 4 task, got %+v", tasks)
+	}
+	if want := time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local); !backend.lastTo.Equal(want) {
+		t.Errorf("expected date-only to to include the whole day, got %v", backend.lastTo)
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/tasks", "")
+	if tasks := decodeBody[[]taskJSON](t, rec); len(tasks) != 3 {
+		t.Fatalf("expected all 3 tasks without a range, got %d", len(tasks))
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/tasks?from=yesterday", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid from, got %d", rec.Code)
+	}
+}
+
+func TestServer_ProjectsAndSummaries(t *testing.T) {
+	backend := &fakeBackend{
+		summary: &models.Summary{
+			Buckets: make([]models.Bucket, 3),
+			Projects: []models.ProjectSeries{
+				{ProjectName: "Alpha", Duration: 2 * time.Hour, Buckets: []time.Duration{time.Hour, time.Hour, 0}, Percentage: 1,
+					Estimated: time.Hour, EstimatedActual: 90 * time.Minute},
+			},
+		},
+	}
+	server := NewServer(backend, testToken)
+	now := time.Date(2024, 3, 6, 12, 0, 0, 0, time.Local) // Wednesday
+	server.now = func() time.Time { return now }
+	handler := server.Handler()
+
+	rec := doRequest(t, handler, http.MethodGet, "/projects", "")
+	if names := decodeBody[[]string](t, rec); len(names) != 2 || names[0] != "Beta" {
+		t.Fatalf("unexpected projects: %v", names)
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/summaries", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	summaries := decodeBody[[]summaryJSON](t, rec)
+	if len(summaries) != 1 || summaries[0].DurationSeconds != 7200 || summaries[0].DailySeconds[1] != 3600 {
+		t.Fatalf("unexpected summaries: %+v", summaries)
+	}
+	if len(summaries[0].DailySeconds) != 7 {
+		t.Errorf("expected the whole current week's days, got %v", summaries[0].DailySeconds)
+	}
+	if summaries[0].EstimatedSeconds != 3600 || summaries[0].EstimatedActualSeconds != 5400 {
+		t.Fatalf("expected the estimate comparison, got %+v", summaries[0])
+	}
+	if want := time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local); !backend.lastFrom.Equal(want) || !backend.lastTo.Equal(now) {
+		t.Errorf("expected default window [%v, %v], got [%v, %v]", want, now, backend.lastFrom, backend.lastTo)
+	}
+
+	if backend.lastRounded {
+		t.Error("expected raw totals by default")
+	}
+	if rec = doRequest(t, handler, http.MethodGet, "/summaries?rounded=true", ""); rec.Code != http.StatusOK || !backend.lastRounded {
+		t.Errorf("expected rounded totals requested, got %d", rec.Code)
+	}
+	if rec = doRequest(t, handler, http.MethodGet, "/summaries?rounded=maybe", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid rounded flag, got %d", rec.Code)
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/summaries?from=2024-03-10&to=2024-03-01", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for inverted range, got %d", rec.Code)
+	}
+}
+
+func TestServer_SummariesCompared(t *testing.T) {
+	lastWeek := time.Date(2024, 2, 26, 0, 0, 0, 0, time.Local)
+	backend := &fakeBackend{
+		summary: &models.Summary{Projects: []models.ProjectSeries{{ProjectName: "Alpha", Duration: 3 * time.Hour}}},
+		summariesFrom: map[time.Time]models.Summary{
+			lastWeek: {Projects: []models.ProjectSeries{{ProjectName: "Beta", Duration: time.Hour}, {ProjectName: "Alpha", Duration: 2 * time.Hour}}},
+			time.Date(2023, 3, 4, 0, 0, 0, 0, time.Local): {},
+		},
+	}
+	server := NewServer(backend, testToken)
+	now := time.Date(2024, 3, 6, 12, 0, 0, 0, time.Local) // Wednesday
+	server.now = func() time.Time { return now }
+	handler := server.Handler()
+
+	rec := doRequest(t, handler, http.MethodGet, "/summaries?compare=previous", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	// This week so far is compared with last week up to the same time.
+	if want := now.AddDate(0, 0, -7); !backend.lastFrom.Equal(lastWeek) || !backend.lastTo.Equal(want) {
+		t.Errorf("expected the compared window [%v, %v], got [%v, %v]", lastWeek, want, backend.lastFrom, backend.lastTo)
+	}
+	summaries := decodeBody[[]summaryJSON](t, rec)
+	if len(summaries) != 2 {
+		t.Fatalf("expected Alpha and the no longer tracked Beta, got %+v", summaries)
+	}
+	alpha, beta := summaries[0], summaries[1]
+	if alpha.PreviousSeconds == nil || *alpha.PreviousSeconds != 7200 || *alpha.ChangeSeconds != 3600 || alpha.ChangePercent == nil || *alpha.ChangePercent != 50 {
+		t.Errorf("unexpected Alpha comparison %+v", alpha)
+	}
+	if beta.Project != "Beta" || beta.DurationSeconds != 0 || *beta.ChangeSeconds != -3600 || *beta.ChangePercent != -100 {
+		t.Errorf("unexpected Beta comparison %+v", beta)
+	}
+	if len(beta.DailySeconds) != len(alpha.DailySeconds) {
+		t.Errorf("expected Beta's days to match Alpha's, got %v and %v", beta.DailySeconds, alpha.DailySeconds)
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/summaries?from=2024-03-04&to=2024-03-10&compare=last_year", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if want := time.Date(2023, 3, 4, 0, 0, 0, 0, time.Local); !backend.lastFrom.Equal(want) || !backend.lastTo.Equal(want.AddDate(0, 0, 7)) {
+		t.Errorf("expected the same dates a year earlier, got [%v, %v]", backend.lastFrom, backend.lastTo)
+	}
+	if summaries := decodeBody[[]summaryJSON](t, rec); summaries[0].ChangePercent != nil {
+		t.Errorf("expected no percentage for a project new since last year, got %v", *summaries[0].ChangePercent)
+	}
+
+	if rec = doRequest(t, handler, http.MethodGet, "/summaries?compare=lastweek", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown comparison, got %d", rec.Code)
+	}
+}
+
+func TestServer_SummariesLongRange(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 9, 0, 0, 0, time.Local) }
+	backend := &fakeBackend{tasks: []*models.Task{
+		{ProjectName: "Alpha", StartTime: day(1), Duration: time.Hour},
+		{ProjectName: "Alpha", StartTime: day(10), Duration: 2 * time.Hour},
+		{ProjectName: "Beta", StartTime: day(14), Duration: 30 * time.Minute},
+	}}
+	handler := NewServer(backend, testToken).Handler()
+
+	rec := doRequest(t, handler, http.MethodGet, "/summaries?from=2024-03-01&to=2024-03-14&compare=previous", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	summaries := decodeBody[[]summaryJSON](t, rec)
+	if len(summaries) != 2 {
+		t.Fatalf("expected Alpha and Beta, got %+v", summaries)
+	}
+	alpha, beta := summaries[0], summaries[1]
+	if len(alpha.DailySeconds) != 14 || alpha.DailySeconds[0] != 3600 || alpha.DailySeconds[9] != 7200 || alpha.DurationSeconds != 10800 {
+		t.Errorf("expected 14 days with Alpha's time on the 1st and 10th, got %+v", alpha)
+	}
+	if len(beta.DailySeconds) != 14 || beta.DailySeconds[13] != 1800 {
+		t.Errorf("expected Beta's time on the 14th day, got %v", beta.DailySeconds)
+	}
+}
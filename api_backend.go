@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"trackyou/api"
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+)
+
+// apiShutdownTimeout bounds how long in-flight API requests may delay
+// stopping the server.
+const apiShutdownTimeout = 2 * time.Second
+
+// appBackend adapts App to api.Backend. Calls that touch widgets are run on
+// the Fyne main goroutine, exactly as if the matching button had been used.
+type appBackend struct {
+	app *App
+}
+
+var _ api.Backend = appBackend{}
+
+// toAPIError maps App errors onto the api sentinel errors.
+func toAPIError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errTaskAlreadyRunning), errors.Is(err, errNoTaskRunning):
+		return fmt.Errorf("%w: %v", api.ErrConflict, err)
+	case errors.Is(err, errProjectRequired):
+		return fmt.Errorf("%w: %v", api.ErrInvalid, err)
+	case errors.Is(err, errTaskNotFound):
+		return fmt.Errorf("%w: %v", api.ErrNotFound, err)
+	}
+	return err
+}
+
+func (b appBackend) Status() api.Status {
+	a := b.app
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	status := api.Status{
+		TotalToday:  a.calculateTotalDurationTodayUnlocked(),
+		WorkdayGoal: a.schedule.Target(time.Now()).Hours(),
+	}
+	if a.currentTask != nil {
+		current := *a.currentTask
+		status.Current = &current
+	}
+	return status
+}
+
+func (b appBackend) StartTimer(projectName, description string, estimate time.Duration) (*models.Task, error) {
+	var task *models.Task
+	var err error
+	fyne.DoAndWait(func() {
+		task, err = b.app.beginTask(projectName, description, estimate)
+	})
+	return task, toAPIError(err)
+}
+
+func (b appBackend) StopTimer() (*models.Task, error) {
+	var task *models.Task
+	var err error
+	fyne.DoAndWait(func() {
+		task, err = b.app.finishTask()
+	})
+	return task, toAPIError(err)
+}
+
+func (b appBackend) Tasks(from, to time.Time) ([]*models.Task, error) {
+	a := b.app
+	a.mu.RLock()
+	tasks := make([]*models.Task, 0, len(a.tasks))
+	for _, t := range a.tasks {
+		if t.Overlaps(from, to) {
+			task := *t
+			tasks = append(tasks, &task)
+		}
+	}
+	a.mu.RUnlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].StartTime.After(tasks[j].StartTime)
+	})
+	return tasks, nil
+}
+
+func (b appBackend) Task(id int64) (*models.Task, error) {
+	t := b.app.findTask(id)
+	if t == nil {
+		return nil, toAPIError(errTaskNotFound)
+	}
+	b.app.mu.RLock()
+	task := *t
+	b.app.mu.RUnlock()
+	return &task, nil
+}
+
+func (b appBackend) CreateTask(task *models.Task) error {
+	var err error
+	fyne.DoAndWait(func() {
+		err = b.app.addTask(task)
+	})
+	return toAPIError(err)
+}
+
+func (b appBackend) UpdateTask(task *models.Task) error {
+	existing := b.app.findTask(task.ID)
+	if existing == nil {
+		return toAPIError(errTaskNotFound)
+	}
+	var err error
+	fyne.DoAndWait(func() {
+		err = b.app.applyTaskEdit(existing, task.ProjectName, task.Description, task.StartTime, task.EndTime)
+	})
+	if err != nil {
+		return toAPIError(err)
+	}
+	task.Duration = existing.Duration
+	return nil
+}
+
+func (b appBackend) DeleteTask(id int64) error {
+	var err error
+	fyne.DoAndWait(func() {
+		err = b.app.removeTask(id)
+	})
+	return toAPIError(err)
+}
+
+func (b appBackend) ProjectNames() ([]string, error) {
+	return b.app.db.GetProjectNames()
+}
+
+func (b appBackend) WeekStart() time.Weekday {
+	b.app.mu.RLock()
+	defer b.app.mu.RUnlock()
+	return b.app.prefs.WeekStart
+}
+
+func (b appBackend) Summaries(from, to time.Time, rounded bool) (models.Summary, error) {
+	a := b.app
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var rules models.RoundingRules
+	if rounded {
+		rules = a.prefs.RoundingRules()
+	}
+	return models.ComputeSummary(a.tasks, from, to, models.BucketDay, a.prefs.WeekStart, rules), nil
+}
+
+// startAPIServer starts the local REST API when it is enabled in the
+// preferences, generating a bearer token on first use.
+func (a *App) startAPIServer() error {
+	a.mu.RLock()
+	enabled, port := a.prefs.APIEnabled, a.prefs.APIPort
+	a.mu.RUnlock()
+	if !enabled {
+		return nil
+	}
+	token, err := a.ensureAPIToken()
+	if err != nil {
+		return err
+	}
+
+	server := api.NewServer(appBackend{app: a}, token)
+	if err := server.Start(port); err != nil {
+		return fmt.Errorf("failed to start local API on %s: %w", api.LoopbackAddr(port), err)
+	}
+	a.apiServer = server
+	return nil
+}
+
+// stopAPIServer shuts down the local REST API if it is running.
+func (a *App) stopAPIServer() {
+	if a.apiServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), apiShutdownTimeout)
+	defer cancel()
+	if err := a.apiServer.Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to stop local API: %v\n", err)
+	}
+	a.apiServer = nil
+}
+
+// ensureAPIToken returns the stored API token, creating one if needed.
+func (a *App) ensureAPIToken() (string, error) {
+	token, err := a.db.GetAPIToken()
+	if err != nil || token != "" {
+		return token, err
+	}
+	token, err = api.NewToken()
+	if err != nil {
+		return "", err
+	}
+	return token, a.db.SetAPIToken(token)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"trackyou/api"
+	"trackyou/models"
+)
+
+func doAPIRequest(t *testing.T, handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIntegration_API_TimerDrivesApp(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	handler := api.NewServer(appBackend{app: app}, "token").Handler()
+
+	rec := doAPIRequest(t, handler, http.MethodPost, "/timer/start", `{"project":"API Project","description":"via http"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	if app.currentTask == nil || app.currentTask.ProjectName != "API Project" {
+		t.Fatal("expected the API to start the app's timer")
+	}
+	if !app.startButton.Disabled() {
+		t.Error("start button should be disabled while an API-started task runs")
+	}
+	if app.projectEntry.Text != "API Project" {
+		t.Errorf("expected project entry synced, got %q", app.projectEntry.Text)
+	}
+
+	rec = doAPIRequest(t, handler, http.MethodPost, "/timer/start", `{"project":"Other"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while running, got %d", rec.Code)
+	}
+
+	rec = doAPIRequest(t, handler, http.MethodPost, "/timer/stop", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if app.currentTask != nil {
+		t.Fatal("expected the API to stop the app's timer")
+	}
+
+	tasks, err := app.db.GetTasks()
+	if err != nil {
+		t.Fatalf("failed to get tasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ProjectName != "API Project" {
+		t.Fatalf("expected stopped task persisted, got %+v", tasks)
+	}
+	if app.tasks[0].ID != tasks[0].ID {
+		t.Errorf("expected in-memory task to carry the saved ID %d, got %d", tasks[0].ID, app.tasks[0].ID)
+	}
+}
+
+func TestIntegration_API_TaskCRUDUpdatesLog(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	handler := api.NewServer(appBackend{app: app}, "token").Handler()
+
+	rec := doAPIRequest(t, handler, http.MethodPost, "/tasks",
+		`{"project":"Imported","description":"meeting","start":"2024-03-04T09:00:00Z","end":"2024-03-04T10:00:00Z"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if len(app.tasks) != 1 || app.getTaskCount() != 2 {
+		t.Fatalf("expected the created task in the Log, got %d tasks / %d rows", len(app.tasks), app.getTaskCount())
+	}
+
+	rec = doAPIRequest(t, handler, http.MethodPut, "/tasks/"+strconv.FormatInt(created.ID, 10),
+		`{"project":"Renamed","start":"2024-03-04T09:00:00Z","end":"2024-03-04T09:30:00Z"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if app.tasks[0].ProjectName != "Renamed" || app.tasks[0].Duration.Minutes() != 30 {
+		t.Fatalf("expected in-memory task updated, got %+v", app.tasks[0])
+	}
+	saved, err := app.db.GetTask(created.ID)
+	if err != nil || saved.ProjectName != "Renamed" {
+		t.Fatalf("expected update persisted, got %+v (err %v)", saved, err)
+	}
+
+	rec = doAPIRequest(t, handler, http.MethodGet, "/projects", "")
+	if !strings.Contains(rec.Body.String(), "Renamed") {
+		t.Errorf("expected projects to include Renamed, got %s", rec.Body)
+	}
+
+	rec = doAPIRequest(t, handler, http.MethodDelete, "/tasks/"+strconv.FormatInt(created.ID, 10), "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body)
+	}
+	if len(app.tasks) != 0 || app.getTaskCount() != 0 {
+		t.Fatalf("expected Log emptied after delete, got %d tasks", len(app.tasks))
+	}
+
+	rec = doAPIRequest(t, handler, http.MethodDelete, "/tasks/"+strconv.FormatInt(created.ID, 10), "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for deleted task, got %d", rec.Code)
+	}
+}
+
+func TestIntegration_API_RoundedSummaries(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	handler := api.NewServer(appBackend{app: app}, "token").Handler()
+	rec := doAPIRequest(t, handler, http.MethodPost, "/tasks",
+		`{"project":"Client","start":"2024-03-04T09:00:00Z","end":"2024-03-04T09:10:00Z"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	app.prefs.RoundingMinutes, app.prefs.RoundingMode, app.prefs.RoundingApply = 15, models.RoundUp, models.RoundPerEntry
+
+	summary := func(query string) int64 {
+		t.Helper()
+		rec := doAPIRequest(t, handler, http.MethodGet, "/summaries?from=2024-03-04T00:00:00Z&to=2024-03-05T00:00:00Z"+query, "")
+		var summaries []struct {
+			DurationSeconds int64 `json:"duration_seconds"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&summaries); err != nil || len(summaries) != 1 {
+			t.Fatalf("unexpected summaries %s (err %v)", rec.Body, err)
+		}
+		return summaries[0].DurationSeconds
+	}
+	if got := summary(""); got != 600 {
+		t.Errorf("expected 10 minutes raw, got %ds", got)
+	}
+	if got := summary("&rounded=true"); got != 900 {
+		t.Errorf("expected 15 minutes rounded, got %ds", got)
+	}
+	if app.tasks[0].Duration != 10*time.Minute {
+		t.Errorf("expected the stored duration untouched, got %v", app.tasks[0].Duration)
+	}
+
+	// The Summary tab toggle redraws without touching the tasks either.
+	app.roundedCheck.SetChecked(true)
+	app.roundedCheck.SetChecked(false)
+	if app.tasks[0].Duration != 10*time.Minute {
+		t.Errorf("expected the stored duration untouched, got %v", app.tasks[0].Duration)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// awayPollInterval is how often the system idle time is sampled while a task
+// runs. It bounds how late the away prompt appears after the user returns.
+const awayPollInterval = 5 * time.Second
+
+// awayChoice is what to do with time spent away from a running task.
+type awayChoice int
+
+const (
+	awayKeep awayChoice = iota
+	awayDiscard
+	awaySplit
+)
+
+// monitorAway watches the system idle time and asks what to do with the
+// away time once the user returns to a running task.
+func (a *App) monitorAway(ctx context.Context) {
+	if a.idleSource == nil {
+		return
+	}
+	ticker := time.NewTicker(awayPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if from, to, ok := a.checkAway(time.Now().Round(0)); ok {
+				fyne.Do(func() { a.showAwayDialog(from, to) })
+			}
+		}
+	}
+}
+
+// checkAway samples the idle source. Once the user has been idle for at
+// least the idle threshold with a task running, it returns the away period
+// [from, to) as soon as input resumes, and true. Only one period is
+// reported until resolveAwayTime is called for it.
+func (a *App) checkAway(now time.Time) (from, to time.Time, ok bool) {
+	a.mu.RLock()
+	running := a.currentTask != nil && !a.currentTask.IsPaused()
+	prompting := a.awayPrompting
+	threshold := time.Duration(a.idleThreshold) * time.Minute
+	a.mu.RUnlock()
+
+	if !running || prompting {
+		a.mu.Lock()
+		a.awaySince = time.Time{}
+		a.mu.Unlock()
+		return time.Time{}, time.Time{}, false
+	}
+
+	idleFor, err := a.idleSource.IdleTime()
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	lastInput := now.Add(-idleFor)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.currentTask == nil || a.currentTask.IsPaused() {
+		return time.Time{}, time.Time{}, false
+	}
+	if idleFor >= threshold {
+		if a.awaySince.IsZero() {
+			a.awaySince = lastInput
+		}
+		return time.Time{}, time.Time{}, false
+	}
+	if a.awaySince.IsZero() {
+		return time.Time{}, time.Time{}, false
+	}
+
+	from = a.awaySince
+	a.awaySince = time.Time{}
+	a.lastActivity = from
+	// Time before the task (or its latest resume) is not the task's to give up.
+	if since := a.currentTask.ActiveSince(); from.Before(since) {
+		from = since
+	}
+	if lastInput.Sub(from) < threshold {
+		return time.Time{}, time.Time{}, false
+	}
+	a.awayPrompting = true
+	return from, lastInput, true
+}
+
+// resolveAwayTime applies the user's choice for the away period [from, to)
+// of the running task. Splitting records the period as a completed task for
+// project.
+func (a *App) resolveAwayTime(choice awayChoice, from, to time.Time, project, description string) error {
+	a.mu.Lock()
+	a.awayPrompting = false
+	a.mu.Unlock()
+
+	if choice == awayKeep {
+		return nil
+	}
+	if choice == awaySplit && project == "" {
+		return errProjectRequired
+	}
+
+	a.mu.Lock()
+	if a.currentTask == nil {
+		a.mu.Unlock()
+		return errNoTaskRunning
+	}
+	a.currentTask.Discard(from, to)
+	a.mu.Unlock()
+	a.refreshTimerSummary()
+
+	if choice == awayDiscard {
+		return nil
+	}
+	task := models.NewTask(project, description)
+	task.StartTime = from
+	task.EndTime = to
+	task.UpdateDuration()
+	if err := a.addTask(task); err != nil {
+		return err
+	}
+	a.refreshProjectSuggestions()
+	return nil
+}
+
+func (a *App) showAwayDialog(from, to time.Time) {
+	resolve := func(choice awayChoice, project, description string) {
+		if err := a.resolveAwayTime(choice, from, to, project, description); err != nil {
+			a.showDialogError(err)
+		}
+	}
+	if os.Getenv("FYNE_TEST_SKIP_GUI") != "" {
+		resolve(awayKeep, "", "")
+		return
+	}
+
+	a.mu.RLock()
+	current := ""
+	if a.currentTask != nil {
+		current = a.currentTask.ProjectName
+	}
+	a.mu.RUnlock()
+
+	message := widget.NewLabel(fmt.Sprintf("You were away from %s to %s (%d minutes) while %s was running.",
+		from.Format("15:04"), to.Format("15:04"), int(to.Sub(from).Minutes()), current))
+	message.Wrapping = fyne.TextWrapWord
+
+	projectNames, err := a.db.GetProjectNames()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load project names: %v\n", err)
+	}
+	projectEntry := widget.NewSelectEntry(projectNames)
+	projectEntry.SetPlaceHolder("Project for the away time")
+	descriptionEntry := widget.NewEntry()
+	descriptionEntry.SetPlaceHolder("Description (optional)")
+	form := widget.NewForm(
+		widget.NewFormItem("Project", projectEntry),
+		widget.NewFormItem("Description", descriptionEntry),
+	)
+
+	var d *dialog.CustomDialog
+	keep := widget.NewButton("Keep", func() {
+		d.Hide()
+		resolve(awayKeep, "", "")
+	})
+	discard := widget.NewButton("Discard", func() {
+		d.Hide()
+		resolve(awayDiscard, "", "")
+	})
+	split := widget.NewButton("Split Off", func() {
+		project := strings.TrimSpace(projectEntry.Text)
+		if project == "" {
+			a.showDialogError(errProjectRequired)
+			return
+		}
+		d.Hide()
+		resolve(awaySplit, project, strings.TrimSpace(descriptionEntry.Text))
+	})
+	keep.Importance = widget.HighImportance
+
+	d = dialog.NewCustomWithoutButtons("Welcome Back", container.NewVBox(message, form), a.window)
+	d.SetButtons([]fyne.CanvasObject{discard, split, keep})
+	d.Resize(fyne.NewSize(420, d.MinSize().Height))
+	d.Show()
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// budgetWindow identifies a budget's current window, so that alerts fire
+// again once a weekly or monthly budget starts over.
+type budgetWindow struct {
+	project string
+	start   time.Time
+}
+
+// budgetUsagesUnlocked returns the usage of every budget at now, counting
+// the running task. The caller must hold a.mu.
+func (a *App) budgetUsagesUnlocked(now time.Time) []models.BudgetUsage {
+	usages := make([]models.BudgetUsage, 0, len(a.budgets))
+	for _, b := range a.budgets {
+		usages = append(usages, models.ComputeBudgetUsage(a.tasks, a.currentTask, b, now, a.prefs.WeekStart))
+	}
+	return usages
+}
+
+// budgetUsage returns the usage at now of project's budget, or false when
+// it has none.
+func (a *App) budgetUsage(project string, now time.Time) (models.BudgetUsage, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, b := range a.budgets {
+		if b.ProjectName == project {
+			return models.ComputeBudgetUsage(a.tasks, a.currentTask, b, now, a.prefs.WeekStart), true
+		}
+	}
+	return models.BudgetUsage{}, false
+}
+
+// showBudgetProgress shows the budget of the running task's project, or of
+// the project entered in the Start area, hiding it for projects without one.
+func (a *App) showBudgetProgress(now time.Time) {
+	if a.budgetBar == nil {
+		return
+	}
+	project := strings.TrimSpace(a.projectEntry.Text)
+	a.mu.RLock()
+	if a.currentTask != nil {
+		project = a.currentTask.ProjectName
+	}
+	a.mu.RUnlock()
+
+	u, ok := a.budgetUsage(project, now)
+	if !ok {
+		a.budgetBar.Hide()
+		a.budgetLabel.Hide()
+		return
+	}
+	a.budgetBar.SetValue(min(u.Fraction(), 1))
+	a.budgetLabel.SetText("Budget: " + models.FormatBudget(u))
+	a.budgetLabel.Importance = widget.LowImportance
+	if u.Remaining() < 0 {
+		a.budgetLabel.Importance = widget.DangerImportance
+	}
+	a.budgetLabel.Refresh()
+	a.budgetBar.Show()
+	a.budgetLabel.Show()
+}
+
+// checkBudget notifies the user when the running task's project reaches
+// one of the alert thresholds of its budget, once per threshold and budget
+// window. Returns true if a notification was sent.
+func (a *App) checkBudget(now time.Time) bool {
+	a.mu.RLock()
+	task := a.currentTask
+	var project string
+	if task != nil {
+		project = task.ProjectName
+	}
+	thresholds := a.prefs.BudgetAlerts
+	a.mu.RUnlock()
+	if task == nil {
+		return false
+	}
+	u, ok := a.budgetUsage(project, now)
+	if !ok {
+		return false
+	}
+
+	window := budgetWindow{project: project, start: u.Start}
+	reached := models.BudgetAlert(u.Fraction(), thresholds)
+	a.mu.Lock()
+	if a.budgetAlerted == nil {
+		a.budgetAlerted = make(map[budgetWindow]int)
+	}
+	alerted := a.budgetAlerted[window]
+	// A raised budget that is no longer reached should notify again when it
+	// is.
+	a.budgetAlerted[window] = reached
+	a.mu.Unlock()
+	if reached <= alerted {
+		return false
+	}
+
+	title := fmt.Sprintf("Budget %d%% Used", reached)
+	if reached >= 100 {
+		title = "Budget Exceeded"
+	}
+	a.app.SendNotification(fyne.NewNotification(title,
+		fmt.Sprintf("%s: %s.", project, models.FormatBudget(u))))
+	return true
+}
+
+// showBudgetsDialog lists the project budgets, with controls to set and
+// remove them.
+func (a *App) showBudgetsDialog() {
+	rows := container.NewVBox()
+	var refresh func()
+	refresh = func() {
+		rows.RemoveAll()
+		budgets, err := a.db.GetBudgets()
+		if err != nil {
+			a.showDialogError(err)
+		}
+		if len(budgets) == 0 {
+			rows.Add(widget.NewLabel("No budgets set yet."))
+		}
+		for _, b := range budgets {
+			text := fmt.Sprintf("%s · %s · %s", b.ProjectName, models.BudgetPeriodLabel(b.Period), models.FormatEstimate(b.Limit))
+			project := b.ProjectName
+			remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				if err := a.removeBudget(project); err != nil {
+					a.showDialogError(err)
+				}
+				refresh()
+			})
+			remove.Importance = widget.LowImportance
+			rows.Add(container.NewHBox(widget.NewLabel(text), layout.NewSpacer(), remove))
+		}
+		rows.Refresh()
+	}
+	refresh()
+
+	names, err := a.db.GetProjectNames()
+	if err != nil {
+		a.showDialogError(err)
+	}
+	projectEntry := widget.NewSelectEntry(names)
+	projectEntry.SetPlaceHolder("Project")
+	hoursEntry := widget.NewEntry()
+	hoursEntry.SetPlaceHolder("Hours")
+	periods := make([]string, len(models.BudgetPeriods))
+	for i, period := range models.BudgetPeriods {
+		periods[i] = models.BudgetPeriodLabel(period)
+	}
+	periodSelect := widget.NewSelect(periods, nil)
+	periodSelect.SetSelectedIndex(0)
+	set := widget.NewButtonWithIcon("Set", theme.ContentAddIcon(), func() {
+		period := models.BudgetPeriods[periodSelect.SelectedIndex()]
+		if err := a.setBudget(projectEntry.Text, hoursEntry.Text, period); err != nil {
+			a.showDialogError(err)
+			return
+		}
+		projectEntry.SetText("")
+		hoursEntry.SetText("")
+		refresh()
+	})
+
+	noteLabel := widget.NewLabel("Setting a budget for a project that has one replaces it. Alerts fire at the percentages set in Settings.")
+	noteLabel.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(360, 200))
+	content := container.NewBorder(
+		noteLabel,
+		container.NewBorder(nil, nil, nil, set, container.NewGridWithColumns(3, projectEntry, hoursEntry, periodSelect)),
+		nil, nil,
+		scroll,
+	)
+	dialog.ShowCustom("Budgets", "Close", content, a.window)
+}
+
+// setBudget gives project a budget of hours, a positive number, per period.
+func (a *App) setBudget(project, hours, period string) error {
+	value, err := strconv.ParseFloat(strings.TrimSpace(hours), 64)
+	if err != nil || !(value > 0) || value > 1e6 {
+		return models.ErrInvalidBudget
+	}
+	b := models.Budget{
+		ProjectName: strings.TrimSpace(project),
+		Limit:       time.Duration(value * float64(time.Hour)).Round(time.Second),
+		Period:      period,
+	}
+	if err := a.db.SetBudget(b); err != nil {
+		return err
+	}
+	a.reloadPreferences()
+	return nil
+}
+
+// removeBudget deletes project's budget.
+func (a *App) removeBudget(project string) error {
+	if err := a.db.DeleteBudget(project); err != nil {
+		return err
+	}
+	a.reloadPreferences()
+	return nil
+}
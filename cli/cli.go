@@ -0,0 +1,87 @@
+// Package cli implements TrackYou's command-line subcommands. They run
+// without starting the GUI so they stay fast enough for shell prompts.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Env carries everything a subcommand needs from the process.
+type Env struct {
+	DBPath string
+	Stdout io.Writer
+	Stderr io.Writer
+	Now    func() time.Time
+}
+
+// flagSpec describes a flag for shell completion. Values lists the fixed
+// choices, if any.
+type flagSpec struct {
+	Name   string
+	Usage  string
+	Values []string
+}
+
+type command struct {
+	run     func(env Env, args []string) error
+	summary string
+	// values lists the choices of the first positional argument, for
+	// completion.
+	values []string
+	flags  []flagSpec
+}
+
+var commands map[string]command
+
+func init() {
+	// Assigned in init because completion refers back to this table.
+	commands = map[string]command{
+		"prompt": {
+			run:     runPrompt,
+			summary: "print the running task for shell prompts and status bars",
+			flags: []flagSpec{
+				{Name: "template", Usage: "Go text/template for the running task"},
+				{Name: "idle", Usage: "text to print when no task is running"},
+				{Name: "format", Usage: "output format", Values: promptFormats},
+			},
+		},
+		"completion": {run: runCompletion, summary: "print a shell completion script", values: completionShells},
+	}
+}
+
+// IsCommand reports whether name is a known subcommand.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Run executes the subcommand named by args[0] and returns the process exit
+// code.
+func Run(env Env, args []string) int {
+	if env.Now == nil {
+		env.Now = time.Now
+	}
+	if len(args) == 0 || !IsCommand(args[0]) {
+		fmt.Fprintf(env.Stderr, "trackyou: unknown command %q\n", strings.Join(args, " "))
+		return 2
+	}
+	if err := commands[args[0]].run(env, args[1:]); err != nil {
+		fmt.Fprintf(env.Stderr, "trackyou %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// formatShort renders a duration compactly, e.g. "1h05m" or "12m".
+func formatShort(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%dh%02dm", h, m)
+	}
+	return fmt.Sprintf("%dm", m)
+}
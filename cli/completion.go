@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// completionShells are the shells `trackyou completion` can generate for.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// completionData is the template view of the command line: the global
+// flags, then the subcommands.
+type completionData struct {
+	Global   []flagSpec
+	Commands []completionCommand
+}
+
+// completionCommand is the template view of a command.
+type completionCommand struct {
+	Name    string
+	Summary string
+	Values  []string
+	Flags   []flagSpec
+}
+
+// runCompletion prints a completion script: trackyou completion bash|zsh|fish
+func runCompletion(env Env, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: trackyou completion %s", strings.Join(completionShells, "|"))
+	}
+	tmpl, ok := completionTemplates[args[0]]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q (want %s)", args[0], strings.Join(completionShells, ", "))
+	}
+	return tmpl.Execute(env.Stdout, completionData{Global: globalFlags, Commands: sortedCommands()})
+}
+
+func sortedCommands() []completionCommand {
+	list := make([]completionCommand, 0, len(commands))
+	for name, cmd := range commands {
+		list = append(list, completionCommand{
+			Name:    name,
+			Summary: cmd.summary,
+			Values:  cmd.values,
+			Flags:   cmd.flags,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+var completionFuncs = template.FuncMap{
+	"join": strings.Join,
+	"names": func(cmds []completionCommand) string {
+		names := make([]string, len(cmds))
+		for i, c := range cmds {
+			names[i] = c.Name
+		}
+		return strings.Join(names, " ")
+	},
+	"flagNames": func(flags []flagSpec) string {
+		names := make([]string, len(flags))
+		for i, f := range flags {
+			names[i] = "--" + f.Name
+		}
+		return strings.Join(names, " ")
+	},
+	// dashed lists the flags in both the --name and -name forms.
+	"dashed": func(flags []flagSpec, sep string) string {
+		forms := make([]string, 0, 2*len(flags))
+		for _, f := range flags {
+			forms = append(forms, "--"+f.Name, "-"+f.Name)
+		}
+		return strings.Join(forms, sep)
+	},
+	// alternatives joins the flag names for a pattern, e.g. "db|workspace".
+	"alternatives": func(flags []flagSpec) string {
+		names := make([]string, len(flags))
+		for i, f := range flags {
+			names[i] = f.Name
+		}
+		return strings.Join(names, "|")
+	},
+	// squote quotes s for the single-quoted strings of all three shells.
+	"squote": func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	},
+	"zdesc": func(s string) string {
+		return strings.NewReplacer(":", `\:`, "[", `\[`, "]", `\]`).Replace(s)
+	},
+}
+
+var completionTemplates = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Funcs(completionFuncs).Parse(bashCompletion)),
+	"zsh":  template.Must(template.New("zsh").Funcs(completionFuncs).Parse(zshCompletion)),
+	"fish": template.Must(template.New("fish").Funcs(completionFuncs).Parse(fishCompletion)),
+}
+
+const bashCompletion = `# bash completion for trackyou
+# Install: trackyou completion bash > ~/.local/share/bash-completion/completions/trackyou
+
+_trackyou() {
+    local cur prev cmd flag i=1
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    # Global flags come before the subcommand; bash splits --db=path at "=".
+    while [[ $i -lt $COMP_CWORD ]]; do
+        case "${COMP_WORDS[i]}" in
+        {{dashed .Global "|"}})
+            if [[ ${COMP_WORDS[i+1]} == "=" ]]; then ((i += 3)); else ((i += 2)); fi
+            ;;
+        {{dashed .Global "=*|"}}=*)
+            ((i++))
+            ;;
+        *)
+            break
+            ;;
+        esac
+    done
+
+    if [[ $i -gt $COMP_CWORD ]]; then
+        flag="$prev"
+        if [[ $cur == "=" ]]; then
+            cur=""
+        elif [[ $prev == "=" ]]; then
+            flag="${COMP_WORDS[COMP_CWORD-2]}"
+        fi
+        [[ $flag == --db || $flag == -db ]] && COMPREPLY=($(compgen -f -- "$cur"))
+        return
+    fi
+    if [[ $i -eq $COMP_CWORD ]]; then
+        if [[ $cur == -* ]]; then
+            COMPREPLY=($(compgen -W "{{flagNames .Global}}" -- "$cur"))
+        else
+            COMPREPLY=($(compgen -W "{{names .Commands}}" -- "$cur"))
+        fi
+        return
+    fi
+
+    cmd="${COMP_WORDS[i]}"
+    case "$cmd" in
+{{- range .Commands}}
+    {{.Name}})
+{{- if .Flags}}
+        case "$prev" in
+{{- range .Flags}}
+        --{{.Name}})
+{{- if .Values}}
+            COMPREPLY=($(compgen -W "{{join .Values " "}}" -- "$cur"))
+{{- end}}
+            return
+            ;;
+{{- end}}
+        esac
+        if [[ $cur == -* ]]; then
+            COMPREPLY=($(compgen -W "{{flagNames .Flags}}" -- "$cur"))
+            return
+        fi
+{{- end}}
+        [[ $COMP_CWORD -eq $((i + 1)) ]] || return
+{{- if .Values}}
+        COMPREPLY=($(compgen -W "{{join .Values " "}}" -- "$cur"))
+{{- end}}
+        ;;
+{{- end}}
+    esac
+}
+
+complete -F _trackyou trackyou
+`
+
+const zshCompletion = `#compdef trackyou
+# Install: trackyou completion zsh > "${fpath[1]}/_trackyou"
+
+_trackyou() {
+    local -a commands
+    commands=(
+{{- range .Commands}}
+        {{squote (printf "%s:%s" .Name (zdesc .Summary))}}
+{{- end}}
+    )
+
+    # Global flags come before the subcommand.
+    local i=2
+    while (( i < CURRENT )); do
+        case $words[i] in
+        ({{dashed .Global "|"}})
+            (( i += 2 ))
+            ;;
+        (-(-|)({{alternatives .Global}})=*)
+            (( i++ ))
+            ;;
+        (*)
+            break
+            ;;
+        esac
+    done
+
+    if (( i > CURRENT )); then
+        [[ $words[CURRENT-1] == (--db|-db) ]] && _files
+        return
+    fi
+    if (( i == CURRENT )); then
+        if [[ $PREFIX == (--db|-db)=* ]]; then
+            compset -P '*='
+            _files
+        elif [[ $PREFIX == -* ]]; then
+            compadd -- {{flagNames .Global}}
+        else
+            _describe -t commands 'trackyou command' commands
+        fi
+        return
+    fi
+
+    local cmd=$words[i]
+    words=("${(@)words[i,-1]}")
+    (( CURRENT -= i - 1 ))
+
+    case $cmd in
+{{- range .Commands}}
+    {{.Name}})
+{{- if .Flags}}
+        _arguments{{range .Flags}} \
+            {{squote (printf "--%s[%s]:%s:%s" .Name (zdesc .Usage) .Name (printf "(%s)" (join .Values " ")))}}{{end}}
+{{- else if .Values}}
+        (( CURRENT == 2 )) && compadd -- {{join .Values " "}}
+{{- end}}
+        ;;
+{{- end}}
+    esac
+}
+
+if [[ $funcstack[1] == _trackyou ]]; then
+    _trackyou "$@"
+else
+    compdef _trackyou trackyou
+fi
+`
+
+const fishCompletion = `# fish completion for trackyou
+# Install: trackyou completion fish > ~/.config/fish/completions/trackyou.fish
+
+# __trackyou_using [COMMAND [ARGS]] succeeds when the command line, after the
+# global flags, has no subcommand yet, or has COMMAND followed by ARGS
+# words when given.
+function __trackyou_using
+    set -l words (commandline -opc)
+    set -e words[1]
+    while set -q words[1]
+        if contains -- $words[1] {{dashed .Global " "}}
+            set -e words[1]
+            set -q words[1]; and set -e words[1]
+        else if string match -qr -- '^--?({{alternatives .Global}})=' $words[1]
+            set -e words[1]
+        else
+            break
+        end
+    end
+    if not set -q argv[1]
+        not set -q words[1]
+        return
+    end
+    test "$words[1]" = "$argv[1]"; or return 1
+    set -q argv[2]; or return 0
+    test (count $words) -eq (math $argv[2] + 1)
+end
+
+complete -c trackyou -f
+{{- range .Global}}
+complete -c trackyou -n __trackyou_using -l {{.Name}} -d {{squote .Usage}} -r{{if eq .Name "db"}} -F{{end}}
+{{- end}}
+{{- range .Commands}}
+complete -c trackyou -n __trackyou_using -a {{.Name}} -d {{squote .Summary}}
+{{- end}}
+{{- range $cmd := .Commands}}
+{{- range .Flags}}
+complete -c trackyou -n '__trackyou_using {{$cmd.Name}}' -l {{.Name}} -d {{squote .Usage}}{{if .Values}} -x -a {{squote (join .Values " ")}}{{else}} -r{{end}}
+{{- end}}
+{{- if .Values}}
+complete -c trackyou -n '__trackyou_using {{.Name}} 0' -a {{squote (join .Values " ")}}
+{{- end}}
+{{- end}}
+`
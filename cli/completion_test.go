@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompletion_Scripts(t *testing.T) {
+	for _, shell := range completionShells {
+		t.Run(shell, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			env := Env{DBPath: filepath.Join(t.TempDir(), "tasks.db"), Stdout: &stdout, Stderr: &stderr}
+			if code := Run(env, []string{"completion", shell}); code != 0 {
+				t.Fatalf("expected exit 0, got %d: %s", code, stderr.String())
+			}
+			script := stdout.String()
+			for _, want := range []string{"prompt", "completion", "template", "waybar", "fish"} {
+				if !strings.Contains(script, want) {
+					t.Errorf("expected %s script to mention %q", shell, want)
+				}
+			}
+
+			if path, err := exec.LookPath(shell); err == nil {
+				cmd := exec.Command(path, "-n")
+				cmd.Stdin = strings.NewReader(script)
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Fatalf("%s rejected the generated script: %v\n%s", shell, err, out)
+				}
+			}
+		})
+	}
+}
+
+func TestCompletion_BashGlobalFlags(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	var script, stderr bytes.Buffer
+	if code := Run(Env{Stdout: &script, Stderr: &stderr}, []string{"completion", "bash"}); code != 0 {
+		t.Fatalf("expected exit 0, got %d: %s", code, stderr.String())
+	}
+
+	// Words as bash splits them, the last one being completed.
+	tests := []struct {
+		name  string
+		words []string
+		want  string
+	}{
+		{"command", []string{"trackyou", ""}, "completion prompt"},
+		{"global flags", []string{"trackyou", "-"}, "--db --workspace"},
+		{"after a workspace", []string{"trackyou", "--workspace", "work", ""}, "completion prompt"},
+		{"after a database", []string{"trackyou", "--db", "=", "/tmp/x.db", "--workspace", "work", "pr"}, "prompt"},
+		{"workspace value", []string{"trackyou", "--workspace", ""}, ""},
+		{"command argument", []string{"trackyou", "--workspace", "work", "completion", "f"}, "fish"},
+		{"command flag", []string{"trackyou", "--db=/tmp/x.db", "prompt", "--format", "w"}, "waybar"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := exec.Command(bash, "-c", `eval "$SCRIPT"; COMP_WORDS=("$@"); COMP_CWORD=$(($# - 1)); _trackyou; echo "${COMPREPLY[*]}"`, "bash")
+			cmd.Args = append(cmd.Args, tc.words...)
+			cmd.Env = append(cmd.Environ(), "SCRIPT="+script.String())
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("bash failed: %v\n%s", err, out)
+			}
+			if got := strings.TrimSpace(string(out)); got != tc.want {
+				t.Errorf("completing %q: got %q, want %q", tc.words, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompletion_UnknownShell(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	env := Env{Stdout: &stdout, Stderr: &stderr}
+	if code := Run(env, []string{"completion", "powershell"}); code != 1 {
+		t.Fatalf("expected exit 1, got %d", code)
+	}
+	if code := Run(env, []string{"completion"}); code != 1 {
+		t.Fatalf("expected exit 1 without a shell, got %d", code)
+	}
+}
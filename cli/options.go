@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"trackyou/database"
+)
+
+// Environment variables that select the database when no flag is given.
+const (
+	dbPathEnv    = "TRACKYOU_DB"
+	workspaceEnv = "TRACKYOU_WORKSPACE"
+)
+
+// globalFlags are the flags ParseArgs accepts, for completion.
+var globalFlags = []flagSpec{
+	{Name: "db", Usage: "database file to use"},
+	{Name: "workspace", Usage: "named workspace to use"},
+}
+
+// Options are the global flags accepted before a subcommand or when
+// launching the GUI.
+type Options struct {
+	DBPath    string
+	Workspace string
+}
+
+// ParseArgs splits leading --db and --workspace flags from the rest of the
+// command line. Both the "--db path" and "--db=path" forms are accepted.
+func ParseArgs(args []string) (Options, []string, error) {
+	var opts Options
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(args[0], "=")
+		var target *string
+		switch name {
+		case "--db", "-db":
+			target = &opts.DBPath
+		case "--workspace", "-workspace":
+			target = &opts.Workspace
+		default:
+			return opts, args, nil
+		}
+		if !hasValue {
+			if len(args) < 2 {
+				return opts, nil, fmt.Errorf("flag %s needs a value", name)
+			}
+			value = args[1]
+			args = args[1:]
+		}
+		if value == "" {
+			return opts, nil, fmt.Errorf("flag %s needs a value", name)
+		}
+		*target = value
+		args = args[1:]
+	}
+	return opts, args, nil
+}
+
+// IsCommandLine reports whether args, after any global flags, name a
+// subcommand rather than a GUI launch.
+func IsCommandLine(args []string) bool {
+	_, rest, err := ParseArgs(args)
+	return err == nil && len(rest) > 0 && IsCommand(rest[0])
+}
+
+// Resolve picks the database to open, in precedence order: --db,
+// TRACKYOU_DB, --workspace, TRACKYOU_WORKSPACE, then the workspace last
+// chosen in the GUI. workspace is empty when an explicit path was given.
+func (o Options) Resolve() (dbPath, workspace string, err error) {
+	dbPath = o.DBPath
+	if dbPath == "" {
+		dbPath = os.Getenv(dbPathEnv)
+	}
+	if dbPath != "" {
+		abs, err := filepath.Abs(dbPath)
+		if err != nil {
+			return "", "", err
+		}
+		return abs, "", nil
+	}
+
+	workspace = o.Workspace
+	if workspace == "" {
+		workspace = os.Getenv(workspaceEnv)
+	}
+	if workspace == "" {
+		if workspace, err = database.GetActiveWorkspace(); err != nil {
+			return "", "", err
+		}
+	}
+	dbPath, err = database.GetWorkspaceDBPath(workspace)
+	if err != nil {
+		return "", "", err
+	}
+	return dbPath, workspace, nil
+}
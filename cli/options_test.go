@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		opts      Options
+		rest      []string
+		expectErr bool
+	}{
+		{name: "empty", args: nil, rest: nil},
+		{name: "command only", args: []string{"prompt", "--format", "waybar"}, rest: []string{"prompt", "--format", "waybar"}},
+		{name: "db flag", args: []string{"--db", "/tmp/x.db", "prompt"}, opts: Options{DBPath: "/tmp/x.db"}, rest: []string{"prompt"}},
+		{name: "equals form", args: []string{"--workspace=work", "--db=/tmp/y.db"}, opts: Options{DBPath: "/tmp/y.db", Workspace: "work"}, rest: []string{}},
+		{name: "missing value", args: []string{"--db"}, expectErr: true},
+		{name: "empty value", args: []string{"--workspace="}, expectErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, rest, err := ParseArgs(tc.args)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts != tc.opts || !slices.Equal(rest, tc.rest) {
+				t.Fatalf("got %+v %q, want %+v %q", opts, rest, tc.opts, tc.rest)
+			}
+		})
+	}
+}
+
+func TestIsCommandLine(t *testing.T) {
+	if !IsCommandLine([]string{"--workspace", "work", "prompt"}) {
+		t.Error("expected prompt after global flags to be a command line")
+	}
+	if IsCommandLine([]string{"--workspace", "work"}) || IsCommandLine(nil) || IsCommandLine([]string{"--db"}) {
+		t.Error("expected GUI launches not to be command lines")
+	}
+}
+
+func TestOptionsResolve(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("HOME", configHome)
+	t.Setenv(dbPathEnv, "")
+	t.Setenv(workspaceEnv, "")
+	appDir := filepath.Join(configHome, "TrackYou")
+
+	path, workspace, err := Options{}.Resolve()
+	if err != nil || path != filepath.Join(appDir, "tasks.db") || workspace != "default" {
+		t.Fatalf("expected default workspace, got %s %q (err %v)", path, workspace, err)
+	}
+
+	t.Setenv(workspaceEnv, "personal")
+	path, workspace, _ = Options{}.Resolve()
+	if path != filepath.Join(appDir, "workspaces", "personal", "tasks.db") || workspace != "personal" {
+		t.Fatalf("expected TRACKYOU_WORKSPACE to apply, got %s %q", path, workspace)
+	}
+
+	path, workspace, _ = Options{Workspace: "employer"}.Resolve()
+	if workspace != "employer" {
+		t.Fatalf("expected --workspace to beat the environment, got %q (%s)", workspace, path)
+	}
+
+	custom := filepath.Join(t.TempDir(), "custom.db")
+	t.Setenv(dbPathEnv, custom)
+	path, workspace, _ = Options{Workspace: "employer"}.Resolve()
+	if path != custom || workspace != "" {
+		t.Fatalf("expected TRACKYOU_DB to beat workspaces, got %s %q", path, workspace)
+	}
+
+	flagPath := filepath.Join(t.TempDir(), "flag.db")
+	path, _, _ = Options{DBPath: flagPath}.Resolve()
+	if path != flagPath {
+		t.Fatalf("expected --db to beat TRACKYOU_DB, got %s", path)
+	}
+
+	t.Setenv(dbPathEnv, "")
+	if _, _, err := (Options{Workspace: "../x"}).Resolve(); err == nil {
+		t.Fatal("expected invalid workspace name to fail")
+	}
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"trackyou/state"
+)
+
+// DefaultPromptTemplate is used when neither --template nor
+// TRACKYOU_PROMPT_TEMPLATE is set.
+const DefaultPromptTemplate = "{{.Project}} {{.Elapsed}}"
+
+// promptTemplateEnv overrides the default prompt template.
+const promptTemplateEnv = "TRACKYOU_PROMPT_TEMPLATE"
+
+// goalReachedColor is the i3blocks color used once the workday goal is met.
+const goalReachedColor = "#4CAF50"
+
+// promptFormats are the values accepted by --format.
+var promptFormats = []string{"text", "waybar", "polybar", "i3blocks"}
+
+// PromptData is the template context for `trackyou prompt`.
+type PromptData struct {
+	Running     bool
+	Paused      bool
+	Project     string
+	Description string
+	Elapsed     string
+	Today       string
+	Goal        string
+	Percent     int
+}
+
+type waybarOutput struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Percentage int    `json:"percentage"`
+}
+
+func runPrompt(env Env, args []string) error {
+	flags := flag.NewFlagSet("prompt", flag.ContinueOnError)
+	flags.SetOutput(env.Stderr)
+	defaultTemplate := DefaultPromptTemplate
+	if value := os.Getenv(promptTemplateEnv); value != "" {
+		defaultTemplate = value
+	}
+	tmplText := flags.String("template", defaultTemplate, "Go text/template for the running task")
+	idleText := flags.String("idle", "", "text to print when no task is running")
+	format := flags.String("format", "text", "output format: "+strings.Join(promptFormats, ", "))
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("prompt").Parse(*tmplText)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	s, err := state.Read(state.PathForDB(env.DBPath))
+	if err != nil {
+		return err
+	}
+	data := newPromptData(s, env.Now())
+
+	text := *idleText
+	if data.Running {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+		text = b.String()
+	}
+	todayText := fmt.Sprintf("Today: %s / %s", data.Today, data.Goal)
+
+	switch *format {
+	case "text":
+		if text != "" {
+			fmt.Fprintln(env.Stdout, text)
+		}
+	case "waybar":
+		out := waybarOutput{
+			Text:       text,
+			Tooltip:    todayText,
+			Class:      "idle",
+			Percentage: min(data.Percent, 100),
+		}
+		if data.Running {
+			out.Class = "running"
+			if data.Paused {
+				out.Class = "paused"
+			}
+			out.Tooltip = data.Project + ": " + data.Description + "\n" + todayText
+		}
+		return json.NewEncoder(env.Stdout).Encode(out)
+	case "polybar":
+		fmt.Fprintln(env.Stdout, joinNonEmpty(" | ", text, data.Today+"/"+data.Goal))
+	case "i3blocks":
+		// i3blocks reads full_text, short_text and color from separate lines.
+		fmt.Fprintln(env.Stdout, joinNonEmpty(" | ", text, todayText))
+		fmt.Fprintln(env.Stdout, joinNonEmpty(" ", text, data.Today))
+		if data.Percent >= 100 {
+			fmt.Fprintln(env.Stdout, goalReachedColor)
+		}
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	return nil
+}
+
+func newPromptData(s *state.State, now time.Time) PromptData {
+	today := s.TotalToday(now)
+	data := PromptData{
+		Running:     s.Running,
+		Paused:      s.Running && s.Paused,
+		Project:     s.Project,
+		Description: s.Description,
+		Elapsed:     formatShort(s.Elapsed(now)),
+		Today:       formatShort(today),
+		Goal:        strconv.FormatFloat(s.WorkdayGoalHours, 'f', -1, 64) + "h",
+	}
+	if s.WorkdayGoalHours > 0 {
+		data.Percent = int(math.Floor(today.Hours() / s.WorkdayGoalHours * 100))
+	}
+	return data
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	kept := parts[:0]
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
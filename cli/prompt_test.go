@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"trackyou/state"
+)
+
+func setupPromptEnv(t *testing.T, s *state.State, now time.Time) (Env, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "tasks.db")
+	if s != nil {
+		if err := state.Write(state.PathForDB(dbPath), s); err != nil {
+			t.Fatalf("failed to write state: %v", err)
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	env := Env{DBPath: dbPath, Stdout: &stdout, Stderr: &stderr, Now: func() time.Time { return now }}
+	return env, &stdout, &stderr
+}
+
+func runningState(now time.Time) *state.State {
+	return &state.State{
+		Running:               true,
+		Project:               "Alpha",
+		Description:           "docs",
+		StartTime:             now.Add(-65 * time.Minute),
+		Day:                   state.DayKey(now),
+		CompletedTodaySeconds: int64((3 * time.Hour).Seconds()),
+		WorkdayGoalHours:      8,
+	}
+}
+
+func TestPrompt_DefaultTemplate(t *testing.T) {
+	t.Setenv(promptTemplateEnv, "")
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local)
+	env, stdout, _ := setupPromptEnv(t, runningState(now), now)
+
+	if code := Run(env, []string{"prompt"}); code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	if got := stdout.String(); got != "Alpha 1h05m\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestPrompt_CustomTemplateAndIdle(t *testing.T) {
+	t.Setenv(promptTemplateEnv, "[{{.Project}}]")
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local)
+
+	env, stdout, _ := setupPromptEnv(t, runningState(now), now)
+	Run(env, []string{"prompt"})
+	if got := stdout.String(); got != "[Alpha]\n" {
+		t.Fatalf("expected env template to apply, got %q", got)
+	}
+
+	stdout.Reset()
+	Run(env, []string{"prompt", "--template", "{{.Today}} of {{.Goal}} ({{.Percent}}%)"})
+	if got := stdout.String(); got != "4h05m of 8h (51%)\n" {
+		t.Fatalf("unexpected flag template output %q", got)
+	}
+
+	idleEnv, idleOut, _ := setupPromptEnv(t, nil, now)
+	Run(idleEnv, []string{"prompt"})
+	if idleOut.Len() != 0 {
+		t.Fatalf("expected no output when idle, got %q", idleOut.String())
+	}
+	Run(idleEnv, []string{"prompt", "--idle", "no timer"})
+	if got := idleOut.String(); got != "no timer\n" {
+		t.Fatalf("expected idle text, got %q", got)
+	}
+}
+
+func TestPrompt_Waybar(t *testing.T) {
+	t.Setenv(promptTemplateEnv, "")
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local)
+	env, stdout, _ := setupPromptEnv(t, runningState(now), now)
+
+	if code := Run(env, []string{"prompt", "--format", "waybar"}); code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	var out waybarOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", stdout.String(), err)
+	}
+	if out.Text != "Alpha 1h05m" || out.Class != "running" || out.Percentage != 51 {
+		t.Fatalf("unexpected waybar output %+v", out)
+	}
+	if !strings.Contains(out.Tooltip, "Today: 4h05m / 8h") {
+		t.Errorf("expected tooltip to include today's total, got %q", out.Tooltip)
+	}
+}
+
+func TestPrompt_Paused(t *testing.T) {
+	t.Setenv(promptTemplateEnv, "")
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local)
+	s := runningState(now)
+	s.Paused = true
+	s.ActiveSeconds = int64((20 * time.Minute).Seconds())
+	s.ActiveTodaySeconds = s.ActiveSeconds
+	env, stdout, _ := setupPromptEnv(t, s, now)
+
+	Run(env, []string{"prompt", "--template", "{{.Project}} {{.Elapsed}}{{if .Paused}} (paused){{end}}"})
+	if got := stdout.String(); got != "Alpha 20m (paused)\n" {
+		t.Fatalf("unexpected paused output %q", got)
+	}
+
+	stdout.Reset()
+	Run(env, []string{"prompt", "--format", "waybar"})
+	var out waybarOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", stdout.String(), err)
+	}
+	if out.Class != "paused" {
+		t.Errorf("expected paused class, got %q", out.Class)
+	}
+}
+
+func TestPrompt_PolybarAndI3blocks(t *testing.T) {
+	t.Setenv(promptTemplateEnv, "")
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local)
+	s := runningState(now)
+	s.CompletedTodaySeconds = int64((7 * time.Hour).Seconds())
+	env, stdout, _ := setupPromptEnv(t, s, now)
+
+	Run(env, []string{"prompt", "--format", "polybar"})
+	if got := stdout.String(); got != "Alpha 1h05m | 8h05m/8h\n" {
+		t.Fatalf("unexpected polybar output %q", got)
+	}
+
+	stdout.Reset()
+	Run(env, []string{"prompt", "--format", "i3blocks"})
+	lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected full_text, short_text and color lines, got %q", stdout.String())
+	}
+	if lines[0] != "Alpha 1h05m | Today: 8h05m / 8h" || lines[1] != "Alpha 1h05m 8h05m" || lines[2] != goalReachedColor {
+		t.Fatalf("unexpected i3blocks output %q", lines)
+	}
+}
+
+func TestPrompt_Errors(t *testing.T) {
+	now := time.Now()
+	env, _, stderr := setupPromptEnv(t, runningState(now), now)
+
+	if code := Run(env, []string{"prompt", "--format", "tmux"}); code != 1 {
+		t.Errorf("expected exit 1 for unknown format, got %d", code)
+	}
+	if code := Run(env, []string{"prompt", "--template", "{{.Project"}); code != 1 {
+		t.Errorf("expected exit 1 for bad template, got %d", code)
+	}
+	if code := Run(env, []string{"bogus"}); code != 2 {
+		t.Errorf("expected exit 2 for unknown command, got %d", code)
+	}
+	if stderr.Len() == 0 {
+		t.Error("expected errors on stderr")
+	}
+}
+
+func TestFormatShort(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                             "0m",
+		59 * time.Second:              "0m",
+		12 * time.Minute:              "12m",
+		time.Hour + 5*time.Minute:     "1h05m",
+		10*time.Hour + 30*time.Minute: "10h30m",
+	}
+	for d, want := range tests {
+		if got := formatShort(d); got != want {
+			t.Errorf("formatShort(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,823 @@
+// Package config reads TrackYou's optional config.toml. Values set in the
+// file take precedence over the preferences stored in the database, which in
+// turn take precedence over built-in defaults.
+package config
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"trackyou/database"
+	"trackyou/models"
+
+	"github.com/BurntSushi/toml"
+)
+
+// FileName is the config file's name inside the TrackYou config directory.
+const FileName = "config.toml"
+
+// Preference keys. They match both the TOML keys and the keys of the
+// database's preferences table.
+const (
+	KeyTheme         = "theme"
+	KeyIdleThreshold = "idle_threshold"
+	KeyWorkdayLength = "workday_length"
+	KeyAPIEnabled    = "api_enabled"
+	KeyAPIPort       = "api_port"
+	KeyMaxTimerHours = "max_timer_hours"
+	KeyEndOfDay      = "end_of_day"
+	KeySleepPolicy   = "sleep_policy"
+	KeySwitchTasks   = "switch_tasks"
+	KeyWeekStart     = "week_start"
+	KeyTargets       = "targets"
+	KeyHolidaysFile  = "holidays_file"
+	KeyBudgetAlerts  = "budget_alerts"
+
+	KeyPomodoroEnabled    = "pomodoro.enabled"
+	KeyPomodoroWork       = "pomodoro.work"
+	KeyPomodoroShortBreak = "pomodoro.short_break"
+	KeyPomodoroLongBreak  = "pomodoro.long_break"
+	KeyPomodoroBreaks     = "pomodoro.breaks"
+
+	KeyRoundingMinutes = "rounding.minutes"
+	KeyRoundingMode    = "rounding.mode"
+	KeyRoundingApply   = "rounding.apply"
+
+	KeyFlexStart          = "flex.start"
+	KeyFlexOpeningBalance = "flex.opening_balance"
+
+	KeyDigestEnabled      = "digest.enabled"
+	KeyDigestTime         = "digest.time"
+	KeyDigestWeekly       = "digest.weekly"
+	KeyDigestReportDir    = "digest.report_dir"
+	KeyDigestReportFormat = "digest.report_format"
+	KeyDigestTimeZone     = "digest.time_zone"
+)
+
+// AbsenceAllowanceKey returns the preference key of an absence type's
+// yearly allowance, set in its [absences.<type>] table.
+func AbsenceAllowanceKey(absenceType string) string {
+	return "absences." + absenceType + ".allowance"
+}
+
+// AbsenceCarryOverKey returns the preference key of an absence type's
+// carry-over rule.
+func AbsenceCarryOverKey(absenceType string) string {
+	return "absences." + absenceType + ".carry_over"
+}
+
+// RoundingRuleKey returns the preference key of a project's or client's
+// rounding rule, set in its [rounding.projects."name"] or
+// [rounding.clients."name"] table.
+func RoundingRuleKey(scope, name string) string {
+	return fmt.Sprintf("rounding.%ss.%q", scope, name)
+}
+
+// ClientKey returns the preference key of the client a project is billed
+// to, set in the [clients] table.
+func ClientKey(project string) string {
+	return fmt.Sprintf("clients.%q", project)
+}
+
+var (
+	themes     = []string{"light", "dark", "system"}
+	weekdays   = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+	roundModes = []string{models.RoundNearest, models.RoundUp, models.RoundDown}
+	roundScope = []string{models.RoundPerEntry, models.RoundPerDay}
+	breakModes = []string{database.PomodoroBreaksGap, database.PomodoroBreaksProject}
+	sleepModes = []string{database.SleepPolicyPause, database.SleepPolicyAsk, database.SleepPolicyIgnore}
+)
+
+// Source says where an effective preference value came from.
+type Source int
+
+const (
+	SourceDefault Source = iota
+	SourceDatabase
+	SourceFile
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceFile:
+		return FileName
+	case SourceDatabase:
+		return "database"
+	default:
+		return "default"
+	}
+}
+
+// File is the parsed contents of config.toml. Nil fields are unset.
+type File struct {
+	Theme         *string  `toml:"theme"`
+	IdleThreshold *int     `toml:"idle_threshold"`
+	WorkdayLength *float64 `toml:"workday_length"`
+	APIEnabled    *bool    `toml:"api_enabled"`
+	APIPort       *int     `toml:"api_port"`
+	MaxTimerHours *float64 `toml:"max_timer_hours"`
+	EndOfDay      *string  `toml:"end_of_day"`
+	SleepPolicy   *string  `toml:"sleep_policy"`
+	SwitchTasks   *bool    `toml:"switch_tasks"`
+	WeekStart     *string  `toml:"week_start"`
+	HolidaysFile  *string  `toml:"holidays_file"`
+	BudgetAlerts  *[]int   `toml:"budget_alerts"`
+	Targets       Targets  `toml:"targets"`
+	Rounding      Rounding `toml:"rounding"`
+	Flex          Flex     `toml:"flex"`
+	Pomodoro      Pomodoro `toml:"pomodoro"`
+	Digest        Digest   `toml:"digest"`
+
+	// Absences holds the [absences.<type>] tables by absence type.
+	Absences map[string]AbsencePolicy `toml:"absences"`
+
+	// Clients holds the [clients] table, the client each project is billed
+	// to, by project name.
+	Clients map[string]string `toml:"clients"`
+
+	// Unknown lists keys the file sets that this version does not know.
+	Unknown []string `toml:"-"`
+}
+
+// Rounding is the [rounding] table. Its [rounding.clients."name"] tables
+// override the rule for the projects billed to a client, and its
+// [rounding.projects."name"] tables for single projects.
+type Rounding struct {
+	RoundingRule
+	Clients  map[string]RoundingRule `toml:"clients"`
+	Projects map[string]RoundingRule `toml:"projects"`
+}
+
+// RoundingRule holds the keys of a rounding table. Minutes is the increment,
+// 0 turning rounding off.
+type RoundingRule struct {
+	Minutes *int    `toml:"minutes"`
+	Mode    *string `toml:"mode"`
+	Apply   *string `toml:"apply"`
+}
+
+func (r RoundingRule) validate(table string) error {
+	if r.Minutes != nil && *r.Minutes < 0 {
+		return fmt.Errorf("%s.minutes must be >= 0", table)
+	}
+	if r.Mode != nil && !slices.Contains(roundModes, *r.Mode) {
+		return fmt.Errorf("%s.mode must be one of %s", table, strings.Join(roundModes, ", "))
+	}
+	if r.Apply != nil && !slices.Contains(roundScope, *r.Apply) {
+		return fmt.Errorf("%s.apply must be one of %s", table, strings.Join(roundScope, ", "))
+	}
+	return nil
+}
+
+// Targets is the [targets] table of daily targets in hours. Unset days keep
+// the saved targets, or workday_length from Monday to Friday and none at the
+// weekend.
+type Targets struct {
+	Monday    *float64 `toml:"monday"`
+	Tuesday   *float64 `toml:"tuesday"`
+	Wednesday *float64 `toml:"wednesday"`
+	Thursday  *float64 `toml:"thursday"`
+	Friday    *float64 `toml:"friday"`
+	Saturday  *float64 `toml:"saturday"`
+	Sunday    *float64 `toml:"sunday"`
+}
+
+// byWeekday returns the targets indexed by time.Weekday.
+func (t Targets) byWeekday() [7]*float64 {
+	return [7]*float64{t.Sunday, t.Monday, t.Tuesday, t.Wednesday, t.Thursday, t.Friday, t.Saturday}
+}
+
+// AbsencePolicy is an [absences.<type>] table, in days.
+type AbsencePolicy struct {
+	Allowance *float64 `toml:"allowance"`
+	CarryOver *float64 `toml:"carry_over"`
+}
+
+// Flex is the [flex] table. Start is the first day of the flex-time balance
+// as YYYY-MM-DD, empty to keep none, and OpeningBalance the balance in hours
+// before it.
+type Flex struct {
+	Start          *string  `toml:"start"`
+	OpeningBalance *float64 `toml:"opening_balance"`
+}
+
+// Pomodoro is the [pomodoro] table. Lengths are in minutes.
+type Pomodoro struct {
+	Enabled    *bool   `toml:"enabled"`
+	Work       *int    `toml:"work"`
+	ShortBreak *int    `toml:"short_break"`
+	LongBreak  *int    `toml:"long_break"`
+	Breaks     *string `toml:"breaks"`
+}
+
+// Digest is the [digest] table. Time is when digests are sent as "HH:MM",
+// empty for end_of_day, and ReportDir the folder reports are written to,
+// relative to the config file unless absolute.
+type Digest struct {
+	Enabled      *bool   `toml:"enabled"`
+	Time         *string `toml:"time"`
+	Weekly       *bool   `toml:"weekly"`
+	ReportDir    *string `toml:"report_dir"`
+	ReportFormat *string `toml:"report_format"`
+	TimeZone     *string `toml:"time_zone"`
+}
+
+// DefaultPath returns <UserConfigDir>/TrackYou/config.toml.
+func DefaultPath() (string, error) {
+	appDir, err := database.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(appDir, FileName), nil
+}
+
+// Load reads and validates the config file. A missing file yields an empty
+// File.
+func Load(path string) (*File, error) {
+	var f File
+	md, err := toml.DecodeFile(path, &f)
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", FileName, err)
+	}
+	for _, key := range md.Undecoded() {
+		f.Unknown = append(f.Unknown, key.String())
+	}
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", FileName, err)
+	}
+	return &f, nil
+}
+
+func (f *File) validate() error {
+	if f.Theme != nil && !slices.Contains(themes, *f.Theme) {
+		return fmt.Errorf("theme must be one of %s", strings.Join(themes, ", "))
+	}
+	if f.IdleThreshold != nil && *f.IdleThreshold < 1 {
+		return errors.New("idle_threshold must be >= 1")
+	}
+	if f.WorkdayLength != nil {
+		if v := *f.WorkdayLength; v <= 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.New("workday_length must be a finite number > 0")
+		}
+	}
+	if f.APIPort != nil && (*f.APIPort < 1 || *f.APIPort > 65535) {
+		return errors.New("api_port must be between 1 and 65535")
+	}
+	if f.MaxTimerHours != nil {
+		if v := *f.MaxTimerHours; v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.New("max_timer_hours must be a finite number >= 0")
+		}
+	}
+	if f.EndOfDay != nil {
+		if _, err := models.ParseTimeOfDay(*f.EndOfDay); err != nil {
+			return fmt.Errorf("end_of_day: %w", err)
+		}
+	}
+	if f.BudgetAlerts != nil {
+		for _, percent := range *f.BudgetAlerts {
+			if percent < 1 || percent > 1000 {
+				return fmt.Errorf("%s must be percentages between 1 and 1000", KeyBudgetAlerts)
+			}
+		}
+	}
+	if f.SleepPolicy != nil && !slices.Contains(sleepModes, *f.SleepPolicy) {
+		return fmt.Errorf("%s must be one of %s", KeySleepPolicy, strings.Join(sleepModes, ", "))
+	}
+	if f.WeekStart != nil && !slices.Contains(weekdays, *f.WeekStart) {
+		return fmt.Errorf("%s must be one of %s", KeyWeekStart, strings.Join(weekdays, ", "))
+	}
+	for day, hours := range f.Targets.byWeekday() {
+		if hours != nil && (*hours < 0 || *hours > 24 || math.IsNaN(*hours)) {
+			return fmt.Errorf("%s.%s must be between 0 and 24", KeyTargets, strings.ToLower(time.Weekday(day).String()))
+		}
+	}
+	for absenceType, policy := range f.Absences {
+		if !slices.Contains(models.AbsenceTypes, absenceType) {
+			return fmt.Errorf("absences.%s: absence type must be one of %s", absenceType, strings.Join(models.AbsenceTypes, ", "))
+		}
+		for key, days := range map[string]*float64{
+			AbsenceAllowanceKey(absenceType): policy.Allowance,
+			AbsenceCarryOverKey(absenceType): policy.CarryOver,
+		} {
+			if days != nil && (*days < 0 || math.IsNaN(*days) || math.IsInf(*days, 0)) {
+				return fmt.Errorf("%s must be a finite number >= 0", key)
+			}
+		}
+	}
+	if err := f.Rounding.validate("rounding"); err != nil {
+		return err
+	}
+	for scope, rules := range map[string]map[string]RoundingRule{
+		models.RoundingForClient:  f.Rounding.Clients,
+		models.RoundingForProject: f.Rounding.Projects,
+	} {
+		for name, rule := range rules {
+			if err := rule.validate(RoundingRuleKey(scope, name)); err != nil {
+				return err
+			}
+		}
+	}
+	for project, client := range f.Clients {
+		if strings.TrimSpace(client) == "" {
+			return fmt.Errorf("%s must name a client", ClientKey(project))
+		}
+	}
+	if f.Flex.Start != nil && *f.Flex.Start != "" {
+		if _, err := time.Parse(time.DateOnly, *f.Flex.Start); err != nil {
+			return fmt.Errorf("%s must be a date as YYYY-MM-DD", KeyFlexStart)
+		}
+	}
+	if f.Flex.OpeningBalance != nil {
+		if v := *f.Flex.OpeningBalance; math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("%s must be a finite number", KeyFlexOpeningBalance)
+		}
+	}
+	for key, minutes := range map[string]*int{
+		KeyPomodoroWork:       f.Pomodoro.Work,
+		KeyPomodoroShortBreak: f.Pomodoro.ShortBreak,
+		KeyPomodoroLongBreak:  f.Pomodoro.LongBreak,
+	} {
+		if minutes != nil && *minutes < 1 {
+			return fmt.Errorf("%s must be >= 1", key)
+		}
+	}
+	if f.Pomodoro.Breaks != nil && !slices.Contains(breakModes, *f.Pomodoro.Breaks) {
+		return fmt.Errorf("%s must be one of %s", KeyPomodoroBreaks, strings.Join(breakModes, ", "))
+	}
+	if f.Digest.Time != nil {
+		if _, err := models.ParseTimeOfDay(*f.Digest.Time); err != nil {
+			return fmt.Errorf("%s: %w", KeyDigestTime, err)
+		}
+	}
+	if f.Digest.ReportFormat != nil && !slices.Contains(models.ReportFormats, *f.Digest.ReportFormat) {
+		return fmt.Errorf("%s must be one of %s", KeyDigestReportFormat, strings.Join(models.ReportFormats, ", "))
+	}
+	if f.Digest.TimeZone != nil {
+		if _, err := models.LoadTimeZone(*f.Digest.TimeZone); err != nil {
+			return fmt.Errorf("%s: %w", KeyDigestTimeZone, err)
+		}
+	}
+	return nil
+}
+
+// Preferences are the effective settings after applying precedence.
+type Preferences struct {
+	Theme         string
+	IdleThreshold int
+	WorkdayLength float64
+	APIEnabled    bool
+	APIPort       int
+	MaxTimerHours float64
+	EndOfDay      string // "HH:MM", empty when disabled
+	SleepPolicy   string
+	SwitchTasks   bool
+	WeekStart     time.Weekday
+	Targets       [7]time.Duration // indexed by time.Weekday
+	HolidaysFile  string           // path of an .ics calendar, empty for none
+	BudgetAlerts  []int            // percentages of a budget to notify at, ascending
+
+	PomodoroEnabled    bool
+	PomodoroWork       int // minutes
+	PomodoroShortBreak int // minutes
+	PomodoroLongBreak  int // minutes
+	PomodoroBreaks     string
+
+	RoundingMinutes int // 0 when rounding is off
+	RoundingMode    string
+	RoundingApply   string
+	// RoundingClients and RoundingProjects hold the rules per client and per
+	// project, from config.toml, with unset keys taken from the rule above,
+	// or else from the database.
+	RoundingClients  map[string]models.RoundingRule
+	RoundingProjects map[string]models.RoundingRule
+	// ProjectClients gives the client each project is billed to.
+	ProjectClients map[string]string
+
+	FlexStart          string  // "YYYY-MM-DD", empty when no balance is kept
+	FlexOpeningBalance float64 // hours
+
+	// AbsencePolicies holds the allowance of each absence type.
+	AbsencePolicies map[string]models.AbsencePolicy
+
+	// Digest says when digests are sent and where their reports go.
+	Digest models.DigestSettings
+
+	// Sources maps each preference key to where its value came from.
+	Sources map[string]Source
+}
+
+// Resolve combines the config file, the database and the defaults. f may be
+// nil when there is no file. On database errors the affected keys fall back
+// to their defaults and the errors are returned alongside the result.
+func Resolve(f *File, db *database.DB) (Preferences, error) {
+	if f == nil {
+		f = &File{}
+	}
+	p := Preferences{Sources: make(map[string]Source)}
+	var errs []error
+
+	// fromDB records the database as the source when it stores key.
+	fromDB := func(key string, err error) {
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		stored, err := db.HasPreference(key)
+		if err != nil {
+			errs = append(errs, err)
+		} else if stored {
+			p.Sources[key] = SourceDatabase
+		}
+	}
+
+	if f.Theme != nil {
+		p.Theme, p.Sources[KeyTheme] = *f.Theme, SourceFile
+	} else {
+		theme, err := db.GetTheme()
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+		p.Theme = theme
+		if !slices.Contains(themes, theme) {
+			p.Theme = themes[0]
+		}
+		fromDB(KeyTheme, err)
+	}
+
+	if f.IdleThreshold != nil {
+		p.IdleThreshold, p.Sources[KeyIdleThreshold] = *f.IdleThreshold, SourceFile
+	} else {
+		var err error
+		p.IdleThreshold, err = db.GetIdleThreshold()
+		fromDB(KeyIdleThreshold, err)
+	}
+
+	if f.WorkdayLength != nil {
+		p.WorkdayLength, p.Sources[KeyWorkdayLength] = *f.WorkdayLength, SourceFile
+	} else {
+		var err error
+		p.WorkdayLength, err = db.GetWorkdayLength()
+		fromDB(KeyWorkdayLength, err)
+	}
+
+	targets, err := db.GetWeekdayTargets()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	p.Targets = models.DefaultWeekdays(time.Duration(p.WorkdayLength * float64(time.Hour)))
+	if targets != "" {
+		p.Targets, _ = models.ParseWeekdayTargets(targets)
+		p.Sources[KeyTargets] = SourceDatabase
+	}
+	for day, hours := range f.Targets.byWeekday() {
+		if hours != nil {
+			p.Targets[day] = time.Duration(*hours * float64(time.Hour)).Round(time.Minute)
+			p.Sources[KeyTargets] = SourceFile
+		}
+	}
+
+	if f.HolidaysFile != nil {
+		p.HolidaysFile, p.Sources[KeyHolidaysFile] = *f.HolidaysFile, SourceFile
+	} else {
+		var err error
+		p.HolidaysFile, err = db.GetHolidaysFile()
+		fromDB(KeyHolidaysFile, err)
+	}
+
+	if f.BudgetAlerts != nil {
+		p.BudgetAlerts, _ = models.ParseBudgetAlerts(models.FormatBudgetAlerts(*f.BudgetAlerts))
+		p.Sources[KeyBudgetAlerts] = SourceFile
+	} else {
+		var err error
+		p.BudgetAlerts, err = db.GetBudgetAlerts()
+		fromDB(KeyBudgetAlerts, err)
+	}
+
+	if f.APIEnabled != nil {
+		p.APIEnabled, p.Sources[KeyAPIEnabled] = *f.APIEnabled, SourceFile
+	} else {
+		var err error
+		p.APIEnabled, err = db.GetAPIEnabled()
+		fromDB(KeyAPIEnabled, err)
+	}
+
+	if f.APIPort != nil {
+		p.APIPort, p.Sources[KeyAPIPort] = *f.APIPort, SourceFile
+	} else {
+		var err error
+		p.APIPort, err = db.GetAPIPort()
+		fromDB(KeyAPIPort, err)
+	}
+
+	if f.MaxTimerHours != nil {
+		p.MaxTimerHours, p.Sources[KeyMaxTimerHours] = *f.MaxTimerHours, SourceFile
+	} else {
+		var err error
+		p.MaxTimerHours, err = db.GetMaxTimerHours()
+		fromDB(KeyMaxTimerHours, err)
+	}
+
+	if f.EndOfDay != nil {
+		p.EndOfDay, p.Sources[KeyEndOfDay] = *f.EndOfDay, SourceFile
+	} else {
+		var err error
+		p.EndOfDay, err = db.GetEndOfDay()
+		fromDB(KeyEndOfDay, err)
+	}
+
+	if f.SleepPolicy != nil {
+		p.SleepPolicy, p.Sources[KeySleepPolicy] = *f.SleepPolicy, SourceFile
+	} else {
+		var err error
+		p.SleepPolicy, err = db.GetSleepPolicy()
+		fromDB(KeySleepPolicy, err)
+	}
+
+	if f.SwitchTasks != nil {
+		p.SwitchTasks, p.Sources[KeySwitchTasks] = *f.SwitchTasks, SourceFile
+	} else {
+		var err error
+		p.SwitchTasks, err = db.GetSwitchTasks()
+		fromDB(KeySwitchTasks, err)
+	}
+
+	if f.WeekStart != nil {
+		p.WeekStart, _ = models.ParseWeekday(*f.WeekStart)
+		p.Sources[KeyWeekStart] = SourceFile
+	} else {
+		var err error
+		p.WeekStart, err = db.GetWeekStart()
+		fromDB(KeyWeekStart, err)
+	}
+
+	if f.Pomodoro.Enabled != nil {
+		p.PomodoroEnabled, p.Sources[KeyPomodoroEnabled] = *f.Pomodoro.Enabled, SourceFile
+	} else {
+		var err error
+		p.PomodoroEnabled, err = db.GetPomodoroEnabled()
+		fromDB(KeyPomodoroEnabled, err)
+	}
+
+	work, shortBreak, longBreak, err := db.GetPomodoroLengths()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, length := range []struct {
+		key     string
+		file    *int
+		stored  int
+		applied *int
+	}{
+		{KeyPomodoroWork, f.Pomodoro.Work, work, &p.PomodoroWork},
+		{KeyPomodoroShortBreak, f.Pomodoro.ShortBreak, shortBreak, &p.PomodoroShortBreak},
+		{KeyPomodoroLongBreak, f.Pomodoro.LongBreak, longBreak, &p.PomodoroLongBreak},
+	} {
+		if length.file != nil {
+			*length.applied, p.Sources[length.key] = *length.file, SourceFile
+		} else {
+			*length.applied = length.stored
+			fromDB(length.key, nil)
+		}
+	}
+
+	if f.Pomodoro.Breaks != nil {
+		p.PomodoroBreaks, p.Sources[KeyPomodoroBreaks] = *f.Pomodoro.Breaks, SourceFile
+	} else {
+		var err error
+		p.PomodoroBreaks, err = db.GetPomodoroBreaks()
+		fromDB(KeyPomodoroBreaks, err)
+	}
+
+	minutes, mode, apply, err := db.GetRounding()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, setting := range []struct {
+		key     string
+		file    *string
+		stored  string
+		applied *string
+	}{
+		{KeyRoundingMode, f.Rounding.Mode, mode, &p.RoundingMode},
+		{KeyRoundingApply, f.Rounding.Apply, apply, &p.RoundingApply},
+	} {
+		if setting.file != nil {
+			*setting.applied, p.Sources[setting.key] = *setting.file, SourceFile
+		} else {
+			*setting.applied = setting.stored
+			fromDB(setting.key, nil)
+		}
+	}
+	if f.Rounding.Minutes != nil {
+		p.RoundingMinutes, p.Sources[KeyRoundingMinutes] = *f.Rounding.Minutes, SourceFile
+	} else {
+		p.RoundingMinutes = minutes
+		fromDB(KeyRoundingMinutes, nil)
+	}
+	p.RoundingClients = make(map[string]models.RoundingRule)
+	p.RoundingProjects = make(map[string]models.RoundingRule)
+	scoped := map[string]map[string]models.RoundingRule{
+		models.RoundingForClient:  p.RoundingClients,
+		models.RoundingForProject: p.RoundingProjects,
+	}
+	stored, err := db.GetRoundingRules()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, rule := range stored {
+		scoped[rule.Scope][rule.Name] = rule.RoundingRule
+		p.Sources[RoundingRuleKey(rule.Scope, rule.Name)] = SourceDatabase
+	}
+	for scope, rules := range map[string]map[string]RoundingRule{
+		models.RoundingForClient:  f.Rounding.Clients,
+		models.RoundingForProject: f.Rounding.Projects,
+	} {
+		for name, rule := range rules {
+			resolved := p.roundingRule()
+			if rule.Minutes != nil {
+				resolved.Increment = time.Duration(*rule.Minutes) * time.Minute
+			}
+			if rule.Mode != nil {
+				resolved.Mode = *rule.Mode
+			}
+			if rule.Apply != nil {
+				resolved.Per = *rule.Apply
+			}
+			scoped[scope][name] = resolved
+			p.Sources[RoundingRuleKey(scope, name)] = SourceFile
+		}
+	}
+	p.ProjectClients, err = db.GetProjectClients()
+	if err != nil {
+		errs = append(errs, err)
+		p.ProjectClients = make(map[string]string)
+	}
+	for project := range p.ProjectClients {
+		p.Sources[ClientKey(project)] = SourceDatabase
+	}
+	for project, client := range f.Clients {
+		p.ProjectClients[project] = client
+		p.Sources[ClientKey(project)] = SourceFile
+	}
+
+	flexStart, flexOpening, err := db.GetFlex()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if f.Flex.Start != nil {
+		p.FlexStart, p.Sources[KeyFlexStart] = *f.Flex.Start, SourceFile
+	} else {
+		p.FlexStart = flexStart
+		fromDB(KeyFlexStart, nil)
+	}
+	if f.Flex.OpeningBalance != nil {
+		p.FlexOpeningBalance, p.Sources[KeyFlexOpeningBalance] = *f.Flex.OpeningBalance, SourceFile
+	} else {
+		p.FlexOpeningBalance = flexOpening
+		fromDB(KeyFlexOpeningBalance, nil)
+	}
+
+	p.Digest, err = db.GetDigest()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, setting := range []struct {
+		key     string
+		file    *bool
+		applied *bool
+	}{
+		{KeyDigestEnabled, f.Digest.Enabled, &p.Digest.Enabled},
+		{KeyDigestWeekly, f.Digest.Weekly, &p.Digest.Weekly},
+	} {
+		if setting.file != nil {
+			*setting.applied, p.Sources[setting.key] = *setting.file, SourceFile
+		} else {
+			fromDB(setting.key, nil)
+		}
+	}
+	for _, setting := range []struct {
+		key     string
+		file    *string
+		applied *string
+	}{
+		{KeyDigestTime, f.Digest.Time, &p.Digest.Time},
+		{KeyDigestReportDir, f.Digest.ReportDir, &p.Digest.ReportDir},
+		{KeyDigestReportFormat, f.Digest.ReportFormat, &p.Digest.ReportFormat},
+		{KeyDigestTimeZone, f.Digest.TimeZone, &p.Digest.TimeZone},
+	} {
+		if setting.file != nil {
+			*setting.applied, p.Sources[setting.key] = *setting.file, SourceFile
+		} else {
+			fromDB(setting.key, nil)
+		}
+	}
+
+	p.AbsencePolicies = make(map[string]models.AbsencePolicy, len(models.AbsenceTypes))
+	for _, absenceType := range models.AbsenceTypes {
+		policy, err := db.GetAbsencePolicy(absenceType)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		file := f.Absences[absenceType]
+		for _, setting := range []struct {
+			key     string
+			file    *float64
+			applied *float64
+		}{
+			{AbsenceAllowanceKey(absenceType), file.Allowance, &policy.Allowance},
+			{AbsenceCarryOverKey(absenceType), file.CarryOver, &policy.CarryOver},
+		} {
+			if setting.file != nil {
+				*setting.applied, p.Sources[setting.key] = *setting.file, SourceFile
+			} else {
+				fromDB(setting.key, nil)
+			}
+		}
+		p.AbsencePolicies[absenceType] = policy
+	}
+
+	return p, errors.Join(errs...)
+}
+
+// roundingRule converts the rounding preferences into the rule for projects
+// without one of their own.
+func (p Preferences) roundingRule() models.RoundingRule {
+	return models.RoundingRule{
+		Increment: time.Duration(p.RoundingMinutes) * time.Minute,
+		Mode:      p.RoundingMode,
+		Per:       p.RoundingApply,
+	}
+}
+
+// RoundingRules converts the rounding preferences into rules for reports.
+func (p Preferences) RoundingRules() models.RoundingRules {
+	return models.RoundingRules{
+		Default:        p.roundingRule(),
+		Clients:        p.RoundingClients,
+		Projects:       p.RoundingProjects,
+		ProjectClients: p.ProjectClients,
+	}
+}
+
+// WorkSchedule combines the daily targets with holidays and absences.
+func (p Preferences) WorkSchedule(holidays []models.Holiday, absences []models.Absence) models.WorkSchedule {
+	return models.WorkSchedule{Weekdays: p.Targets, Holidays: holidays, Absences: absences}
+}
+
+// FlexStartDate returns midnight of the flex-time balance's first day in the
+// local timezone, and whether a balance is kept at all.
+func (p Preferences) FlexStartDate() (time.Time, bool) {
+	start, err := time.ParseInLocation(time.DateOnly, p.FlexStart, time.Local)
+	return start, err == nil
+}
+
+// FlexOpening returns the opening balance as a duration.
+func (p Preferences) FlexOpening() time.Duration {
+	return time.Duration(p.FlexOpeningBalance * float64(time.Hour))
+}
+
+// PomodoroSettings converts the Pomodoro lengths into phase durations.
+func (p Preferences) PomodoroSettings() models.PomodoroSettings {
+	return models.PomodoroSettings{
+		Work:       time.Duration(p.PomodoroWork) * time.Minute,
+		ShortBreak: time.Duration(p.PomodoroShortBreak) * time.Minute,
+		LongBreak:  time.Duration(p.PomodoroLongBreak) * time.Minute,
+	}
+}
+
+// DigestSchedule returns the time of day digests are sent at, falling back
+// to the end of day and then models.DefaultDigestTime, and the time zone
+// their days are taken in.
+func (p Preferences) DigestSchedule() (time.Duration, *time.Location) {
+	at, err := models.ParseTimeOfDay(p.Digest.Time)
+	if err != nil || p.Digest.Time == "" {
+		at, err = models.ParseTimeOfDay(p.EndOfDay)
+		if err != nil || p.EndOfDay == "" {
+			at = models.DefaultDigestTime
+		}
+	}
+	loc, err := models.LoadTimeZone(p.Digest.TimeZone)
+	if err != nil {
+		loc = time.Local
+	}
+	return at, loc
+}
+
+// ForgottenRules converts the forgotten-timer preferences into rules.
+func (p Preferences) ForgottenRules() models.ForgottenRules {
+	endOfDay, _ := models.ParseTimeOfDay(p.EndOfDay)
+	return models.ForgottenRules{
+		MaxContinuous: time.Duration(p.MaxTimerHours * float64(time.Hour)),
+		EndOfDay:      endOfDay,
+	}
+}
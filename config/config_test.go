@@ -0,0 +1,494 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"trackyou/database"
+	"trackyou/models"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func setupTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatalf("failed to create test db: %v", err)
+	}
+	if err := db.InitDB(); err != nil {
+		t.Fatalf("failed to init test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected missing file to load, got %v", err)
+	}
+	if f.Theme != nil || f.IdleThreshold != nil || len(f.Unknown) != 0 {
+		t.Fatalf("expected empty config for missing file, got %+v", f)
+	}
+
+	writeConfig(t, path, `
+theme = "dark"
+idle_threshold = 10
+workday_length = 7.5
+api_enabled = true
+week_start = "sunday"
+colour = "blue"
+
+[rounding]
+minutes = 15
+mode = "up"
+
+[hooks]
+on_start = "notify-send started"
+`)
+	f, err = Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if *f.Theme != "dark" || *f.IdleThreshold != 10 || *f.WorkdayLength != 7.5 || !*f.APIEnabled {
+		t.Errorf("unexpected preferences: %+v", f)
+	}
+	if f.APIPort != nil {
+		t.Errorf("expected api_port unset, got %d", *f.APIPort)
+	}
+	if *f.WeekStart != "sunday" || *f.Rounding.Minutes != 15 || *f.Rounding.Mode != "up" {
+		t.Errorf("unexpected extended settings: %+v", f)
+	}
+	if !slices.Equal(f.Unknown, []string{"colour", "hooks", "hooks.on_start"}) {
+		t.Errorf("expected colour and the hook reported as unknown, got %v", f.Unknown)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"syntax":         `theme = `,
+		"wrong type":     `idle_threshold = "ten"`,
+		"theme":          `theme = "blue"`,
+		"idle threshold": `idle_threshold = 0`,
+		"workday length": `workday_length = -1.0`,
+		"api port":       `api_port = 70000`,
+		"week start":     `week_start = "someday"`,
+		"rounding mode":  "[rounding]\nmode = \"sideways\"",
+		"rounding apply": "[rounding]\napply = \"week\"",
+		"rounding rule":  "[rounding.projects.Acme]\nminutes = -6",
+		"client rule":    "[rounding.clients.Acme]\nmode = \"sideways\"",
+		"client":         "[clients]\nWebsite = \" \"",
+		"max timer":      `max_timer_hours = -2.0`,
+		"end of day":     `end_of_day = "6pm"`,
+		"sleep policy":   `sleep_policy = "snooze"`,
+		"pomodoro work":  "[pomodoro]\nwork = 0",
+		"pomodoro break": "[pomodoro]\nbreaks = \"skip\"",
+		"flex start":     "[flex]\nstart = \"Aug 12\"",
+		"target":         "[targets]\nfriday = 25.0",
+		"absence type":   "[absences.sabbatical]\nallowance = 5.0",
+		"allowance":      "[absences.vacation]\nallowance = -1.0",
+		"budget alerts":  `budget_alerts = [80, 0]`,
+		"digest time":    "[digest]\ntime = \"6pm\"",
+		"report format":  "[digest]\nreport_format = \"pdf\"",
+		"time zone":      "[digest]\ntime_zone = \"Mars/Olympus\"",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			writeConfig(t, path, content)
+			if _, err := Load(path); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestResolve_Rounding(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.SetRounding(15, models.RoundUp, models.RoundPerEntry); err != nil {
+		t.Fatalf("failed to set rounding: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), FileName)
+	writeConfig(t, path, `
+[rounding]
+apply = "day"
+
+[rounding.projects."Client A"]
+minutes = 6
+
+[rounding.projects.Internal]
+minutes = 0
+`)
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	p, err := Resolve(f, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if p.RoundingMinutes != 15 || p.Sources[KeyRoundingMinutes] != SourceDatabase {
+		t.Errorf("expected the increment from the database, got %d from %v", p.RoundingMinutes, p.Sources[KeyRoundingMinutes])
+	}
+	if p.RoundingApply != models.RoundPerDay || p.Sources[KeyRoundingApply] != SourceFile {
+		t.Errorf("expected daily rounding from the file, got %q from %v", p.RoundingApply, p.Sources[KeyRoundingApply])
+	}
+
+	rules := p.RoundingRules()
+	want := models.RoundingRule{Increment: 15 * time.Minute, Mode: models.RoundUp, Per: models.RoundPerDay}
+	if rules.For("Other") != want {
+		t.Errorf("unexpected default rule %+v", rules.For("Other"))
+	}
+	want.Increment = 6 * time.Minute
+	if rules.For("Client A") != want {
+		t.Errorf("expected the project rule to inherit unset keys, got %+v", rules.For("Client A"))
+	}
+	if rules.For("Internal").Enabled() {
+		t.Error("expected rounding off for Internal")
+	}
+}
+
+func TestResolve_RoundingClients(t *testing.T) {
+	db := setupTestDB(t)
+	for _, r := range []models.ScopedRoundingRule{
+		{Scope: models.RoundingForClient, Name: "Acme Corp", RoundingRule: models.RoundingRule{Increment: 15 * time.Minute, Mode: models.RoundNearest, Per: models.RoundPerEntry}},
+		{Scope: models.RoundingForClient, Name: "Beta Ltd", RoundingRule: models.RoundingRule{Increment: 30 * time.Minute, Mode: models.RoundDown, Per: models.RoundPerDay}},
+	} {
+		if err := db.SetRoundingRule(r); err != nil {
+			t.Fatalf("failed to set rounding rule: %v", err)
+		}
+	}
+	for project, client := range map[string]string{"Website": "Beta Ltd", "App": "Beta Ltd"} {
+		if err := db.SetProjectClient(project, client); err != nil {
+			t.Fatalf("failed to set project client: %v", err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), FileName)
+	writeConfig(t, path, `
+[rounding]
+mode = "up"
+
+[rounding.clients."Acme Corp"]
+minutes = 6
+
+[clients]
+Website = "Acme Corp"
+`)
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	p, err := Resolve(f, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+
+	rules := p.RoundingRules()
+	want := models.RoundingRule{Increment: 6 * time.Minute, Mode: models.RoundUp, Per: models.RoundPerEntry}
+	if rules.For("Website") != want {
+		t.Errorf("expected the file's client and rule to win, got %+v", rules.For("Website"))
+	}
+	want = models.RoundingRule{Increment: 30 * time.Minute, Mode: models.RoundDown, Per: models.RoundPerDay}
+	if rules.For("App") != want {
+		t.Errorf("expected the saved client rule, got %+v", rules.For("App"))
+	}
+	if rules.For("Other").Enabled() {
+		t.Error("expected no rounding for projects without a client")
+	}
+	for key, source := range map[string]Source{
+		RoundingRuleKey(models.RoundingForClient, "Acme Corp"): SourceFile,
+		RoundingRuleKey(models.RoundingForClient, "Beta Ltd"):  SourceDatabase,
+		ClientKey("Website"): SourceFile,
+		ClientKey("App"):     SourceDatabase,
+	} {
+		if p.Sources[key] != source {
+			t.Errorf("expected %s from %v, got %v", key, source, p.Sources[key])
+		}
+	}
+}
+
+func TestResolve_Precedence(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.SetIdleThreshold(12); err != nil {
+		t.Fatalf("failed to set threshold: %v", err)
+	}
+	if err := db.SetWorkdayLength(6); err != nil {
+		t.Fatalf("failed to set workday length: %v", err)
+	}
+
+	theme, port := "system", 9000
+	p, err := Resolve(&File{Theme: &theme, APIPort: &port}, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+
+	if p.Theme != "system" || p.Sources[KeyTheme] != SourceFile {
+		t.Errorf("expected theme from file, got %q from %v", p.Theme, p.Sources[KeyTheme])
+	}
+	if p.APIPort != 9000 || p.Sources[KeyAPIPort] != SourceFile {
+		t.Errorf("expected port from file, got %d from %v", p.APIPort, p.Sources[KeyAPIPort])
+	}
+	if p.IdleThreshold != 12 || p.Sources[KeyIdleThreshold] != SourceDatabase {
+		t.Errorf("expected threshold from database, got %d from %v", p.IdleThreshold, p.Sources[KeyIdleThreshold])
+	}
+	if p.WorkdayLength != 6 || p.Sources[KeyWorkdayLength] != SourceDatabase {
+		t.Errorf("expected workday length from database, got %v from %v", p.WorkdayLength, p.Sources[KeyWorkdayLength])
+	}
+	if p.APIEnabled || p.Sources[KeyAPIEnabled] != SourceDefault {
+		t.Errorf("expected API enabled from defaults, got %v from %v", p.APIEnabled, p.Sources[KeyAPIEnabled])
+	}
+	if p.MaxTimerHours != database.DefaultMaxTimerHours || p.EndOfDay != "" {
+		t.Errorf("expected forgotten-timer defaults, got %v %q", p.MaxTimerHours, p.EndOfDay)
+	}
+	if p.SleepPolicy != database.SleepPolicyAsk || p.Sources[KeySleepPolicy] != SourceDefault {
+		t.Errorf("expected to ask after sleep by default, got %q from %v", p.SleepPolicy, p.Sources[KeySleepPolicy])
+	}
+	if !p.SwitchTasks || p.Sources[KeySwitchTasks] != SourceDefault {
+		t.Errorf("expected task switching on by default, got %v from %v", p.SwitchTasks, p.Sources[KeySwitchTasks])
+	}
+
+	if p.WeekStart != time.Monday || p.Sources[KeyWeekStart] != SourceDefault {
+		t.Errorf("expected weeks to start on Monday by default, got %v from %v", p.WeekStart, p.Sources[KeyWeekStart])
+	}
+
+	weekStart := "saturday"
+	p, err = Resolve(&File{WeekStart: &weekStart}, db)
+	if err != nil || p.WeekStart != time.Saturday || p.Sources[KeyWeekStart] != SourceFile {
+		t.Errorf("expected weeks to start on Saturday from the file, got %v from %v (err %v)", p.WeekStart, p.Sources[KeyWeekStart], err)
+	}
+
+	endOfDay := "18:00"
+	p, err = Resolve(&File{EndOfDay: &endOfDay}, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if rules := p.ForgottenRules(); rules.EndOfDay != 18*time.Hour || rules.MaxContinuous != 10*time.Hour {
+		t.Errorf("unexpected forgotten-timer rules %+v", rules)
+	}
+
+	if err := db.SetPomodoroLengths(50, 10, 30); err != nil {
+		t.Fatalf("failed to set pomodoro lengths: %v", err)
+	}
+	work, enabled := 45, true
+	p, err = Resolve(&File{Pomodoro: Pomodoro{Enabled: &enabled, Work: &work}}, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if p.Sources[KeyPomodoroWork] != SourceFile || p.Sources[KeyPomodoroShortBreak] != SourceDatabase || p.Sources[KeyPomodoroBreaks] != SourceDefault {
+		t.Errorf("unexpected pomodoro sources %v", p.Sources)
+	}
+	want := models.PomodoroSettings{Work: 45 * time.Minute, ShortBreak: 10 * time.Minute, LongBreak: 30 * time.Minute}
+	if !p.PomodoroEnabled || p.PomodoroSettings() != want || p.PomodoroBreaks != database.PomodoroBreaksGap {
+		t.Errorf("unexpected pomodoro preferences %+v", p)
+	}
+
+	p, err = Resolve(nil, db)
+	if err != nil {
+		t.Fatalf("failed to resolve without file: %v", err)
+	}
+	if p.Theme != "light" || p.Sources[KeyTheme] != SourceDatabase || p.APIPort != database.DefaultAPIPort {
+		t.Errorf("expected database and defaults without a file, got %+v", p)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan struct{}, 10)
+	if err := Watch(ctx, path, func() { changed <- struct{}{} }); err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+
+	writeConfig(t, filepath.Join(filepath.Dir(path), "other.toml"), `theme = "dark"`)
+	writeConfig(t, path, `theme = "dark"`)
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a change notification")
+	}
+	select {
+	case <-changed:
+		t.Fatal("expected the write burst to be coalesced")
+	case <-time.After(2 * reloadDelay):
+	}
+}
+
+func TestResolve_Flex(t *testing.T) {
+	db := setupTestDB(t)
+	p, err := Resolve(nil, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if _, ok := p.FlexStartDate(); ok || p.Sources[KeyFlexStart] != SourceDefault {
+		t.Errorf("expected no flex-time balance by default, got %q from %v", p.FlexStart, p.Sources[KeyFlexStart])
+	}
+
+	if err := db.SetFlex("2024-08-12", 3); err != nil {
+		t.Fatalf("failed to set flex-time balance: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), FileName)
+	writeConfig(t, path, "[flex]\nopening_balance = -1.5\n")
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	p, err = Resolve(f, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	start, ok := p.FlexStartDate()
+	if !ok || !start.Equal(time.Date(2024, 8, 12, 0, 0, 0, 0, time.Local)) || p.Sources[KeyFlexStart] != SourceDatabase {
+		t.Errorf("expected the start date from the database, got %q from %v", p.FlexStart, p.Sources[KeyFlexStart])
+	}
+	if p.FlexOpening() != -90*time.Minute || p.Sources[KeyFlexOpeningBalance] != SourceFile {
+		t.Errorf("expected -1.5h from the file, got %v from %v", p.FlexOpening(), p.Sources[KeyFlexOpeningBalance])
+	}
+}
+
+func TestResolve_Targets(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.SetWorkdayLength(7.5); err != nil {
+		t.Fatalf("failed to set workday length: %v", err)
+	}
+	p, err := Resolve(nil, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if got := models.FormatWeekdayTargets(p.Targets); got != "7.5 7.5 7.5 7.5 7.5 0 0" || p.Sources[KeyTargets] != SourceDefault {
+		t.Errorf("expected the workday length from Monday to Friday, got %q from %v", got, p.Sources[KeyTargets])
+	}
+
+	if err := db.SetWeekdayTargets("8 8 8 8 6 0 0"); err != nil {
+		t.Fatalf("failed to set targets: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), FileName)
+	writeConfig(t, path, "holidays_file = \"holidays.ics\"\n\n[targets]\nsaturday = 2.0\n")
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	p, err = Resolve(f, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if got := models.FormatWeekdayTargets(p.Targets); got != "8 8 8 8 6 2 0" || p.Sources[KeyTargets] != SourceFile {
+		t.Errorf("expected the file to override Saturday only, got %q from %v", got, p.Sources[KeyTargets])
+	}
+	if p.HolidaysFile != "holidays.ics" || p.Sources[KeyHolidaysFile] != SourceFile {
+		t.Errorf("expected the holidays file from config.toml, got %q", p.HolidaysFile)
+	}
+}
+
+func TestResolve_AbsencePolicies(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.SetAbsencePolicy(models.AbsenceVacation, models.AbsencePolicy{Allowance: 25, CarryOver: 10}); err != nil {
+		t.Fatalf("failed to set allowance: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), FileName)
+	writeConfig(t, path, "[absences.vacation]\ncarry_over = 5.0\n")
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	p, err := Resolve(f, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	want := models.AbsencePolicy{Allowance: 25, CarryOver: 5}
+	if got := p.AbsencePolicies[models.AbsenceVacation]; got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if p.Sources[AbsenceAllowanceKey(models.AbsenceVacation)] != SourceDatabase || p.Sources[AbsenceCarryOverKey(models.AbsenceVacation)] != SourceFile {
+		t.Errorf("unexpected sources %v", p.Sources)
+	}
+	if got := p.AbsencePolicies[models.AbsenceSick]; got != (models.AbsencePolicy{}) {
+		t.Errorf("expected no sick leave allowance, got %+v", got)
+	}
+}
+
+func TestResolve_BudgetAlerts(t *testing.T) {
+	db := setupTestDB(t)
+	p, err := Resolve(nil, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if !slices.Equal(p.BudgetAlerts, []int{80, 100}) || p.Sources[KeyBudgetAlerts] != SourceDefault {
+		t.Errorf("expected alerts at 80%% and 100%% by default, got %v from %v", p.BudgetAlerts, p.Sources[KeyBudgetAlerts])
+	}
+
+	if err := db.SetBudgetAlerts([]int{90}); err != nil {
+		t.Fatalf("failed to set alerts: %v", err)
+	}
+	if p, _ := Resolve(nil, db); !slices.Equal(p.BudgetAlerts, []int{90}) || p.Sources[KeyBudgetAlerts] != SourceDatabase {
+		t.Errorf("expected the alert at 90%% from the database, got %v from %v", p.BudgetAlerts, p.Sources[KeyBudgetAlerts])
+	}
+
+	path := filepath.Join(t.TempDir(), FileName)
+	writeConfig(t, path, "budget_alerts = [100, 75]\n")
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if p, _ := Resolve(f, db); !slices.Equal(p.BudgetAlerts, []int{75, 100}) || p.Sources[KeyBudgetAlerts] != SourceFile {
+		t.Errorf("expected the sorted alerts from the file, got %v from %v", p.BudgetAlerts, p.Sources[KeyBudgetAlerts])
+	}
+}
+
+func TestResolve_Digest(t *testing.T) {
+	db := setupTestDB(t)
+	p, err := Resolve(nil, db)
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if p.Digest != models.DefaultDigestSettings() || p.Sources[KeyDigestEnabled] != SourceDefault {
+		t.Errorf("expected the default digest settings, got %+v from %v", p.Digest, p.Sources[KeyDigestEnabled])
+	}
+	if at, loc := p.DigestSchedule(); at != models.DefaultDigestTime || loc != time.Local {
+		t.Errorf("expected the default time in local time, got %v in %v", at, loc)
+	}
+
+	if err := db.SetEndOfDay("17:00"); err != nil {
+		t.Fatalf("failed to set end of day: %v", err)
+	}
+	if err := db.SetDigest(models.DigestSettings{Enabled: true, ReportFormat: models.ReportHTML}); err != nil {
+		t.Fatalf("failed to set digest settings: %v", err)
+	}
+	p, _ = Resolve(nil, db)
+	if !p.Digest.Enabled || p.Digest.Weekly || p.Sources[KeyDigestEnabled] != SourceDatabase {
+		t.Errorf("expected daily digests only from the database, got %+v", p.Digest)
+	}
+	if at, _ := p.DigestSchedule(); at != 17*time.Hour {
+		t.Errorf("expected digests at the end of day, got %v", at)
+	}
+
+	path := filepath.Join(t.TempDir(), FileName)
+	writeConfig(t, path, "[digest]\ntime = \"16:30\"\nweekly = true\nreport_dir = \"reports\"\ntime_zone = \"Asia/Tokyo\"\n")
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	p, _ = Resolve(f, db)
+	want := models.DigestSettings{Enabled: true, Time: "16:30", Weekly: true, ReportDir: "reports", ReportFormat: models.ReportHTML, TimeZone: "Asia/Tokyo"}
+	if p.Digest != want || p.Sources[KeyDigestTime] != SourceFile || p.Sources[KeyDigestReportFormat] != SourceDatabase {
+		t.Errorf("expected %+v, got %+v", want, p.Digest)
+	}
+	if at, loc := p.DigestSchedule(); at != 16*time.Hour+30*time.Minute || loc.String() != "Asia/Tokyo" {
+		t.Errorf("expected 16:30 in Tokyo, got %v in %v", at, loc)
+	}
+}
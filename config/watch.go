@@ -0,0 +1,56 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay coalesces the burst of events editors produce when saving.
+const reloadDelay = 250 * time.Millisecond
+
+// Watch calls onChange after the file at path is created, written, replaced
+// or removed, until ctx is cancelled. The parent directory is watched rather
+// than the file so editors that save by renaming a temp file are noticed.
+// onChange runs on Watch's goroutine.
+func Watch(ctx context.Context, path string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+		timer := time.NewTimer(reloadDelay)
+		timer.Stop()
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Base(event.Name) != filepath.Base(path) || event.Op == fsnotify.Chmod {
+					continue
+				}
+				timer.Reset(reloadDelay)
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			case <-timer.C:
+				onChange()
+			}
+		}
+	}()
+	return nil
+}
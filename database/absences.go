@@ -0,0 +1,48 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"trackyou/models"
+)
+
+// AddAbsence saves an absence and stores the generated ID on it
+func (db *DB) AddAbsence(a *models.Absence) error {
+	if !slices.Contains(models.AbsenceTypes, a.Type) {
+		return fmt.Errorf("unknown absence type %q", a.Type)
+	}
+	result, err := db.Exec(`INSERT INTO absences (date, type, half, note) VALUES (?, ?, ?, ?)`,
+		a.Date, a.Type, a.Half, a.Note)
+	if err != nil {
+		return err
+	}
+	a.ID, err = result.LastInsertId()
+	return err
+}
+
+// GetAbsences retrieves all absences, earliest first, with their dates in
+// the local timezone
+func (db *DB) GetAbsences() ([]models.Absence, error) {
+	rows, err := db.Query(`SELECT id, date, type, half, note FROM absences ORDER BY date, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var absences []models.Absence
+	for rows.Next() {
+		var a models.Absence
+		if err := rows.Scan(&a.ID, &a.Date, &a.Type, &a.Half, &a.Note); err != nil {
+			return nil, err
+		}
+		a.Date = a.Date.Local()
+		absences = append(absences, a)
+	}
+	return absences, rows.Err()
+}
+
+// DeleteAbsence removes an absence
+func (db *DB) DeleteAbsence(id int64) error {
+	_, err := db.Exec(`DELETE FROM absences WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"time"
+
+	"trackyou/models"
+)
+
+// SetBudget saves a project's budget, replacing any it had
+func (db *DB) SetBudget(b models.Budget) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	_, err := db.Exec(`INSERT OR REPLACE INTO budgets (project_name, hours, period) VALUES (?, ?, ?)`,
+		b.ProjectName, b.Limit.Hours(), b.Period)
+	return err
+}
+
+// GetBudgets retrieves the budgets of all projects, by project name
+func (db *DB) GetBudgets() ([]models.Budget, error) {
+	rows, err := db.Query(`SELECT project_name, hours, period FROM budgets ORDER BY project_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var budgets []models.Budget
+	for rows.Next() {
+		var b models.Budget
+		var hours float64
+		if err := rows.Scan(&b.ProjectName, &hours, &b.Period); err != nil {
+			return nil, err
+		}
+		b.Limit = time.Duration(hours * float64(time.Hour)).Round(time.Second)
+		budgets = append(budgets, b)
+	}
+	return budgets, rows.Err()
+}
+
+// DeleteBudget removes a project's budget
+func (db *DB) DeleteBudget(projectName string) error {
+	_, err := db.Exec(`DELETE FROM budgets WHERE project_name = ?`, projectName)
+	return err
+}
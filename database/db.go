@@ -4,9 +4,9 @@ import (
 	"database/sql"
 	"fmt"
 	"math"
-	"os"
-	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 	"trackyou/models"
 
@@ -16,18 +16,38 @@ import (
 // GetDefaultDBPath returns the platform-specific default path for the database file.
 // It ensures the directory structure exists.
 func GetDefaultDBPath() (string, error) {
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		return "", err
-	}
+	return GetWorkspaceDBPath(DefaultWorkspace)
+}
 
-	appDir := filepath.Join(configDir, "TrackYou")
-	if err := os.MkdirAll(appDir, 0700); err != nil {
-		return "", err
-	}
+// DefaultAPIPort is the loopback port used by the local REST API when none
+// has been configured.
+const DefaultAPIPort = 47711
 
-	return filepath.Join(appDir, "tasks.db"), nil
-}
+// DefaultMaxTimerHours is the longest a timer may run without a pause before
+// it is reported as probably forgotten.
+const DefaultMaxTimerHours = 10.0
+
+// Default Pomodoro phase lengths in minutes.
+const (
+	DefaultPomodoroWork       = 25
+	DefaultPomodoroShortBreak = 5
+	DefaultPomodoroLongBreak  = 15
+)
+
+// Pomodoro break modes: breaks either pause the task, leaving gaps, or are
+// recorded as tasks of the Break project.
+const (
+	PomodoroBreaksGap     = "gap"
+	PomodoroBreaksProject = "project"
+)
+
+// Sleep policies: what happens to a running timer's time while the computer
+// was suspended or its screen locked.
+const (
+	SleepPolicyPause  = "pause"
+	SleepPolicyAsk    = "ask"
+	SleepPolicyIgnore = "ignore"
+)
 
 type DB struct {
 	*sql.DB
@@ -58,10 +78,53 @@ func (db *DB) InitDB() error {
 			end_time DATETIME NOT NULL,
 			duration INTEGER NOT NULL
 		);`,
+		`CREATE TABLE IF NOT EXISTS task_segments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+			start_time DATETIME NOT NULL,
+			end_time DATETIME NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_task_segments_task_id ON task_segments(task_id);`,
 		`CREATE TABLE IF NOT EXISTS preferences (
 			key TEXT PRIMARY KEY,
 			value TEXT NOT NULL
 		);`,
+		`CREATE TABLE IF NOT EXISTS flex_corrections (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			date DATETIME NOT NULL,
+			amount INTEGER NOT NULL,
+			note TEXT NOT NULL DEFAULT ''
+		);`,
+		`CREATE TABLE IF NOT EXISTS holidays (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			date DATETIME NOT NULL,
+			name TEXT NOT NULL DEFAULT '',
+			yearly INTEGER NOT NULL DEFAULT 0
+		);`,
+		`CREATE TABLE IF NOT EXISTS absences (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			date DATETIME NOT NULL,
+			type TEXT NOT NULL,
+			half INTEGER NOT NULL DEFAULT 0,
+			note TEXT NOT NULL DEFAULT ''
+		);`,
+		`CREATE TABLE IF NOT EXISTS budgets (
+			project_name TEXT PRIMARY KEY,
+			hours REAL NOT NULL,
+			period TEXT NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS rounding_rules (
+			scope TEXT NOT NULL,
+			name TEXT NOT NULL,
+			minutes INTEGER NOT NULL,
+			mode TEXT NOT NULL,
+			apply TEXT NOT NULL,
+			PRIMARY KEY (scope, name)
+		);`,
+		`CREATE TABLE IF NOT EXISTS project_clients (
+			project_name TEXT PRIMARY KEY,
+			client TEXT NOT NULL
+		);`,
 	}
 
 	for _, query := range queries {
@@ -69,6 +132,12 @@ func (db *DB) InitDB() error {
 			return err
 		}
 	}
+	if err := db.addColumn("tasks", "pomodoros", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := db.addColumn("tasks", "estimate", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
 
 	// Set default theme if not exists
 	_, err := db.Exec(`
@@ -96,6 +165,31 @@ func (db *DB) InitDB() error {
 	return err
 }
 
+// addColumn adds a column to a table created by an older version, if it is
+// not there yet.
+func (db *DB) addColumn(table, column, definition string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
 // GetWorkdayLength retrieves the workday length preference in hours
 func (db *DB) GetWorkdayLength() (float64, error) {
 	var length string
@@ -125,25 +219,99 @@ func (db *DB) SetWorkdayLength(hours float64) error {
 	return err
 }
 
-// SaveTask saves a task to the database
+// SaveTask saves a task and its segments to the database and stores the
+// generated ID on it
 func (db *DB) SaveTask(task *models.Task) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-	INSERT INTO tasks (project_name, description, start_time, end_time, duration)
-	VALUES (?, ?, ?, ?, ?)`
+	INSERT INTO tasks (project_name, description, start_time, end_time, duration, pomodoros, estimate)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := db.Exec(query,
+	result, err := tx.Exec(query,
 		task.ProjectName,
 		task.Description,
 		task.StartTime,
 		task.EndTime,
-		task.Duration.Nanoseconds())
-	return err
+		task.Duration.Nanoseconds(),
+		task.Pomodoros,
+		task.Estimate.Nanoseconds())
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := replaceSegments(tx, id, task.Segments); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	task.ID = id
+	return nil
+}
+
+// GetTask retrieves a single task by ID. It returns sql.ErrNoRows when no
+// task with that ID exists.
+func (db *DB) GetTask(id int64) (*models.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = ?`
+
+	task, err := scanTask(db.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := db.loadSegments([]*models.Task{task}); err != nil {
+		return nil, err
+	}
+	return task, nil
 }
 
 // GetTasks retrieves all tasks from the database
 func (db *DB) GetTasks() ([]*models.Task, error) {
-	query := `SELECT id, project_name, description, start_time, end_time, duration FROM tasks`
-	rows, err := db.Query(query)
+	return db.queryTasks(`SELECT ` + taskColumns + ` FROM tasks`)
+}
+
+// taskColumns lists the tasks columns read by scanTask, in order.
+const taskColumns = `id, project_name, description, start_time, end_time, duration, pomodoros, estimate`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTask reads a task selected with taskColumns.
+func scanTask(row rowScanner) (*models.Task, error) {
+	task := &models.Task{}
+	var duration, estimate int64
+	err := row.Scan(
+		&task.ID,
+		&task.ProjectName,
+		&task.Description,
+		&task.StartTime,
+		&task.EndTime,
+		&duration,
+		&task.Pomodoros,
+		&estimate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	task.Duration = time.Duration(duration)
+	task.Estimate = time.Duration(estimate)
+	return task, nil
+}
+
+// queryTasks runs a query selecting taskColumns and loads the segments of
+// the resulting tasks.
+func (db *DB) queryTasks(query string, args ...any) ([]*models.Task, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -151,22 +319,19 @@ func (db *DB) GetTasks() ([]*models.Task, error) {
 
 	var tasks []*models.Task
 	for rows.Next() {
-		task := &models.Task{}
-		var duration int64
-		err := rows.Scan(
-			&task.ID,
-			&task.ProjectName,
-			&task.Description,
-			&task.StartTime,
-			&task.EndTime,
-			&duration,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
-		task.Duration = time.Duration(duration)
 		tasks = append(tasks, task)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := db.loadSegments(tasks); err != nil {
+		return nil, err
+	}
 	return tasks, nil
 }
 
@@ -196,28 +361,52 @@ func (db *DB) GetProjectNames() ([]string, error) {
 	return projectNames, nil
 }
 
-// UpdateTask updates an existing task in the database
+// UpdateTask updates an existing task and replaces its segments
 func (db *DB) UpdateTask(task *models.Task) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 	UPDATE tasks 
-	SET project_name = ?, description = ?, start_time = ?, end_time = ?, duration = ?
+	SET project_name = ?, description = ?, start_time = ?, end_time = ?, duration = ?, pomodoros = ?, estimate = ?
 	WHERE id = ?`
 
-	_, err := db.Exec(query,
+	_, err = tx.Exec(query,
 		task.ProjectName,
 		task.Description,
 		task.StartTime,
 		task.EndTime,
 		task.Duration.Nanoseconds(),
+		task.Pomodoros,
+		task.Estimate.Nanoseconds(),
 		task.ID)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := replaceSegments(tx, task.ID, task.Segments); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-// DeleteTask deletes a task from the database
+// DeleteTask deletes a task and its segments from the database
 func (db *DB) DeleteTask(id int64) error {
-	query := `DELETE FROM tasks WHERE id = ?`
-	_, err := db.Exec(query, id)
-	return err
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceSegments(tx, id, nil); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM tasks WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetTheme retrieves the current theme preference
@@ -267,3 +456,527 @@ func (db *DB) SetIdleThreshold(minutes int) error {
 	_, err := db.Exec(query, strconv.Itoa(minutes))
 	return err
 }
+
+// getPreference reads a raw preference value. ok is false when the key has
+// never been stored.
+func (db *DB) getPreference(key string) (value string, ok bool, err error) {
+	err = db.QueryRow("SELECT value FROM preferences WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// HasPreference reports whether key has a value stored in the database.
+func (db *DB) HasPreference(key string) (bool, error) {
+	_, ok, err := db.getPreference(key)
+	return ok, err
+}
+
+// setPreference stores a raw preference value, replacing any previous one.
+func (db *DB) setPreference(key, value string) error {
+	query := `
+	INSERT OR REPLACE INTO preferences (key, value)
+	VALUES (?, ?)`
+	_, err := db.Exec(query, key, value)
+	return err
+}
+
+// GetAPIEnabled reports whether the local REST API should be started
+func (db *DB) GetAPIEnabled() (bool, error) {
+	value, ok, err := db.getPreference("api_enabled")
+	if err != nil || !ok {
+		return false, err
+	}
+	return value == "1", nil
+}
+
+// SetAPIEnabled saves whether the local REST API should be started
+func (db *DB) SetAPIEnabled(enabled bool) error {
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	return db.setPreference("api_enabled", value)
+}
+
+// GetAPIPort retrieves the loopback port for the local REST API
+func (db *DB) GetAPIPort() (int, error) {
+	value, ok, err := db.getPreference("api_port")
+	if err != nil || !ok {
+		return DefaultAPIPort, err
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return DefaultAPIPort, nil
+	}
+	return port, nil
+}
+
+// SetAPIPort saves the loopback port for the local REST API
+func (db *DB) SetAPIPort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("api port must be between 1 and 65535")
+	}
+	return db.setPreference("api_port", strconv.Itoa(port))
+}
+
+// GetAPIToken retrieves the bearer token for the local REST API. An empty
+// string means no token has been generated yet.
+func (db *DB) GetAPIToken() (string, error) {
+	value, _, err := db.getPreference("api_token")
+	return value, err
+}
+
+// SetAPIToken saves the bearer token for the local REST API
+func (db *DB) SetAPIToken(token string) error {
+	if token == "" {
+		return fmt.Errorf("api token must not be empty")
+	}
+	return db.setPreference("api_token", token)
+}
+
+// GetMaxTimerHours retrieves the longest continuous run of a timer, in hours,
+// before it counts as forgotten. Zero disables the rule.
+func (db *DB) GetMaxTimerHours() (float64, error) {
+	value, ok, err := db.getPreference("max_timer_hours")
+	if err != nil || !ok {
+		return DefaultMaxTimerHours, err
+	}
+	hours, err := strconv.ParseFloat(value, 64)
+	if err != nil || hours < 0 || math.IsNaN(hours) || math.IsInf(hours, 0) {
+		return DefaultMaxTimerHours, nil
+	}
+	return hours, nil
+}
+
+// SetMaxTimerHours saves the longest continuous run of a timer in hours
+func (db *DB) SetMaxTimerHours(hours float64) error {
+	if hours < 0 || math.IsNaN(hours) || math.IsInf(hours, 0) {
+		return fmt.Errorf("max timer length must be a finite number >= 0")
+	}
+	return db.setPreference("max_timer_hours", strconv.FormatFloat(hours, 'f', 2, 64))
+}
+
+// GetEndOfDay retrieves the "HH:MM" time after which a running timer counts
+// as forgotten. An empty string disables the rule.
+func (db *DB) GetEndOfDay() (string, error) {
+	value, _, err := db.getPreference("end_of_day")
+	if err != nil {
+		return "", err
+	}
+	if _, err := models.ParseTimeOfDay(value); err != nil {
+		return "", nil
+	}
+	return value, nil
+}
+
+// SetEndOfDay saves the end-of-day time as "HH:MM", or "" to disable it
+func (db *DB) SetEndOfDay(value string) error {
+	if _, err := models.ParseTimeOfDay(value); err != nil {
+		return err
+	}
+	return db.setPreference("end_of_day", value)
+}
+
+// GetSwitchTasks reports whether starting a task while another runs
+// switches to it, which is the default, instead of being refused
+func (db *DB) GetSwitchTasks() (bool, error) {
+	value, ok, err := db.getPreference("switch_tasks")
+	if err != nil || !ok {
+		return true, err
+	}
+	return value == "1", nil
+}
+
+// SetSwitchTasks saves whether starting a task while another runs switches
+// to it
+func (db *DB) SetSwitchTasks(enabled bool) error {
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	return db.setPreference("switch_tasks", value)
+}
+
+// GetPomodoroEnabled reports whether new timers run in Pomodoro mode
+func (db *DB) GetPomodoroEnabled() (bool, error) {
+	value, ok, err := db.getPreference("pomodoro.enabled")
+	if err != nil || !ok {
+		return false, err
+	}
+	return value == "1", nil
+}
+
+// SetPomodoroEnabled saves whether new timers run in Pomodoro mode
+func (db *DB) SetPomodoroEnabled(enabled bool) error {
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	return db.setPreference("pomodoro.enabled", value)
+}
+
+// GetPomodoroLengths retrieves the work, short-break and long-break lengths
+// in minutes
+func (db *DB) GetPomodoroLengths() (work, shortBreak, longBreak int, err error) {
+	minutes := func(key string, def int) int {
+		value, ok, getErr := db.getPreference(key)
+		if getErr != nil {
+			err = getErr
+		}
+		if !ok {
+			return def
+		}
+		n, convErr := strconv.Atoi(value)
+		if convErr != nil || n < 1 {
+			return def
+		}
+		return n
+	}
+	work = minutes("pomodoro.work", DefaultPomodoroWork)
+	shortBreak = minutes("pomodoro.short_break", DefaultPomodoroShortBreak)
+	longBreak = minutes("pomodoro.long_break", DefaultPomodoroLongBreak)
+	return work, shortBreak, longBreak, err
+}
+
+// SetPomodoroLengths saves the work, short-break and long-break lengths in
+// minutes
+func (db *DB) SetPomodoroLengths(work, shortBreak, longBreak int) error {
+	if work < 1 || shortBreak < 1 || longBreak < 1 {
+		return fmt.Errorf("pomodoro lengths must be >= 1 minute")
+	}
+	if err := db.setPreference("pomodoro.work", strconv.Itoa(work)); err != nil {
+		return err
+	}
+	if err := db.setPreference("pomodoro.short_break", strconv.Itoa(shortBreak)); err != nil {
+		return err
+	}
+	return db.setPreference("pomodoro.long_break", strconv.Itoa(longBreak))
+}
+
+// GetPomodoroBreaks retrieves how Pomodoro breaks are recorded
+func (db *DB) GetPomodoroBreaks() (string, error) {
+	value, _, err := db.getPreference("pomodoro.breaks")
+	if value != PomodoroBreaksProject {
+		value = PomodoroBreaksGap
+	}
+	return value, err
+}
+
+// SetPomodoroBreaks saves how Pomodoro breaks are recorded
+func (db *DB) SetPomodoroBreaks(mode string) error {
+	if mode != PomodoroBreaksGap && mode != PomodoroBreaksProject {
+		return fmt.Errorf("pomodoro breaks must be %q or %q", PomodoroBreaksGap, PomodoroBreaksProject)
+	}
+	return db.setPreference("pomodoro.breaks", mode)
+}
+
+// GetRounding retrieves the rounding increment in minutes, where 0 turns
+// rounding off, the rounding mode and whether entries or daily totals are
+// rounded. Rounding is off by default
+func (db *DB) GetRounding() (minutes int, mode, per string, err error) {
+	value, ok, err := db.getPreference("rounding.minutes")
+	if ok {
+		if n, convErr := strconv.Atoi(value); convErr == nil && n >= 0 {
+			minutes = n
+		}
+	}
+	mode, _, modeErr := db.getPreference("rounding.mode")
+	if mode != models.RoundUp && mode != models.RoundDown {
+		mode = models.RoundNearest
+	}
+	per, _, perErr := db.getPreference("rounding.apply")
+	if per != models.RoundPerDay {
+		per = models.RoundPerEntry
+	}
+	for _, e := range []error{modeErr, perErr} {
+		if err == nil {
+			err = e
+		}
+	}
+	return minutes, mode, per, err
+}
+
+// SetRounding saves the rounding increment in minutes, the rounding mode and
+// whether entries or daily totals are rounded
+func (db *DB) SetRounding(minutes int, mode, per string) error {
+	if err := validateRounding(minutes, mode, per); err != nil {
+		return err
+	}
+	if err := db.setPreference("rounding.minutes", strconv.Itoa(minutes)); err != nil {
+		return err
+	}
+	if err := db.setPreference("rounding.mode", mode); err != nil {
+		return err
+	}
+	return db.setPreference("rounding.apply", per)
+}
+
+// validateRounding checks the increment, mode and scope of a rounding rule
+func validateRounding(minutes int, mode, per string) error {
+	if minutes < 0 {
+		return fmt.Errorf("rounding increment must be >= 0 minutes")
+	}
+	if mode != models.RoundNearest && mode != models.RoundUp && mode != models.RoundDown {
+		return fmt.Errorf("rounding mode must be %q, %q or %q", models.RoundNearest, models.RoundUp, models.RoundDown)
+	}
+	if per != models.RoundPerEntry && per != models.RoundPerDay {
+		return fmt.Errorf("rounding must apply per %q or %q", models.RoundPerEntry, models.RoundPerDay)
+	}
+	return nil
+}
+
+// GetWeekStart retrieves the first day of the week, Monday by default
+func (db *DB) GetWeekStart() (time.Weekday, error) {
+	value, ok, err := db.getPreference("week_start")
+	if err != nil || !ok {
+		return time.Monday, err
+	}
+	day, parseErr := models.ParseWeekday(value)
+	if parseErr != nil {
+		return time.Monday, nil
+	}
+	return day, nil
+}
+
+// SetWeekStart saves the first day of the week
+func (db *DB) SetWeekStart(day time.Weekday) error {
+	if day < time.Sunday || day > time.Saturday {
+		return fmt.Errorf("invalid first day of the week %d", day)
+	}
+	return db.setPreference("week_start", strings.ToLower(day.String()))
+}
+
+// GetFlex retrieves the first day of the flex-time balance as YYYY-MM-DD,
+// empty when no balance is kept, and the opening balance in hours
+func (db *DB) GetFlex() (start string, opening float64, err error) {
+	start, _, err = db.getPreference("flex.start")
+	if _, parseErr := time.Parse(time.DateOnly, start); parseErr != nil {
+		start = ""
+	}
+	value, ok, openingErr := db.getPreference("flex.opening_balance")
+	if ok {
+		if v, convErr := strconv.ParseFloat(value, 64); convErr == nil && !math.IsNaN(v) && !math.IsInf(v, 0) {
+			opening = v
+		}
+	}
+	if err == nil {
+		err = openingErr
+	}
+	return start, opening, err
+}
+
+// SetFlex saves the first day of the flex-time balance as YYYY-MM-DD, or ""
+// to keep none, and the opening balance in hours
+func (db *DB) SetFlex(start string, opening float64) error {
+	if start != "" {
+		if _, err := time.Parse(time.DateOnly, start); err != nil {
+			return fmt.Errorf("flex-time start must be a date as YYYY-MM-DD")
+		}
+	}
+	if math.IsNaN(opening) || math.IsInf(opening, 0) {
+		return fmt.Errorf("opening balance must be a finite number of hours")
+	}
+	if err := db.setPreference("flex.start", start); err != nil {
+		return err
+	}
+	return db.setPreference("flex.opening_balance", strconv.FormatFloat(opening, 'f', 2, 64))
+}
+
+// GetWeekdayTargets retrieves the daily targets from Monday to Sunday as
+// models.ParseWeekdayTargets reads them, empty when none are saved
+func (db *DB) GetWeekdayTargets() (string, error) {
+	value, _, err := db.getPreference("targets")
+	if _, parseErr := models.ParseWeekdayTargets(value); parseErr != nil {
+		return "", err
+	}
+	return value, err
+}
+
+// SetWeekdayTargets saves the daily targets from Monday to Sunday, or "" to
+// use the workday length from Monday to Friday
+func (db *DB) SetWeekdayTargets(value string) error {
+	if value != "" {
+		if _, err := models.ParseWeekdayTargets(value); err != nil {
+			return err
+		}
+	}
+	return db.setPreference("targets", value)
+}
+
+// GetHolidaysFile retrieves the path of the .ics holiday calendar, empty
+// when there is none
+func (db *DB) GetHolidaysFile() (string, error) {
+	value, _, err := db.getPreference("holidays_file")
+	return value, err
+}
+
+// SetHolidaysFile saves the path of the .ics holiday calendar, or "" for
+// none
+func (db *DB) SetHolidaysFile(path string) error {
+	return db.setPreference("holidays_file", path)
+}
+
+// GetAbsencePolicy retrieves the yearly allowance of an absence type and
+// the most unused days carried into the next year, both none by default
+func (db *DB) GetAbsencePolicy(absenceType string) (models.AbsencePolicy, error) {
+	var policy models.AbsencePolicy
+	var err error
+	for _, setting := range []struct {
+		key   string
+		value *float64
+	}{
+		{"absences." + absenceType + ".allowance", &policy.Allowance},
+		{"absences." + absenceType + ".carry_over", &policy.CarryOver},
+	} {
+		value, ok, getErr := db.getPreference(setting.key)
+		if getErr != nil && err == nil {
+			err = getErr
+		}
+		if v, convErr := strconv.ParseFloat(value, 64); ok && convErr == nil && v >= 0 && !math.IsInf(v, 0) {
+			*setting.value = v
+		}
+	}
+	return policy, err
+}
+
+// SetAbsencePolicy saves the yearly allowance of an absence type and the
+// most unused days carried into the next year
+func (db *DB) SetAbsencePolicy(absenceType string, policy models.AbsencePolicy) error {
+	if !slices.Contains(models.AbsenceTypes, absenceType) {
+		return fmt.Errorf("unknown absence type %q", absenceType)
+	}
+	for _, v := range []float64{policy.Allowance, policy.CarryOver} {
+		if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("absence allowance must be a finite number of days >= 0")
+		}
+	}
+	if err := db.setPreference("absences."+absenceType+".allowance", strconv.FormatFloat(policy.Allowance, 'f', -1, 64)); err != nil {
+		return err
+	}
+	return db.setPreference("absences."+absenceType+".carry_over", strconv.FormatFloat(policy.CarryOver, 'f', -1, 64))
+}
+
+// GetBudgetAlerts retrieves the percentages of a budget at which to
+// notify, models.DefaultBudgetAlerts by default
+func (db *DB) GetBudgetAlerts() ([]int, error) {
+	value, ok, err := db.getPreference("budget_alerts")
+	alerts, parseErr := models.ParseBudgetAlerts(value)
+	if err != nil || !ok || parseErr != nil {
+		return slices.Clone(models.DefaultBudgetAlerts), err
+	}
+	return alerts, nil
+}
+
+// SetBudgetAlerts saves the percentages of a budget at which to notify, none
+// turning the alerts off
+func (db *DB) SetBudgetAlerts(alerts []int) error {
+	value := models.FormatBudgetAlerts(alerts)
+	if _, err := models.ParseBudgetAlerts(value); err != nil {
+		return err
+	}
+	return db.setPreference("budget_alerts", value)
+}
+
+// GetDigest retrieves when digests are sent and where their reports are
+// written, models.DefaultDigestSettings by default
+func (db *DB) GetDigest() (models.DigestSettings, error) {
+	s := models.DefaultDigestSettings()
+	var err error
+	get := func(key string) (string, bool) {
+		value, ok, getErr := db.getPreference(key)
+		if getErr != nil && err == nil {
+			err = getErr
+		}
+		return value, ok
+	}
+	if value, ok := get("digest.enabled"); ok {
+		s.Enabled = value == "1"
+	}
+	if value, ok := get("digest.weekly"); ok {
+		s.Weekly = value == "1"
+	}
+	if value, _ := get("digest.time"); value != "" {
+		if _, parseErr := models.ParseTimeOfDay(value); parseErr == nil {
+			s.Time = value
+		}
+	}
+	s.ReportDir, _ = get("digest.report_dir")
+	if value, _ := get("digest.report_format"); slices.Contains(models.ReportFormats, value) {
+		s.ReportFormat = value
+	}
+	if value, _ := get("digest.time_zone"); value != "" {
+		if _, loadErr := models.LoadTimeZone(value); loadErr == nil {
+			s.TimeZone = value
+		}
+	}
+	return s, err
+}
+
+// SetDigest saves when digests are sent and where their reports are written
+func (db *DB) SetDigest(s models.DigestSettings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	flag := func(b bool) string {
+		if b {
+			return "1"
+		}
+		return "0"
+	}
+	for _, setting := range []struct{ key, value string }{
+		{"digest.enabled", flag(s.Enabled)},
+		{"digest.time", s.Time},
+		{"digest.weekly", flag(s.Weekly)},
+		{"digest.report_dir", s.ReportDir},
+		{"digest.report_format", s.ReportFormat},
+		{"digest.time_zone", s.TimeZone},
+	} {
+		if err := db.setPreference(setting.key, setting.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDigestSent retrieves when the last daily digest was sent, zero if never
+func (db *DB) GetDigestSent() (time.Time, error) {
+	value, ok, err := db.getPreference("digest.last_sent")
+	if err != nil || !ok {
+		return time.Time{}, err
+	}
+	sent, parseErr := time.Parse(time.RFC3339, value)
+	if parseErr != nil {
+		return time.Time{}, nil
+	}
+	return sent, nil
+}
+
+// SetDigestSent saves when the last daily digest was sent, so that it is
+// not sent again after a restart
+func (db *DB) SetDigestSent(sent time.Time) error {
+	return db.setPreference("digest.last_sent", sent.Format(time.RFC3339))
+}
+
+// GetSleepPolicy retrieves what to do with a running timer after a suspend
+// or screen lock, SleepPolicyAsk by default
+func (db *DB) GetSleepPolicy() (string, error) {
+	value, _, err := db.getPreference("sleep_policy")
+	if value != SleepPolicyPause && value != SleepPolicyIgnore {
+		value = SleepPolicyAsk
+	}
+	return value, err
+}
+
+// SetSleepPolicy saves what to do with a running timer after a suspend or
+// screen lock
+func (db *DB) SetSleepPolicy(policy string) error {
+	if policy != SleepPolicyPause && policy != SleepPolicyAsk && policy != SleepPolicyIgnore {
+		return fmt.Errorf("sleep policy must be %q, %q or %q", SleepPolicyPause, SleepPolicyAsk, SleepPolicyIgnore)
+	}
+	return db.setPreference("sleep_policy", policy)
+}
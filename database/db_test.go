@@ -1,6 +1,7 @@
 package database
 
 import (
+	"database/sql"
 	"math"
 	"os"
 	"slices"
@@ -313,3 +314,660 @@ func TestDB_GetProjectNames(t *testing.T) {
 		t.Fatalf("unexpected project names order: %v", projectNames)
 	}
 }
+
+func TestDB_SaveTaskAssignsIDAndGetTask(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	task := models.NewTask("Project 1", "Description 1")
+	task.StopTask()
+	if err := db.SaveTask(task); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+	if task.ID == 0 {
+		t.Fatal("expected SaveTask to assign an ID")
+	}
+
+	loaded, err := db.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if loaded.ProjectName != "Project 1" || loaded.Description != "Description 1" {
+		t.Errorf("unexpected task loaded: %+v", loaded)
+	}
+
+	if _, err := db.GetTask(task.ID + 1); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for a missing task, got %v", err)
+	}
+}
+
+func TestDB_APIPreferences(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	enabled, err := db.GetAPIEnabled()
+	if err != nil || enabled {
+		t.Fatalf("expected API disabled by default, got %v (err %v)", enabled, err)
+	}
+	if stored, err := db.HasPreference("api_enabled"); err != nil || stored {
+		t.Fatalf("expected api_enabled not stored by default, got %v (err %v)", stored, err)
+	}
+	port, err := db.GetAPIPort()
+	if err != nil || port != DefaultAPIPort {
+		t.Fatalf("expected default port %d, got %d (err %v)", DefaultAPIPort, port, err)
+	}
+	token, err := db.GetAPIToken()
+	if err != nil || token != "" {
+		t.Fatalf("expected no token by default, got %q (err %v)", token, err)
+	}
+
+	if err := db.SetAPIEnabled(true); err != nil {
+		t.Fatalf("failed to enable API: %v", err)
+	}
+	if err := db.SetAPIPort(9000); err != nil {
+		t.Fatalf("failed to set port: %v", err)
+	}
+	if err := db.SetAPIToken("abc"); err != nil {
+		t.Fatalf("failed to set token: %v", err)
+	}
+
+	if stored, _ := db.HasPreference("api_enabled"); !stored {
+		t.Error("expected api_enabled to be stored after SetAPIEnabled")
+	}
+
+	enabled, _ = db.GetAPIEnabled()
+	port, _ = db.GetAPIPort()
+	token, _ = db.GetAPIToken()
+	if !enabled || port != 9000 || token != "abc" {
+		t.Errorf("unexpected API preferences: enabled=%v port=%d token=%q", enabled, port, token)
+	}
+
+	if err := db.SetAPIPort(0); err == nil {
+		t.Error("expected error for port 0")
+	}
+	if err := db.SetAPIPort(70000); err == nil {
+		t.Error("expected error for port 70000")
+	}
+	if err := db.SetAPIToken(""); err == nil {
+		t.Error("expected error for empty token")
+	}
+
+	if _, err := db.Exec("INSERT OR REPLACE INTO preferences (key, value) VALUES ('api_port', 'invalid')"); err != nil {
+		t.Fatalf("failed to insert invalid port: %v", err)
+	}
+	if port, _ = db.GetAPIPort(); port != DefaultAPIPort {
+		t.Errorf("expected default port for invalid DB value, got %d", port)
+	}
+}
+
+func TestDB_TaskSegments(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	task := &models.Task{ProjectName: "Paused", Description: "with a break"}
+	if err := task.SetSegments([]models.Segment{
+		{Start: base, End: base.Add(time.Hour)},
+		{Start: base.Add(90 * time.Minute), End: base.Add(2 * time.Hour)},
+	}); err != nil {
+		t.Fatalf("failed to set segments: %v", err)
+	}
+	if err := db.SaveTask(task); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+	plain := &models.Task{ProjectName: "Plain", StartTime: base, EndTime: base.Add(time.Hour), Duration: time.Hour}
+	if err := db.SaveTask(plain); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+
+	got, err := db.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if len(got.Segments) != 2 || !got.Segments[1].Start.Equal(base.Add(90*time.Minute)) || got.Duration != 90*time.Minute {
+		t.Fatalf("expected two segments totalling 90m, got %+v", got)
+	}
+
+	tasks, err := db.GetTasks()
+	if err != nil {
+		t.Fatalf("failed to get tasks: %v", err)
+	}
+	for _, loaded := range tasks {
+		want := 0
+		if loaded.ID == task.ID {
+			want = 2
+		}
+		if len(loaded.Segments) != want {
+			t.Errorf("task %d: expected %d segments, got %d", loaded.ID, want, len(loaded.Segments))
+		}
+	}
+
+	// Fixing the segments down to one interval drops the stored segments.
+	if err := got.SetSegments([]models.Segment{{Start: base, End: base.Add(2 * time.Hour)}}); err != nil {
+		t.Fatalf("failed to set segments: %v", err)
+	}
+	if err := db.UpdateTask(got); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if got, _ = db.GetTask(task.ID); len(got.Segments) != 0 || got.Duration != 2*time.Hour {
+		t.Errorf("expected a plain 2h task after update, got %+v", got)
+	}
+
+	if err := task.SetSegments(task.Segments); err != nil {
+		t.Fatalf("failed to reset segments: %v", err)
+	}
+	if err := db.UpdateTask(task); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if err := db.DeleteTask(task.ID); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM task_segments").Scan(&count); err != nil || count != 0 {
+		t.Errorf("expected segments deleted with the task, got %d (err %v)", count, err)
+	}
+}
+
+func TestDB_ForgottenTimerPreferences(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	hours, err := db.GetMaxTimerHours()
+	if err != nil || hours != DefaultMaxTimerHours {
+		t.Fatalf("expected default max timer length %v, got %v (err %v)", DefaultMaxTimerHours, hours, err)
+	}
+	endOfDay, err := db.GetEndOfDay()
+	if err != nil || endOfDay != "" {
+		t.Fatalf("expected no end of day by default, got %q (err %v)", endOfDay, err)
+	}
+
+	if err := db.SetMaxTimerHours(0); err != nil {
+		t.Fatalf("failed to disable max timer length: %v", err)
+	}
+	if err := db.SetEndOfDay("18:30"); err != nil {
+		t.Fatalf("failed to set end of day: %v", err)
+	}
+	hours, _ = db.GetMaxTimerHours()
+	endOfDay, _ = db.GetEndOfDay()
+	if hours != 0 || endOfDay != "18:30" {
+		t.Errorf("unexpected preferences: max=%v end=%q", hours, endOfDay)
+	}
+
+	if err := db.SetMaxTimerHours(-1); err == nil {
+		t.Error("expected error for negative max timer length")
+	}
+	if err := db.SetEndOfDay("6pm"); err == nil {
+		t.Error("expected error for malformed end of day")
+	}
+}
+
+func TestDB_MigratesPomodoros(t *testing.T) {
+	dbPath := "test_migrate.db"
+	defer os.Remove(dbPath)
+
+	// A database created before pomodoros were tracked.
+	old, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	_, err = old.Exec(`CREATE TABLE tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		project_name TEXT NOT NULL,
+		description TEXT,
+		start_time DATETIME NOT NULL,
+		end_time DATETIME NOT NULL,
+		duration INTEGER NOT NULL
+	)`)
+	if err != nil {
+		t.Fatalf("failed to create old schema: %v", err)
+	}
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	if _, err := old.Exec(`INSERT INTO tasks (project_name, description, start_time, end_time, duration) VALUES ('Old', '', ?, ?, ?)`,
+		start, start.Add(time.Hour), time.Hour.Nanoseconds()); err != nil {
+		t.Fatalf("failed to insert old task: %v", err)
+	}
+	old.Close()
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen db: %v", err)
+	}
+	defer db.Close()
+	for range 2 {
+		if err := db.InitDB(); err != nil {
+			t.Fatalf("failed to migrate: %v", err)
+		}
+	}
+
+	tasks, err := db.GetTasks()
+	if err != nil || len(tasks) != 1 || tasks[0].Pomodoros != 0 || tasks[0].Estimate != 0 {
+		t.Fatalf("expected the old task with no pomodoros or estimate, got %+v (err %v)", tasks, err)
+	}
+
+	task := &models.Task{ProjectName: "Focus", StartTime: start, EndTime: start.Add(time.Hour), Duration: time.Hour, Pomodoros: 2}
+	if err := db.SaveTask(task); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+	task.Pomodoros = 3
+	if err := db.UpdateTask(task); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	got, err := db.GetTask(task.ID)
+	if err != nil || got.Pomodoros != 3 {
+		t.Fatalf("expected 3 pomodoros, got %+v (err %v)", got, err)
+	}
+}
+
+func TestDB_TaskEstimate(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	task := &models.Task{ProjectName: "Focus", StartTime: start, EndTime: start.Add(time.Hour), Duration: time.Hour, Estimate: 45 * time.Minute}
+	if err := db.SaveTask(task); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+	got, err := db.GetTask(task.ID)
+	if err != nil || got.Estimate != 45*time.Minute {
+		t.Fatalf("expected a 45m estimate, got %+v (err %v)", got, err)
+	}
+	task.Estimate = 0
+	if err := db.UpdateTask(task); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if got, err = db.GetTask(task.ID); err != nil || got.Estimate != 0 {
+		t.Fatalf("expected the estimate cleared, got %+v (err %v)", got, err)
+	}
+}
+
+func TestDB_PomodoroPreferences(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	enabled, err := db.GetPomodoroEnabled()
+	if err != nil || enabled {
+		t.Fatalf("expected Pomodoro mode off by default, got %v (err %v)", enabled, err)
+	}
+	work, shortBreak, longBreak, err := db.GetPomodoroLengths()
+	if err != nil || work != DefaultPomodoroWork || shortBreak != DefaultPomodoroShortBreak || longBreak != DefaultPomodoroLongBreak {
+		t.Fatalf("unexpected default lengths %d/%d/%d (err %v)", work, shortBreak, longBreak, err)
+	}
+	if breaks, err := db.GetPomodoroBreaks(); err != nil || breaks != PomodoroBreaksGap {
+		t.Fatalf("expected gap breaks by default, got %q (err %v)", breaks, err)
+	}
+
+	if err := db.SetPomodoroEnabled(true); err != nil {
+		t.Fatalf("failed to enable Pomodoro mode: %v", err)
+	}
+	if err := db.SetPomodoroLengths(50, 10, 30); err != nil {
+		t.Fatalf("failed to set lengths: %v", err)
+	}
+	if err := db.SetPomodoroBreaks(PomodoroBreaksProject); err != nil {
+		t.Fatalf("failed to set breaks: %v", err)
+	}
+	enabled, _ = db.GetPomodoroEnabled()
+	work, shortBreak, longBreak, _ = db.GetPomodoroLengths()
+	breaks, _ := db.GetPomodoroBreaks()
+	if !enabled || work != 50 || shortBreak != 10 || longBreak != 30 || breaks != PomodoroBreaksProject {
+		t.Errorf("unexpected preferences: %v %d/%d/%d %q", enabled, work, shortBreak, longBreak, breaks)
+	}
+
+	if err := db.SetPomodoroLengths(0, 5, 15); err == nil {
+		t.Error("expected error for a zero work length")
+	}
+	if err := db.SetPomodoroBreaks("skip"); err == nil {
+		t.Error("expected error for an unknown break mode")
+	}
+}
+
+func TestDB_SwitchTasks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if enabled, err := db.GetSwitchTasks(); err != nil || !enabled {
+		t.Fatalf("expected switching on by default, got %v (err %v)", enabled, err)
+	}
+	if err := db.SetSwitchTasks(false); err != nil {
+		t.Fatalf("failed to turn switching off: %v", err)
+	}
+	if enabled, _ := db.GetSwitchTasks(); enabled {
+		t.Error("expected switching off once saved")
+	}
+}
+
+func TestDB_Rounding(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	minutes, mode, per, err := db.GetRounding()
+	if err != nil || minutes != 0 || mode != models.RoundNearest || per != models.RoundPerEntry {
+		t.Fatalf("expected rounding off by default, got %d %q %q (err %v)", minutes, mode, per, err)
+	}
+	if err := db.SetRounding(6, models.RoundUp, models.RoundPerDay); err != nil {
+		t.Fatalf("failed to set rounding: %v", err)
+	}
+	minutes, mode, per, _ = db.GetRounding()
+	if minutes != 6 || mode != models.RoundUp || per != models.RoundPerDay {
+		t.Errorf("unexpected rounding %d %q %q", minutes, mode, per)
+	}
+	if err := db.SetRounding(-1, models.RoundUp, models.RoundPerDay); err == nil {
+		t.Error("expected error for a negative increment")
+	}
+	if err := db.SetRounding(15, "sideways", models.RoundPerDay); err == nil {
+		t.Error("expected error for an unknown mode")
+	}
+	if err := db.SetRounding(15, models.RoundUp, "week"); err == nil {
+		t.Error("expected error for an unknown scope")
+	}
+}
+
+func TestDB_RoundingRules(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	client := models.ScopedRoundingRule{Scope: models.RoundingForClient, Name: "Acme Corp",
+		RoundingRule: models.RoundingRule{Increment: 6 * time.Minute, Mode: models.RoundUp, Per: models.RoundPerEntry}}
+	project := models.ScopedRoundingRule{Scope: models.RoundingForProject, Name: "Acme Corp",
+		RoundingRule: models.RoundingRule{Increment: 15 * time.Minute, Mode: models.RoundNearest, Per: models.RoundPerDay}}
+	for _, r := range []models.ScopedRoundingRule{project, client} {
+		if err := db.SetRoundingRule(r); err != nil {
+			t.Fatalf("failed to set rounding rule: %v", err)
+		}
+	}
+	client.Mode = models.RoundDown
+	if err := db.SetRoundingRule(client); err != nil {
+		t.Fatalf("failed to replace rounding rule: %v", err)
+	}
+	for _, r := range []models.ScopedRoundingRule{
+		{Scope: "team", Name: "Acme", RoundingRule: client.RoundingRule},
+		{Scope: models.RoundingForProject, Name: " ", RoundingRule: client.RoundingRule},
+		{Scope: models.RoundingForProject, Name: "Beta", RoundingRule: models.RoundingRule{Increment: 15 * time.Minute, Mode: "sideways", Per: models.RoundPerEntry}},
+	} {
+		if err := db.SetRoundingRule(r); err == nil {
+			t.Errorf("expected error for %+v", r)
+		}
+	}
+	rules, err := db.GetRoundingRules()
+	if want := []models.ScopedRoundingRule{client, project}; err != nil || !slices.Equal(rules, want) {
+		t.Fatalf("expected %+v, got %+v (err %v)", want, rules, err)
+	}
+	if err := db.DeleteRoundingRule(models.RoundingForClient, "Acme Corp"); err != nil {
+		t.Fatalf("failed to delete rounding rule: %v", err)
+	}
+	if rules, _ := db.GetRoundingRules(); len(rules) != 1 || rules[0].Scope != models.RoundingForProject {
+		t.Errorf("expected only the project's rule left, got %+v", rules)
+	}
+
+	if err := db.SetProjectClient("Website", "Acme Corp"); err != nil {
+		t.Fatalf("failed to set project client: %v", err)
+	}
+	if err := db.SetProjectClient("App", "Beta Ltd"); err != nil {
+		t.Fatalf("failed to set project client: %v", err)
+	}
+	if err := db.SetProjectClient("App", ""); err != nil {
+		t.Fatalf("failed to remove project client: %v", err)
+	}
+	clients, err := db.GetProjectClients()
+	if err != nil || len(clients) != 1 || clients["Website"] != "Acme Corp" {
+		t.Errorf("expected only Website billed to Acme Corp, got %v (err %v)", clients, err)
+	}
+}
+
+func TestDB_WeekStart(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if day, err := db.GetWeekStart(); err != nil || day != time.Monday {
+		t.Fatalf("expected Monday by default, got %v (err %v)", day, err)
+	}
+	if err := db.SetWeekStart(time.Sunday); err != nil {
+		t.Fatalf("failed to set week start: %v", err)
+	}
+	if day, _ := db.GetWeekStart(); day != time.Sunday {
+		t.Errorf("expected Sunday, got %v", day)
+	}
+	if err := db.SetWeekStart(time.Weekday(7)); err == nil {
+		t.Error("expected error for an invalid weekday")
+	}
+}
+
+func TestDB_SleepPolicy(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if policy, err := db.GetSleepPolicy(); err != nil || policy != SleepPolicyAsk {
+		t.Fatalf("expected to ask by default, got %q (err %v)", policy, err)
+	}
+	if err := db.SetSleepPolicy(SleepPolicyPause); err != nil {
+		t.Fatalf("failed to set sleep policy: %v", err)
+	}
+	if policy, _ := db.GetSleepPolicy(); policy != SleepPolicyPause {
+		t.Errorf("expected %q, got %q", SleepPolicyPause, policy)
+	}
+	if err := db.SetSleepPolicy("hibernate"); err == nil {
+		t.Error("expected error for an unknown sleep policy")
+	}
+}
+
+func TestDB_Flex(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if start, opening, err := db.GetFlex(); err != nil || start != "" || opening != 0 {
+		t.Fatalf("expected no flex-time balance by default, got %q, %v (err %v)", start, opening, err)
+	}
+	if err := db.SetFlex("2024-08-12", -2.5); err != nil {
+		t.Fatalf("failed to set flex-time balance: %v", err)
+	}
+	if start, opening, _ := db.GetFlex(); start != "2024-08-12" || opening != -2.5 {
+		t.Errorf("expected 2024-08-12 with -2.5h, got %q with %v", start, opening)
+	}
+	if err := db.SetFlex("12/08/2024", 0); err == nil {
+		t.Error("expected error for a malformed start date")
+	}
+
+	paid := models.FlexCorrection{
+		Date:   time.Date(2024, 8, 16, 0, 0, 0, 0, time.Local),
+		Amount: -3 * time.Hour,
+		Note:   "paid out",
+	}
+	if err := db.AddFlexCorrection(&paid); err != nil || paid.ID == 0 {
+		t.Fatalf("failed to add correction: %v (id %d)", err, paid.ID)
+	}
+	earlier := models.FlexCorrection{Date: paid.Date.AddDate(0, 0, -1), Amount: time.Hour}
+	if err := db.AddFlexCorrection(&earlier); err != nil {
+		t.Fatalf("failed to add correction: %v", err)
+	}
+	corrections, err := db.GetFlexCorrections()
+	if err != nil || len(corrections) != 2 {
+		t.Fatalf("expected 2 corrections, got %d (err %v)", len(corrections), err)
+	}
+	if c := corrections[1]; c.ID != paid.ID || !c.Date.Equal(paid.Date) || c.Amount != paid.Amount || c.Note != paid.Note {
+		t.Errorf("expected %+v last, got %+v", paid, c)
+	}
+	if err := db.DeleteFlexCorrection(paid.ID); err != nil {
+		t.Fatalf("failed to delete correction: %v", err)
+	}
+	if corrections, _ := db.GetFlexCorrections(); len(corrections) != 1 || corrections[0].ID != earlier.ID {
+		t.Errorf("expected only the earlier correction left, got %+v", corrections)
+	}
+}
+
+func TestDB_WorkSchedule(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if targets, err := db.GetWeekdayTargets(); err != nil || targets != "" {
+		t.Fatalf("expected no targets by default, got %q (err %v)", targets, err)
+	}
+	if err := db.SetWeekdayTargets("8 8 8 8 6 0 0"); err != nil {
+		t.Fatalf("failed to set targets: %v", err)
+	}
+	if targets, _ := db.GetWeekdayTargets(); targets != "8 8 8 8 6 0 0" {
+		t.Errorf("expected the saved targets, got %q", targets)
+	}
+	if err := db.SetWeekdayTargets("8 8 8"); err == nil {
+		t.Error("expected error for too few targets")
+	}
+	if err := db.SetHolidaysFile("/tmp/holidays.ics"); err != nil {
+		t.Fatalf("failed to set holidays file: %v", err)
+	}
+	if path, _ := db.GetHolidaysFile(); path != "/tmp/holidays.ics" {
+		t.Errorf("expected the saved holidays file, got %q", path)
+	}
+
+	christmas := models.Holiday{Date: time.Date(2024, 12, 25, 0, 0, 0, 0, time.Local), Name: "Christmas Day", Yearly: true}
+	if err := db.AddHoliday(&christmas); err != nil || christmas.ID == 0 {
+		t.Fatalf("failed to add holiday: %v (id %d)", err, christmas.ID)
+	}
+	bridge := models.Holiday{Date: time.Date(2024, 8, 16, 0, 0, 0, 0, time.Local), Name: "Bridge day"}
+	if err := db.AddHoliday(&bridge); err != nil {
+		t.Fatalf("failed to add holiday: %v", err)
+	}
+	holidays, err := db.GetHolidays()
+	if err != nil || len(holidays) != 2 {
+		t.Fatalf("expected 2 holidays, got %d (err %v)", len(holidays), err)
+	}
+	if h := holidays[1]; h.ID != christmas.ID || !h.Date.Equal(christmas.Date) || h.Name != christmas.Name || !h.Yearly {
+		t.Errorf("expected %+v last, got %+v", christmas, h)
+	}
+	if err := db.DeleteHoliday(bridge.ID); err != nil {
+		t.Fatalf("failed to delete holiday: %v", err)
+	}
+	if holidays, _ := db.GetHolidays(); len(holidays) != 1 || holidays[0].ID != christmas.ID {
+		t.Errorf("expected only Christmas left, got %+v", holidays)
+	}
+}
+
+func TestDB_Absences(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if policy, err := db.GetAbsencePolicy(models.AbsenceVacation); err != nil || policy != (models.AbsencePolicy{}) {
+		t.Fatalf("expected no allowance by default, got %+v (err %v)", policy, err)
+	}
+	want := models.AbsencePolicy{Allowance: 30, CarryOver: 5}
+	if err := db.SetAbsencePolicy(models.AbsenceVacation, want); err != nil {
+		t.Fatalf("failed to set allowance: %v", err)
+	}
+	if policy, _ := db.GetAbsencePolicy(models.AbsenceVacation); policy != want {
+		t.Errorf("expected %+v, got %+v", want, policy)
+	}
+	if err := db.SetAbsencePolicy("sabbatical", want); err == nil {
+		t.Error("expected error for an unknown absence type")
+	}
+	if err := db.SetAbsencePolicy(models.AbsenceSick, models.AbsencePolicy{Allowance: -1}); err == nil {
+		t.Error("expected error for a negative allowance")
+	}
+
+	sick := models.Absence{Date: time.Date(2024, 8, 13, 0, 0, 0, 0, time.Local), Type: models.AbsenceSick, Half: true, Note: "dentist"}
+	if err := db.AddAbsence(&sick); err != nil || sick.ID == 0 {
+		t.Fatalf("failed to add absence: %v (id %d)", err, sick.ID)
+	}
+	vacation := models.Absence{Date: sick.Date.AddDate(0, 0, -1), Type: models.AbsenceVacation}
+	if err := db.AddAbsence(&vacation); err != nil {
+		t.Fatalf("failed to add absence: %v", err)
+	}
+	if err := db.AddAbsence(&models.Absence{Date: sick.Date, Type: "sabbatical"}); err == nil {
+		t.Error("expected error for an unknown absence type")
+	}
+	absences, err := db.GetAbsences()
+	if err != nil || len(absences) != 2 {
+		t.Fatalf("expected 2 absences, got %d (err %v)", len(absences), err)
+	}
+	if a := absences[1]; a.ID != sick.ID || !a.Date.Equal(sick.Date) || a.Date.Day() != 13 || a.Type != sick.Type || !a.Half || a.Note != sick.Note {
+		t.Errorf("expected %+v last, got %+v", sick, a)
+	}
+	if err := db.DeleteAbsence(vacation.ID); err != nil {
+		t.Fatalf("failed to delete absence: %v", err)
+	}
+	if absences, _ := db.GetAbsences(); len(absences) != 1 || absences[0].ID != sick.ID {
+		t.Errorf("expected only the sick day left, got %+v", absences)
+	}
+}
+
+func TestDB_Budgets(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if alerts, err := db.GetBudgetAlerts(); err != nil || !slices.Equal(alerts, []int{80, 100}) {
+		t.Fatalf("expected alerts at 80%% and 100%% by default, got %v (err %v)", alerts, err)
+	}
+	if err := db.SetBudgetAlerts([]int{50, 90}); err != nil {
+		t.Fatalf("failed to set alerts: %v", err)
+	}
+	if alerts, _ := db.GetBudgetAlerts(); !slices.Equal(alerts, []int{50, 90}) {
+		t.Errorf("expected alerts at 50%% and 90%%, got %v", alerts)
+	}
+	if err := db.SetBudgetAlerts(nil); err != nil {
+		t.Fatalf("failed to turn alerts off: %v", err)
+	}
+	if alerts, _ := db.GetBudgetAlerts(); len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %v", alerts)
+	}
+	if err := db.SetBudgetAlerts([]int{0}); err == nil {
+		t.Error("expected error for an alert at 0%")
+	}
+
+	acme := models.Budget{ProjectName: "Acme", Limit: 40 * time.Hour, Period: models.BudgetTotal}
+	if err := db.SetBudget(acme); err != nil {
+		t.Fatalf("failed to set budget: %v", err)
+	}
+	if err := db.SetBudget(models.Budget{ProjectName: "Beta", Limit: 90 * time.Minute, Period: models.BudgetWeekly}); err != nil {
+		t.Fatalf("failed to set budget: %v", err)
+	}
+	acme.Limit, acme.Period = 60*time.Hour, models.BudgetMonthly
+	if err := db.SetBudget(acme); err != nil {
+		t.Fatalf("failed to replace budget: %v", err)
+	}
+	if err := db.SetBudget(models.Budget{ProjectName: "Gamma", Period: models.BudgetTotal}); err == nil {
+		t.Error("expected error for a budget without hours")
+	}
+	budgets, err := db.GetBudgets()
+	want := []models.Budget{acme, {ProjectName: "Beta", Limit: 90 * time.Minute, Period: models.BudgetWeekly}}
+	if err != nil || !slices.Equal(budgets, want) {
+		t.Fatalf("expected %+v, got %+v (err %v)", want, budgets, err)
+	}
+	if err := db.DeleteBudget("Beta"); err != nil {
+		t.Fatalf("failed to delete budget: %v", err)
+	}
+	if budgets, _ := db.GetBudgets(); len(budgets) != 1 || budgets[0].ProjectName != "Acme" {
+		t.Errorf("expected only Acme's budget left, got %+v", budgets)
+	}
+}
+
+func TestDB_Digest(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if s, err := db.GetDigest(); err != nil || s != models.DefaultDigestSettings() {
+		t.Fatalf("expected the default digest settings, got %+v (err %v)", s, err)
+	}
+	want := models.DigestSettings{
+		Enabled:      true,
+		Time:         "17:30",
+		ReportDir:    "/tmp/reports",
+		ReportFormat: models.ReportHTML,
+		TimeZone:     "Europe/Berlin",
+	}
+	if err := db.SetDigest(want); err != nil {
+		t.Fatalf("failed to set digest settings: %v", err)
+	}
+	if s, err := db.GetDigest(); err != nil || s != want {
+		t.Errorf("expected %+v, got %+v (err %v)", want, s, err)
+	}
+	if err := db.SetDigest(models.DigestSettings{ReportFormat: "pdf"}); err == nil {
+		t.Error("expected error for an unknown report format")
+	}
+
+	if sent, err := db.GetDigestSent(); err != nil || !sent.IsZero() {
+		t.Errorf("expected no digest sent yet, got %v (err %v)", sent, err)
+	}
+	sent := time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)
+	if err := db.SetDigestSent(sent); err != nil {
+		t.Fatalf("failed to save the digest time: %v", err)
+	}
+	if got, _ := db.GetDigestSent(); !got.Equal(sent) {
+		t.Errorf("expected %v, got %v", sent, got)
+	}
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"time"
+	"trackyou/models"
+)
+
+// AddFlexCorrection saves a correction to the flex-time balance and stores
+// the generated ID on it
+func (db *DB) AddFlexCorrection(c *models.FlexCorrection) error {
+	result, err := db.Exec(`INSERT INTO flex_corrections (date, amount, note) VALUES (?, ?, ?)`,
+		c.Date, int64(c.Amount), c.Note)
+	if err != nil {
+		return err
+	}
+	c.ID, err = result.LastInsertId()
+	return err
+}
+
+// GetFlexCorrections retrieves all corrections to the flex-time balance,
+// oldest first
+func (db *DB) GetFlexCorrections() ([]models.FlexCorrection, error) {
+	rows, err := db.Query(`SELECT id, date, amount, note FROM flex_corrections ORDER BY date, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var corrections []models.FlexCorrection
+	for rows.Next() {
+		var c models.FlexCorrection
+		var amount int64
+		if err := rows.Scan(&c.ID, &c.Date, &amount, &c.Note); err != nil {
+			return nil, err
+		}
+		c.Amount = time.Duration(amount)
+		corrections = append(corrections, c)
+	}
+	return corrections, rows.Err()
+}
+
+// DeleteFlexCorrection removes a correction to the flex-time balance
+func (db *DB) DeleteFlexCorrection(id int64) error {
+	_, err := db.Exec(`DELETE FROM flex_corrections WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"trackyou/models"
+)
+
+// AddHoliday saves a holiday entered by hand and stores the generated ID on
+// it
+func (db *DB) AddHoliday(h *models.Holiday) error {
+	result, err := db.Exec(`INSERT INTO holidays (date, name, yearly) VALUES (?, ?, ?)`,
+		h.Date, h.Name, h.Yearly)
+	if err != nil {
+		return err
+	}
+	h.ID, err = result.LastInsertId()
+	return err
+}
+
+// GetHolidays retrieves the holidays entered by hand, earliest first
+func (db *DB) GetHolidays() ([]models.Holiday, error) {
+	rows, err := db.Query(`SELECT id, date, name, yearly FROM holidays ORDER BY date, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holidays []models.Holiday
+	for rows.Next() {
+		var h models.Holiday
+		if err := rows.Scan(&h.ID, &h.Date, &h.Name, &h.Yearly); err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, h)
+	}
+	return holidays, rows.Err()
+}
+
+// DeleteHoliday removes a holiday entered by hand
+func (db *DB) DeleteHoliday(id int64) error {
+	_, err := db.Exec(`DELETE FROM holidays WHERE id = ?`, id)
+	return err
+}
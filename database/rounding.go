@@ -0,0 +1,86 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"trackyou/models"
+)
+
+// SetRoundingRule saves the rounding rule of a project or client, replacing
+// any it had
+func (db *DB) SetRoundingRule(r models.ScopedRoundingRule) error {
+	if r.Scope != models.RoundingForProject && r.Scope != models.RoundingForClient {
+		return fmt.Errorf("rounding rules must be for a %q or a %q", models.RoundingForProject, models.RoundingForClient)
+	}
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("rounding rules need the name of their %s", r.Scope)
+	}
+	minutes := int(r.Increment / time.Minute)
+	if err := validateRounding(minutes, r.Mode, r.Per); err != nil {
+		return err
+	}
+	_, err := db.Exec(`INSERT OR REPLACE INTO rounding_rules (scope, name, minutes, mode, apply) VALUES (?, ?, ?, ?, ?)`,
+		r.Scope, r.Name, minutes, r.Mode, r.Per)
+	return err
+}
+
+// GetRoundingRules retrieves the rounding rules of all clients and projects,
+// by scope and name
+func (db *DB) GetRoundingRules() ([]models.ScopedRoundingRule, error) {
+	rows, err := db.Query(`SELECT scope, name, minutes, mode, apply FROM rounding_rules ORDER BY scope, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []models.ScopedRoundingRule
+	for rows.Next() {
+		var r models.ScopedRoundingRule
+		var minutes int
+		if err := rows.Scan(&r.Scope, &r.Name, &minutes, &r.Mode, &r.Per); err != nil {
+			return nil, err
+		}
+		r.Increment = time.Duration(minutes) * time.Minute
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// DeleteRoundingRule removes the rounding rule of a project or client
+func (db *DB) DeleteRoundingRule(scope, name string) error {
+	_, err := db.Exec(`DELETE FROM rounding_rules WHERE scope = ? AND name = ?`, scope, name)
+	return err
+}
+
+// SetProjectClient records the client a project is billed to, an empty
+// client removing it
+func (db *DB) SetProjectClient(projectName, client string) error {
+	if strings.TrimSpace(client) == "" {
+		_, err := db.Exec(`DELETE FROM project_clients WHERE project_name = ?`, projectName)
+		return err
+	}
+	_, err := db.Exec(`INSERT OR REPLACE INTO project_clients (project_name, client) VALUES (?, ?)`, projectName, client)
+	return err
+}
+
+// GetProjectClients retrieves the client of each project that has one, by
+// project name
+func (db *DB) GetProjectClients() (map[string]string, error) {
+	rows, err := db.Query(`SELECT project_name, client FROM project_clients`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := make(map[string]string)
+	for rows.Next() {
+		var project, client string
+		if err := rows.Scan(&project, &client); err != nil {
+			return nil, err
+		}
+		clients[project] = client
+	}
+	return clients, rows.Err()
+}
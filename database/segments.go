@@ -0,0 +1,57 @@
+package database
+
+import (
+	"database/sql"
+	"trackyou/models"
+)
+
+// replaceSegments stores segments as the only segments of taskID. Tasks that
+// were never paused have none.
+func replaceSegments(tx *sql.Tx, taskID int64, segments []models.Segment) error {
+	if _, err := tx.Exec(`DELETE FROM task_segments WHERE task_id = ?`, taskID); err != nil {
+		return err
+	}
+	for _, seg := range segments {
+		_, err := tx.Exec(`INSERT INTO task_segments (task_id, start_time, end_time) VALUES (?, ?, ?)`,
+			taskID, seg.Start, seg.End)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadSegments attaches the stored segments to tasks, in start order.
+func (db *DB) loadSegments(tasks []*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*models.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	query := `SELECT task_id, start_time, end_time FROM task_segments`
+	var args []any
+	if len(tasks) == 1 {
+		query += ` WHERE task_id = ?`
+		args = append(args, tasks[0].ID)
+	}
+	rows, err := db.Query(query+` ORDER BY task_id, start_time, id`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int64
+		var seg models.Segment
+		if err := rows.Scan(&taskID, &seg.Start, &seg.End); err != nil {
+			return err
+		}
+		if task, ok := byID[taskID]; ok {
+			task.Segments = append(task.Segments, seg)
+		}
+	}
+	return rows.Err()
+}
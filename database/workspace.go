@@ -0,0 +1,118 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultWorkspace is the workspace stored at the original tasks.db location.
+const DefaultWorkspace = "default"
+
+const (
+	dbFileName          = "tasks.db"
+	workspacesDirName   = "workspaces"
+	activeWorkspaceFile = "workspace"
+)
+
+var workspaceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.-]{0,63}$`)
+
+// GetConfigDir returns <UserConfigDir>/TrackYou, creating it if needed.
+func GetConfigDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	appDir := filepath.Join(configDir, "TrackYou")
+	if err := os.MkdirAll(appDir, 0700); err != nil {
+		return "", err
+	}
+	return appDir, nil
+}
+
+// ValidateWorkspaceName rejects names that are not safe directory names.
+func ValidateWorkspaceName(name string) error {
+	if !workspaceNamePattern.MatchString(name) || strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return fmt.Errorf("invalid workspace name %q: use letters, digits, spaces, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+// GetWorkspaceDBPath returns the database path for a named workspace and
+// ensures its directory exists. Each workspace has its own database and
+// therefore its own preferences.
+func GetWorkspaceDBPath(name string) (string, error) {
+	if err := ValidateWorkspaceName(name); err != nil {
+		return "", err
+	}
+	appDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultWorkspace {
+		return filepath.Join(appDir, dbFileName), nil
+	}
+	dir := filepath.Join(appDir, workspacesDirName, name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, dbFileName), nil
+}
+
+// ListWorkspaces returns the default workspace followed by every named
+// workspace, sorted by name.
+func ListWorkspaces() ([]string, error) {
+	appDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(appDir, workspacesDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && ValidateWorkspaceName(entry.Name()) == nil && entry.Name() != DefaultWorkspace {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultWorkspace}, names...), nil
+}
+
+// GetActiveWorkspace returns the workspace last selected in the GUI, or the
+// default workspace when none has been chosen.
+func GetActiveWorkspace() (string, error) {
+	appDir, err := GetConfigDir()
+	if err != nil {
+		return DefaultWorkspace, err
+	}
+	data, err := os.ReadFile(filepath.Join(appDir, activeWorkspaceFile))
+	if os.IsNotExist(err) {
+		return DefaultWorkspace, nil
+	}
+	if err != nil {
+		return DefaultWorkspace, err
+	}
+	name := strings.TrimSpace(string(data))
+	if ValidateWorkspaceName(name) != nil {
+		return DefaultWorkspace, nil
+	}
+	return name, nil
+}
+
+// SetActiveWorkspace remembers the workspace to open on the next launch.
+func SetActiveWorkspace(name string) error {
+	if err := ValidateWorkspaceName(name); err != nil {
+		return err
+	}
+	appDir, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(appDir, activeWorkspaceFile), []byte(name+"\n"), 0600)
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func setupConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+	configDir, err := GetConfigDir()
+	if err != nil {
+		t.Fatalf("failed to get config dir: %v", err)
+	}
+	return configDir
+}
+
+func TestValidateWorkspaceName(t *testing.T) {
+	for _, name := range []string{"default", "work", "Client A", "acme-2024", "side_project.v2"} {
+		if err := ValidateWorkspaceName(name); err != nil {
+			t.Errorf("expected %q to be valid, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", ".", "..", "../escape", "a/b", `a\\b`, " lead", "trail.", "trail "} {
+		if err := ValidateWorkspaceName(name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestGetWorkspaceDBPath(t *testing.T) {
+	configDir := setupConfigDir(t)
+
+	defaultPath, err := GetDefaultDBPath()
+	if err != nil {
+		t.Fatalf("failed to get default path: %v", err)
+	}
+	if want := filepath.Join(configDir, "tasks.db"); defaultPath != want {
+		t.Errorf("expected default workspace at %s, got %s", want, defaultPath)
+	}
+
+	workPath, err := GetWorkspaceDBPath("work")
+	if err != nil {
+		t.Fatalf("failed to get workspace path: %v", err)
+	}
+	if want := filepath.Join(configDir, "workspaces", "work", "tasks.db"); workPath != want {
+		t.Errorf("expected work workspace at %s, got %s", want, workPath)
+	}
+
+	if _, err := GetWorkspaceDBPath("../evil"); err == nil {
+		t.Error("expected invalid workspace name to be rejected")
+	}
+}
+
+func TestListAndActiveWorkspaces(t *testing.T) {
+	setupConfigDir(t)
+
+	names, err := ListWorkspaces()
+	if err != nil {
+		t.Fatalf("failed to list workspaces: %v", err)
+	}
+	if !slices.Equal(names, []string{DefaultWorkspace}) {
+		t.Fatalf("expected only the default workspace, got %v", names)
+	}
+
+	GetWorkspaceDBPath("personal")
+	GetWorkspaceDBPath("employer")
+	names, _ = ListWorkspaces()
+	if !slices.Equal(names, []string{DefaultWorkspace, "employer", "personal"}) {
+		t.Fatalf("unexpected workspaces %v", names)
+	}
+
+	active, err := GetActiveWorkspace()
+	if err != nil || active != DefaultWorkspace {
+		t.Fatalf("expected default active workspace, got %q (err %v)", active, err)
+	}
+	if err := SetActiveWorkspace("employer"); err != nil {
+		t.Fatalf("failed to set active workspace: %v", err)
+	}
+	if active, _ = GetActiveWorkspace(); active != "employer" {
+		t.Fatalf("expected employer, got %q", active)
+	}
+	if err := SetActiveWorkspace("a/b"); err == nil {
+		t.Error("expected invalid name to be rejected")
+	}
+}
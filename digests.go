@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	// Digest time zones are looked up by name on systems without a zone
+	// database.
+	_ "time/tzdata"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+)
+
+// checkDigests sends the digests due at now, in the configured time zone,
+// and writes their reports when a report folder is set. Returns the digests
+// sent.
+func (a *App) checkDigests(now time.Time) []models.Digest {
+	a.mu.RLock()
+	settings := a.prefs.Digest
+	at, loc := a.prefs.DigestSchedule()
+	schedule, weekStart := a.schedule, a.prefs.WeekStart
+	sent := a.digestSent
+	a.mu.RUnlock()
+	if !settings.Enabled {
+		return nil
+	}
+	now = now.In(loc)
+	due := models.DigestsDue(now, sent.In(loc), at, schedule, weekStart, settings.Weekly)
+	if len(due) == 0 {
+		return nil
+	}
+	a.mu.Lock()
+	a.digestSent = now
+	a.mu.Unlock()
+	if err := a.db.SetDigestSent(now); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save the last digest: %v\n", err)
+	}
+
+	digests := make([]models.Digest, 0, len(due))
+	for _, kind := range due {
+		// Digests report the time as tracked, like the summary by default.
+		a.mu.RLock()
+		d := models.ComputeDigest(a.tasks, a.currentTask, kind, now, schedule, weekStart, models.RoundingRules{})
+		a.mu.RUnlock()
+		digests = append(digests, d)
+
+		message := d.Message()
+		if settings.ReportDir != "" {
+			if path, err := a.writeDigestReport(d, settings); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to write the digest report: %v\n", err)
+			} else {
+				message += "\nReport: " + path
+			}
+		}
+		fyne.Do(func() {
+			a.app.SendNotification(fyne.NewNotification(d.Title(), message))
+		})
+	}
+	return digests
+}
+
+// writeDigestReport writes d to the report folder, relative to the config
+// file unless absolute, creating the folder if needed. Returns the path of
+// the file written.
+func (a *App) writeDigestReport(d models.Digest, settings models.DigestSettings) (string, error) {
+	name, content, err := d.Report(settings.ReportFormat)
+	if err != nil {
+		return "", err
+	}
+	dir := settings.ReportDir
+	if !filepath.IsAbs(dir) && a.configPath != "" {
+		dir = filepath.Join(filepath.Dir(a.configPath), dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"trackyou/models"
+	"trackyou/ui"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// openSummaryProject shows project's time by description in the Summary
+// tab, for the period shown there.
+func (a *App) openSummaryProject(project string) {
+	a.summaryProject, a.summaryDescription = project, nil
+	a.refreshWeeklyChart()
+}
+
+// openSummaryDescription shows the entries of the drilled-into project
+// under description.
+func (a *App) openSummaryDescription(description string) {
+	a.summaryDescription = &description
+	a.refreshWeeklyChart()
+}
+
+// summaryBack goes up one level of the drill-down: from the entries to the
+// descriptions, and from there to the summary of all projects.
+func (a *App) summaryBack() {
+	if a.summaryDescription != nil {
+		a.summaryDescription = nil
+	} else {
+		a.summaryProject = ""
+	}
+	a.refreshWeeklyChart()
+}
+
+// makeDrillDownContent returns the Summary tab's drill-down into b: the
+// descriptions of its project, or the entries of the drilled-into one, below
+// a Back button and the path taken.
+func (a *App) makeDrillDownContent(b models.ProjectBreakdown) fyne.CanvasObject {
+	path := b.ProjectName
+	var content fyne.CanvasObject
+	if a.summaryDescription == nil {
+		content = ui.MakeBreakdownContent(b, a.openSummaryDescription)
+	} else {
+		description := *a.summaryDescription
+		path += " › " + ui.DescriptionName(description)
+		series, ok := b.Description(description)
+		if !ok {
+			lbl := widget.NewLabel("No entries in this period.")
+			lbl.Importance = widget.LowImportance
+			content = container.NewCenter(lbl)
+		} else {
+			content = ui.MakeEntriesContent(series, a.showEditTaskDialog)
+		}
+	}
+
+	back := widget.NewButtonWithIcon("Back", theme.NavigateBackIcon(), a.summaryBack)
+	back.Importance = widget.LowImportance
+	pathLabel := widget.NewLabel(path)
+	pathLabel.TextStyle = fyne.TextStyle{Bold: true}
+	pathLabel.Truncation = fyne.TextTruncateEllipsis
+	return container.NewBorder(container.NewBorder(nil, nil, back, nil, pathLabel), nil, nil, nil, content)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+)
+
+// estimateStatus describes the time left on an estimate, or how far it has
+// been overrun, for the timer label.
+func estimateStatus(elapsed, estimate time.Duration) string {
+	if remaining := estimate - elapsed; remaining > 0 {
+		// Round up so the last minute reads "1m left" rather than "0m left".
+		return models.FormatEstimate((remaining + time.Minute - 1).Truncate(time.Minute)) + " left"
+	}
+	return models.FormatEstimate(elapsed-estimate) + " over"
+}
+
+// formatEstimateInput formats an estimate for the estimate entry so that it
+// parses back to the same duration, or returns "" for none.
+func formatEstimateInput(estimate time.Duration) string {
+	if estimate <= 0 {
+		return ""
+	}
+	s := estimate.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// showEstimateProgress shows how much of the running task's estimate has
+// been used, hiding the bar when the task has no estimate.
+func (a *App) showEstimateProgress(elapsed, estimate time.Duration) {
+	if a.estimateBar == nil {
+		return
+	}
+	if estimate <= 0 {
+		a.estimateBar.Hide()
+		return
+	}
+	a.estimateBar.SetValue(min(models.EstimateProgress(elapsed, estimate), 1))
+	a.estimateBar.Show()
+}
+
+// checkEstimate notifies the user once when the running task runs past its
+// estimate. Returns true if a notification was sent.
+func (a *App) checkEstimate(now time.Time) bool {
+	a.mu.Lock()
+	task := a.currentTask
+	if task == nil || task.Estimate <= 0 || a.estimateNotified || task.Elapsed(now) < task.Estimate {
+		a.mu.Unlock()
+		return false
+	}
+	a.estimateNotified = true
+	project, estimate := task.ProjectName, task.Estimate
+	a.mu.Unlock()
+
+	a.app.SendNotification(fyne.NewNotification(
+		"Estimate Exceeded",
+		fmt.Sprintf("%s has run past its %s estimate.", project, models.FormatEstimate(estimate)),
+	))
+	return true
+}
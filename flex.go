@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// loadFlexLedger recomputes the flex-time balance from the saved tasks and
+// corrections up to now. The running task is added as it runs by
+// updateFlexLabel.
+func (a *App) loadFlexLedger(now time.Time) error {
+	a.mu.RLock()
+	start, enabled := a.prefs.FlexStartDate()
+	opening := a.prefs.FlexOpening()
+	schedule := a.schedule
+	a.mu.RUnlock()
+
+	var ledger models.FlexLedger
+	if enabled {
+		corrections, err := a.db.GetFlexCorrections()
+		if err != nil {
+			return err
+		}
+		a.mu.RLock()
+		ledger = models.ComputeFlexLedger(a.tasks, corrections, schedule, start, opening, now)
+		a.mu.RUnlock()
+	}
+
+	a.mu.Lock()
+	a.flexEnabled = enabled
+	a.flexLedger = ledger
+	a.flexComputed = models.PeriodOf(models.PeriodDay, now, time.Monday).Start
+	a.mu.Unlock()
+	return nil
+}
+
+// refreshFlex recomputes the flex-time balance and redraws the header and
+// the Flex tab.
+func (a *App) refreshFlex() {
+	if err := a.loadFlexLedger(time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load flex-time corrections: %v\n", err)
+	}
+	a.mu.RLock()
+	total := a.calculateTotalDurationTodayUnlocked()
+	a.mu.RUnlock()
+	a.updateFlexLabel(total)
+	a.refreshFlexView()
+}
+
+// updateFlexLabel shows the flex-time balance next to today's total, with
+// today's tracked time counting as it runs.
+func (a *App) updateFlexLabel(today time.Duration) {
+	if a.flexLabel == nil {
+		return
+	}
+	now := time.Now()
+	a.mu.RLock()
+	stale := !a.flexComputed.Equal(models.PeriodOf(models.PeriodDay, now, time.Monday).Start)
+	a.mu.RUnlock()
+	// The previous day's shortfall counts once it is over.
+	if stale {
+		if err := a.loadFlexLedger(now); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load flex-time corrections: %v\n", err)
+		}
+	}
+
+	a.mu.RLock()
+	enabled, ledger := a.flexEnabled, a.flexLedger
+	a.mu.RUnlock()
+	if !enabled {
+		a.flexLabel.Hide()
+		return
+	}
+	a.flexLabel.SetText("Flex: " + models.FormatFlex(ledger.BalanceWithToday(today)))
+	a.flexLabel.Show()
+}
+
+// makeFlexView returns the Flex tab: the flex-time ledger of one month with
+// navigation and corrections.
+func (a *App) makeFlexView() fyne.CanvasObject {
+	a.flexMonthLabel = widget.NewLabel("")
+	a.flexMonthLabel.TextStyle = fyne.TextStyle{Bold: true}
+	a.flexTotals = widget.NewLabel("")
+	a.flexRows = container.NewVBox()
+
+	previous := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		a.flexMonth--
+		a.refreshFlexView()
+	})
+	next := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() {
+		a.flexMonth++
+		a.refreshFlexView()
+	})
+	current := widget.NewButton("This Month", func() {
+		a.flexMonth = 0
+		a.refreshFlexView()
+	})
+	add := widget.NewButtonWithIcon("Add Correction…", theme.ContentAddIcon(), a.showFlexCorrectionDialog)
+
+	header := container.NewVBox(
+		container.NewHBox(previous, a.flexMonthLabel, next, current, layout.NewSpacer(), add),
+		a.flexTotals,
+	)
+	return container.NewBorder(header, nil, nil, nil, container.NewVScroll(a.flexRows))
+}
+
+// refreshFlexView redraws the Flex tab for the month it shows.
+func (a *App) refreshFlexView() {
+	if a.flexRows == nil {
+		return
+	}
+	month := models.PeriodOf(models.PeriodMonth, time.Now(), time.Monday).Shift(a.flexMonth)
+	a.flexMonthLabel.SetText(month.Label())
+
+	a.mu.RLock()
+	enabled, ledger := a.flexEnabled, a.flexLedger
+	a.mu.RUnlock()
+
+	a.flexRows.RemoveAll()
+	if !enabled {
+		a.flexTotals.SetText("Set a flex-time start date in Settings to keep a balance.")
+		a.flexRows.Refresh()
+		return
+	}
+
+	opening, days := ledger.Month(month)
+	closing := opening
+	if len(days) > 0 {
+		closing = days[len(days)-1].Balance
+	}
+	a.flexTotals.SetText(fmt.Sprintf("Opening balance %s · closing balance %s",
+		models.FormatFlex(opening), models.FormatFlex(closing)))
+
+	if len(days) > 0 {
+		a.flexRows.Add(flexRow(true, "Day", "Tracked", "Target", "Difference", "Balance"))
+	}
+	for _, day := range days {
+		row := flexRow(false,
+			day.Date.Format("Mon 2"),
+			models.FormatEstimate(day.Tracked),
+			models.FormatEstimate(day.Target),
+			models.FormatFlex(day.Difference()),
+			models.FormatFlex(day.Balance),
+		)
+		a.flexRows.Add(row)
+		for _, c := range day.Corrections {
+			a.flexRows.Add(a.flexCorrectionRow(c))
+		}
+	}
+	a.flexRows.Refresh()
+}
+
+// flexRow lays out one line of the ledger in columns.
+func flexRow(header bool, cells ...string) fyne.CanvasObject {
+	row := container.NewGridWithColumns(len(cells))
+	for _, text := range cells {
+		label := widget.NewLabel(text)
+		label.TextStyle = fyne.TextStyle{Bold: header}
+		row.Add(label)
+	}
+	return row
+}
+
+// flexCorrectionRow shows a correction below its day, with a button to
+// remove it.
+func (a *App) flexCorrectionRow(c models.FlexCorrection) fyne.CanvasObject {
+	text := "Correction " + models.FormatFlex(c.Amount)
+	if c.Note != "" {
+		text += " · " + c.Note
+	}
+	label := widget.NewLabel(text)
+	label.Importance = widget.LowImportance
+	remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		if err := a.removeFlexCorrection(c.ID); err != nil {
+			a.showDialogError(err)
+		}
+	})
+	remove.Importance = widget.LowImportance
+	return container.NewHBox(layout.NewSpacer(), label, remove)
+}
+
+// showFlexCorrectionDialog asks for a correction to the flex-time balance.
+func (a *App) showFlexCorrectionDialog() {
+	dateEntry := widget.NewEntry()
+	dateEntry.SetPlaceHolder("YYYY-MM-DD")
+	dateEntry.SetText(time.Now().Format(time.DateOnly))
+	amountEntry := widget.NewEntry()
+	amountEntry.SetPlaceHolder("-7h30m")
+	noteEntry := widget.NewEntry()
+	noteEntry.SetPlaceHolder("Paid out overtime")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Date", dateEntry),
+		widget.NewFormItem("Amount", amountEntry),
+		widget.NewFormItem("Note", noteEntry),
+	}
+	dialog.ShowForm("Flex-Time Correction", "Add", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if err := a.addFlexCorrection(dateEntry.Text, amountEntry.Text, noteEntry.Text); err != nil {
+			a.showDialogError(err)
+		}
+	}, a.window)
+}
+
+// addFlexCorrection records a correction of amount, such as "-7h30m", to the
+// balance on date, given as YYYY-MM-DD.
+func (a *App) addFlexCorrection(date, amount, note string) error {
+	day, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(date), time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+	}
+	d, err := models.ParseFlexAmount(amount)
+	if err != nil {
+		return err
+	}
+	c := models.FlexCorrection{Date: day, Amount: d, Note: strings.TrimSpace(note)}
+	if err := a.db.AddFlexCorrection(&c); err != nil {
+		return err
+	}
+	a.refreshFlex()
+	return nil
+}
+
+// removeFlexCorrection deletes a correction to the flex-time balance.
+func (a *App) removeFlexCorrection(id int64) error {
+	if err := a.db.DeleteFlexCorrection(id); err != nil {
+		return err
+	}
+	a.refreshFlex()
+	return nil
+}
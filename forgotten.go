@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// forgottenTimer reports whether the running task breaks a forgotten-timer
+// rule at now.
+func (a *App) forgottenTimer(now time.Time) (models.ForgottenTimer, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.prefs.ForgottenRules().Check(a.currentTask, now)
+}
+
+// checkForgotten notifies once per task when the running timer looks
+// forgotten. Returns true if a notification was sent.
+func (a *App) checkForgotten(now time.Time) bool {
+	forgotten, ok := a.forgottenTimer(now)
+	if !ok {
+		return false
+	}
+
+	a.mu.Lock()
+	if a.forgottenNotified || a.currentTask == nil {
+		a.mu.Unlock()
+		return false
+	}
+	a.forgottenNotified = true
+	project := a.currentTask.ProjectName
+	a.mu.Unlock()
+
+	a.app.SendNotification(fyne.NewNotification("TrackYou",
+		fmt.Sprintf("%s %s. Did you forget to stop it?", project, forgotten.Reason)))
+	return true
+}
+
+// lastActivityTime returns the last input before the running task's latest
+// away period, or zero when no system idle time is known.
+func (a *App) lastActivityTime() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if !a.awaySince.IsZero() {
+		return a.awaySince
+	}
+	return a.lastActivity
+}
+
+// stopTaskAt stops the running task at end, which must lie between its start
+// and now, discarding the time after end.
+func (a *App) stopTaskAt(end time.Time) error {
+	a.mu.RLock()
+	task := a.currentTask
+	a.mu.RUnlock()
+	if task == nil {
+		return errNoTaskRunning
+	}
+	if end.Before(task.StartTime) || end.After(time.Now()) {
+		return errInvalidTrimTime
+	}
+	_, err := a.finishTaskAt(end)
+	return err
+}
+
+// showForgottenDialog asks how to end a task that looks forgotten: keep all
+// of its time, or trim it to the last activity or a chosen time.
+func (a *App) showForgottenDialog(forgotten models.ForgottenTimer) {
+	if os.Getenv("FYNE_TEST_SKIP_GUI") != "" {
+		if _, err := a.finishTask(); err != nil {
+			a.showDialogError(err)
+		}
+		return
+	}
+
+	a.mu.RLock()
+	project := ""
+	if a.currentTask != nil {
+		project = a.currentTask.ProjectName
+	}
+	a.mu.RUnlock()
+
+	message := widget.NewLabel(fmt.Sprintf("%s %s. Trim the entry before saving it?", project, forgotten.Reason))
+	message.Wrapping = fyne.TextWrapWord
+
+	endEntry := widget.NewEntry()
+	endEntry.SetText(forgotten.Cutoff.Format(taskTimeLayout))
+	form := widget.NewForm(widget.NewFormItem("End Time", endEntry))
+
+	var d *dialog.CustomDialog
+	stopAt := func(end time.Time) {
+		if err := a.stopTaskAt(end); err != nil {
+			a.showDialogError(err)
+			return
+		}
+		d.Hide()
+	}
+
+	cancel := widget.NewButton("Cancel", func() { d.Hide() })
+	keep := widget.NewButton("Keep All", func() {
+		d.Hide()
+		if _, err := a.finishTask(); err != nil {
+			a.showDialogError(err)
+		}
+	})
+	trim := widget.NewButton("Trim to Time", func() {
+		end, err := time.ParseInLocation(taskTimeLayout, strings.TrimSpace(endEntry.Text), time.Local)
+		if err != nil {
+			a.showDialogError(fmt.Errorf("invalid end time: %w", err))
+			return
+		}
+		stopAt(end)
+	})
+	trim.Importance = widget.HighImportance
+	buttons := []fyne.CanvasObject{cancel, keep}
+
+	if last := a.lastActivityTime(); !last.IsZero() {
+		buttons = append(buttons, widget.NewButton("Trim to Last Activity ("+last.Format("15:04")+")", func() {
+			stopAt(last)
+		}))
+	}
+	buttons = append(buttons, trim)
+
+	d = dialog.NewCustomWithoutButtons("Forgotten Timer?", container.NewVBox(message, form), a.window)
+	d.SetButtons(buttons)
+	d.Show()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// loadHolidays returns the holidays entered by hand followed by those of the
+// .ics calendar at path, if any. Relative paths are taken from the directory
+// of config.toml. The holidays entered by hand are returned even when the
+// calendar cannot be read.
+func (a *App) loadHolidays(path string) ([]models.Holiday, error) {
+	holidays, err := a.db.GetHolidays()
+	if err != nil || path == "" {
+		return holidays, err
+	}
+	if !filepath.IsAbs(path) && a.configPath != "" {
+		path = filepath.Join(filepath.Dir(a.configPath), path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return holidays, err
+	}
+	defer f.Close()
+	calendar, err := models.ParseICS(f, time.Local)
+	if err != nil {
+		return holidays, fmt.Errorf("%s: %w", path, err)
+	}
+	return append(holidays, calendar...), nil
+}
+
+// showHolidaysDialog lists the holidays entered by hand, with buttons to add
+// and remove them.
+func (a *App) showHolidaysDialog() {
+	rows := container.NewVBox()
+	var refresh func()
+	refresh = func() {
+		rows.RemoveAll()
+		holidays, err := a.db.GetHolidays()
+		if err != nil {
+			a.showDialogError(err)
+		}
+		if len(holidays) == 0 {
+			rows.Add(widget.NewLabel("No holidays entered yet."))
+		}
+		for _, h := range holidays {
+			text := h.Date.In(time.Local).Format("Mon, Jan 2, 2006")
+			if h.Yearly {
+				text = h.Date.In(time.Local).Format("Jan 2") + " every year"
+			}
+			if h.Name != "" {
+				text += " · " + h.Name
+			}
+			id := h.ID
+			remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				if err := a.removeHoliday(id); err != nil {
+					a.showDialogError(err)
+				}
+				refresh()
+			})
+			remove.Importance = widget.LowImportance
+			rows.Add(container.NewHBox(widget.NewLabel(text), layout.NewSpacer(), remove))
+		}
+		rows.Refresh()
+	}
+	refresh()
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetPlaceHolder("YYYY-MM-DD")
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Name")
+	yearlyCheck := widget.NewCheck("Every year", nil)
+	add := widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), func() {
+		if err := a.addHoliday(dateEntry.Text, nameEntry.Text, yearlyCheck.Checked); err != nil {
+			a.showDialogError(err)
+			return
+		}
+		dateEntry.SetText("")
+		nameEntry.SetText("")
+		yearlyCheck.SetChecked(false)
+		refresh()
+	})
+
+	a.mu.RLock()
+	file := a.prefs.HolidaysFile
+	a.mu.RUnlock()
+	note := "Holidays from a calendar can be loaded by setting a holidays file (.ics) in Settings."
+	if file != "" {
+		note = "Holidays are also read from " + file + "."
+	}
+	noteLabel := widget.NewLabel(note)
+	noteLabel.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(360, 200))
+	content := container.NewBorder(
+		noteLabel,
+		container.NewBorder(nil, nil, nil, add, container.NewGridWithColumns(3, dateEntry, nameEntry, yearlyCheck)),
+		nil, nil,
+		scroll,
+	)
+	dialog.ShowCustom("Holidays", "Close", content, a.window)
+}
+
+// addHoliday records a day off on date, given as YYYY-MM-DD, repeating every
+// year if yearly.
+func (a *App) addHoliday(date, name string, yearly bool) error {
+	day, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(date), time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+	}
+	h := models.Holiday{Date: day, Name: strings.TrimSpace(name), Yearly: yearly}
+	if err := a.db.AddHoliday(&h); err != nil {
+		return err
+	}
+	a.reloadPreferences()
+	return nil
+}
+
+// removeHoliday deletes a holiday entered by hand.
+func (a *App) removeHoliday(id int64) error {
+	if err := a.db.DeleteHoliday(id); err != nil {
+		return err
+	}
+	a.reloadPreferences()
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !(linux || freebsd || openbsd || netbsd || dragonfly)
+
+package idle
+
+// candidates is empty where neither D-Bus desktop services nor X11 are
+// expected.
+func candidates() []Source {
+	return nil
+}
+
+// watchSleep has no session services to listen to here; suspends are still
+// noticed from jumps in the wall clock.
+func watchSleep() (SleepWatcher, error) {
+	return nil, ErrUnsupported
+}
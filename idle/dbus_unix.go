@@ -0,0 +1,87 @@
+//go:build linux || freebsd || openbsd || netbsd || dragonfly
+
+package idle
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func candidates() []Source {
+	return []Source{
+		&dbusSource{name: "gnome-mutter", bus: dbus.SessionBus, query: queryMutter},
+		&dbusSource{name: "freedesktop-screensaver", bus: dbus.SessionBus, query: queryScreenSaver},
+		NewX11(),
+		&dbusSource{name: "logind", bus: dbus.SystemBus, query: queryLogind},
+	}
+}
+
+// dbusSource asks a desktop service on the session or system bus.
+type dbusSource struct {
+	name  string
+	bus   func() (*dbus.Conn, error)
+	query func(ctx context.Context, conn *dbus.Conn) (time.Duration, error)
+}
+
+func (s *dbusSource) Name() string { return s.name }
+
+func (s *dbusSource) IdleTime() (time.Duration, error) {
+	conn, err := s.bus()
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	return s.query(ctx, conn)
+}
+
+// queryMutter uses GNOME Shell's idle monitor, which reports milliseconds.
+func queryMutter(ctx context.Context, conn *dbus.Conn) (time.Duration, error) {
+	var ms uint64
+	obj := conn.Object("org.gnome.Mutter.IdleMonitor", "/org/gnome/Mutter/IdleMonitor/Core")
+	if err := obj.CallWithContext(ctx, "org.gnome.Mutter.IdleMonitor.GetIdletime", 0).Store(&ms); err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// queryScreenSaver uses org.freedesktop.ScreenSaver. KDE, its main
+// implementation, reports milliseconds.
+func queryScreenSaver(ctx context.Context, conn *dbus.Conn) (time.Duration, error) {
+	var ms uint32
+	obj := conn.Object("org.freedesktop.ScreenSaver", "/org/freedesktop/ScreenSaver")
+	if err := obj.CallWithContext(ctx, "org.freedesktop.ScreenSaver.GetSessionIdleTime", 0).Store(&ms); err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// queryLogind reads the session's idle hint from systemd-logind. The hint
+// is only set once the desktop's own idle delay has passed, so this source
+// is coarse and tried last.
+func queryLogind(ctx context.Context, conn *dbus.Conn) (time.Duration, error) {
+	obj := conn.Object("org.freedesktop.login1", "/org/freedesktop/login1/session/auto")
+
+	var idle bool
+	if err := obj.CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0,
+		"org.freedesktop.login1.Session", "IdleHint").Store(&idle); err != nil {
+		return 0, err
+	}
+	if !idle {
+		return 0, nil
+	}
+
+	var sinceUsec uint64
+	if err := obj.CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0,
+		"org.freedesktop.login1.Session", "IdleSinceHint").Store(&sinceUsec); err != nil {
+		return 0, err
+	}
+	if sinceUsec == 0 {
+		return 0, errors.New("logind reports idle without a start time")
+	}
+	since := time.UnixMicro(int64(sinceUsec))
+	return max(time.Since(since), 0), nil
+}
@@ -0,0 +1,71 @@
+// Package idle reports how long the user has been away from the keyboard
+// and mouse, using whatever the desktop session provides.
+package idle
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrUnsupported is returned when no source works in this session.
+var ErrUnsupported = errors.New("system idle time is not available")
+
+// queryTimeout bounds a single idle-time query so a stuck desktop service
+// cannot stall the caller.
+const queryTimeout = 2 * time.Second
+
+// Source reports the time since the user's last keyboard or mouse input.
+type Source interface {
+	IdleTime() (time.Duration, error)
+	// Name identifies the source in logs.
+	Name() string
+}
+
+// Detect returns the first source that answers in this session. Desktop
+// services are tried before the X11 screensaver extension, which only sees
+// X clients under Wayland.
+func Detect() (Source, error) {
+	var errs []error
+	for _, source := range candidates() {
+		if _, err := source.IdleTime(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+			continue
+		}
+		return source, nil
+	}
+	if len(errs) == 0 {
+		return nil, ErrUnsupported
+	}
+	return nil, fmt.Errorf("%w (%w)", ErrUnsupported, errors.Join(errs...))
+}
+
+// Fake is a Source whose idle time is set by tests.
+type Fake struct {
+	mu   sync.Mutex
+	idle time.Duration
+	err  error
+}
+
+// Set makes IdleTime report d.
+func (f *Fake) Set(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.idle, f.err = d, nil
+}
+
+// SetError makes IdleTime fail with err.
+func (f *Fake) SetError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func (f *Fake) IdleTime() (time.Duration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.idle, f.err
+}
+
+func (f *Fake) Name() string { return "fake" }
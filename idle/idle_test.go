@@ -0,0 +1,203 @@
+package idle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	var f Fake
+	f.Set(3 * time.Minute)
+	if d, err := f.IdleTime(); err != nil || d != 3*time.Minute {
+		t.Fatalf("expected 3m, got %v (%v)", d, err)
+	}
+
+	boom := errors.New("boom")
+	f.SetError(boom)
+	if _, err := f.IdleTime(); !errors.Is(err, boom) {
+		t.Fatalf("expected error, got %v", err)
+	}
+
+	f.Set(time.Second)
+	if _, err := f.IdleTime(); err != nil {
+		t.Fatalf("expected Set to clear the error, got %v", err)
+	}
+}
+
+func TestParseDisplay(t *testing.T) {
+	tests := []struct {
+		display, socket, number string
+		wantErr                 bool
+	}{
+		{display: ":0", socket: "/tmp/.X11-unix/X0", number: "0"},
+		{display: ":1.0", socket: "/tmp/.X11-unix/X1", number: "1"},
+		{display: "unix:12", socket: "/tmp/.X11-unix/X12", number: "12"},
+		{display: "", wantErr: true},
+		{display: "localhost:10.0", wantErr: true},
+		{display: ":", wantErr: true},
+		{display: ":x", wantErr: true},
+	}
+	for _, tt := range tests {
+		socket, number, err := parseDisplay(tt.display)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDisplay(%q): expected error", tt.display)
+			}
+			continue
+		}
+		if err != nil || socket != tt.socket || number != tt.number {
+			t.Errorf("parseDisplay(%q) = %q, %q, %v; want %q, %q", tt.display, socket, number, err, tt.socket, tt.number)
+		}
+	}
+}
+
+func xauthEntry(family uint16, fields ...string) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, family)
+	for _, field := range fields {
+		binary.Write(&buf, binary.BigEndian, uint16(len(field)))
+		buf.WriteString(field)
+	}
+	return buf.Bytes()
+}
+
+func TestReadXauthority(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skipf("no hostname: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "Xauthority")
+	var content []byte
+	content = append(content, xauthEntry(x11FamilyLocal, "otherhost", "0", x11AuthCookie, "wrong-host")...)
+	content = append(content, xauthEntry(x11FamilyLocal, hostname, "1", x11AuthCookie, "wrong-display")...)
+	content = append(content, xauthEntry(x11FamilyLocal, hostname, "0", x11AuthCookie, "cookie")...)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XAUTHORITY", path)
+
+	name, data := readXauthority("0")
+	if string(name) != x11AuthCookie || string(data) != "cookie" {
+		t.Fatalf("expected cookie for display 0, got %q %q", name, data)
+	}
+	if name, _ := readXauthority("7"); name != nil {
+		t.Fatalf("expected no cookie for display 7, got %q", name)
+	}
+}
+
+// fakeXServer answers the handshake, QueryExtension and
+// ScreenSaverQueryInfo on conn, reporting idle as the time since input.
+func fakeXServer(t *testing.T, conn net.Conn, root uint32, idle time.Duration) {
+	t.Helper()
+	defer conn.Close()
+	le := binary.LittleEndian
+
+	setup := make([]byte, 12)
+	if _, err := io.ReadFull(conn, setup); err != nil {
+		t.Errorf("read setup: %v", err)
+		return
+	}
+	auth := pad4(int(le.Uint16(setup[6:]))) + pad4(int(le.Uint16(setup[8:])))
+	io.CopyN(io.Discard, conn, int64(auth))
+
+	vendor := "fake"
+	body := make([]byte, x11SetupFixedLength)
+	le.PutUint16(body[16:], uint16(len(vendor)))
+	body[21] = 1 // one pixmap format
+	body = append(body, padded([]byte(vendor))...)
+	body = append(body, make([]byte, x11SetupFormatLength)...)
+	screen := make([]byte, 40)
+	le.PutUint32(screen, root)
+	body = append(body, screen...)
+	header := make([]byte, 8)
+	header[0] = x11ReplyOK
+	le.PutUint16(header[6:], uint16(len(body)/4))
+	conn.Write(append(header, body...))
+
+	for range 2 {
+		req := make([]byte, 4)
+		if _, err := io.ReadFull(conn, req); err != nil {
+			t.Errorf("read request: %v", err)
+			return
+		}
+		rest := make([]byte, int(le.Uint16(req[2:]))*4-4)
+		io.ReadFull(conn, rest)
+
+		// Precede each reply with an event the client must skip.
+		event := make([]byte, 32)
+		event[0] = 12
+		conn.Write(event)
+
+		reply := make([]byte, 32)
+		reply[0] = x11ReplyOK
+		switch req[0] {
+		case x11OpQueryExtension:
+			reply[x11QueryExtensionPresent] = 1
+			reply[x11QueryExtensionMajorCode] = 140
+		case 140:
+			if got := le.Uint32(rest); got != root {
+				t.Errorf("expected query on root %#x, got %#x", root, got)
+			}
+			le.PutUint32(reply[x11ScreenSaverIdleOffset:], uint32(idle.Milliseconds()))
+		default:
+			t.Errorf("unexpected opcode %d", req[0])
+			return
+		}
+		conn.Write(reply)
+	}
+}
+
+func TestX11Protocol(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	const root = 0x1a2
+	go fakeXServer(t, server, root, 95*time.Second)
+
+	gotRoot, err := x11Setup(client, nil, nil)
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if gotRoot != root {
+		t.Fatalf("expected root %#x, got %#x", root, gotRoot)
+	}
+	opcode, err := x11QueryExtension(client, screenSaverExtension)
+	if err != nil {
+		t.Fatalf("query extension: %v", err)
+	}
+
+	x := &X11{conn: client, root: gotRoot, opcode: opcode}
+	idle, err := x.queryInfo()
+	if err != nil {
+		t.Fatalf("query info: %v", err)
+	}
+	if idle != 95*time.Second {
+		t.Fatalf("expected 95s idle, got %v", idle)
+	}
+}
+
+func TestX11_NoDisplay(t *testing.T) {
+	x := &X11{}
+	if _, err := x.IdleTime(); err == nil {
+		t.Fatal("expected an error without DISPLAY")
+	}
+}
+
+func TestFakeSleep(t *testing.T) {
+	f := NewFakeSleep()
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	f.Send(Suspend, at)
+	if ev := <-f.Events(); ev.Kind != Suspend || !ev.Kind.Asleep() || !ev.At.Equal(at) {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+	f.Close()
+	if _, ok := <-f.Events(); ok {
+		t.Fatal("expected the events channel closed")
+	}
+}
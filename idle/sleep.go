@@ -0,0 +1,76 @@
+package idle
+
+import "time"
+
+// SleepKind says what a SleepEvent reports.
+type SleepKind int
+
+const (
+	// Suspend is sent just before the machine goes to sleep.
+	Suspend SleepKind = iota
+	// Resume is sent once the machine has woken up.
+	Resume
+	// Lock is sent when the screen locks or the screensaver starts.
+	Lock
+	// Unlock is sent when the screen is unlocked again.
+	Unlock
+)
+
+func (k SleepKind) String() string {
+	switch k {
+	case Suspend:
+		return "suspend"
+	case Resume:
+		return "resume"
+	case Lock:
+		return "lock"
+	default:
+		return "unlock"
+	}
+}
+
+// Asleep reports whether k starts a period without the user, ended by the
+// matching Resume or Unlock.
+func (k SleepKind) Asleep() bool {
+	return k == Suspend || k == Lock
+}
+
+// SleepEvent is a suspend, resume, lock or unlock at At.
+type SleepEvent struct {
+	Kind SleepKind
+	At   time.Time
+}
+
+// SleepWatcher delivers the session's sleep events until it is closed.
+type SleepWatcher interface {
+	Events() <-chan SleepEvent
+	Close() error
+}
+
+// WatchSleep subscribes to suspend and screen-lock notifications from the
+// desktop session, returning ErrUnsupported when neither is available.
+func WatchSleep() (SleepWatcher, error) {
+	return watchSleep()
+}
+
+// FakeSleep is a SleepWatcher whose events are sent by tests.
+type FakeSleep struct {
+	events chan SleepEvent
+}
+
+// NewFakeSleep returns a FakeSleep with room for a few pending events.
+func NewFakeSleep() *FakeSleep {
+	return &FakeSleep{events: make(chan SleepEvent, 8)}
+}
+
+// Send delivers an event of kind at at.
+func (f *FakeSleep) Send(kind SleepKind, at time.Time) {
+	f.events <- SleepEvent{Kind: kind, At: at}
+}
+
+func (f *FakeSleep) Events() <-chan SleepEvent { return f.events }
+
+func (f *FakeSleep) Close() error {
+	close(f.events)
+	return nil
+}
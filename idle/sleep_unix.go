@@ -0,0 +1,122 @@
+//go:build linux || freebsd || openbsd || netbsd || dragonfly
+
+package idle
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// dbusSleepWatcher listens for logind's PrepareForSleep on the system bus and
+// for screensaver ActiveChanged signals on the session bus.
+type dbusSleepWatcher struct {
+	events chan SleepEvent
+	done   chan struct{}
+	conns  []*dbus.Conn
+	wg     sync.WaitGroup
+}
+
+func watchSleep() (SleepWatcher, error) {
+	w := &dbusSleepWatcher{events: make(chan SleepEvent, 8), done: make(chan struct{})}
+	var errs []error
+	subscribe := func(name string, connect func(...dbus.ConnOption) (*dbus.Conn, error), decode func(*dbus.Signal) (SleepKind, bool), matches ...[]dbus.MatchOption) {
+		conn, err := connect()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			return
+		}
+		for _, match := range matches {
+			if err := conn.AddMatchSignal(match...); err != nil {
+				conn.Close()
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				return
+			}
+		}
+		signals := make(chan *dbus.Signal, 8)
+		conn.Signal(signals)
+		w.conns = append(w.conns, conn)
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			// The channel is closed with the connection.
+			for signal := range signals {
+				kind, ok := decode(signal)
+				if !ok {
+					continue
+				}
+				select {
+				case w.events <- SleepEvent{Kind: kind, At: time.Now().Round(0)}:
+				case <-w.done:
+				}
+			}
+		}()
+	}
+
+	subscribe("logind", dbus.ConnectSystemBus, decodePrepareForSleep, []dbus.MatchOption{
+		dbus.WithMatchObjectPath("/org/freedesktop/login1"),
+		dbus.WithMatchInterface("org.freedesktop.login1.Manager"),
+		dbus.WithMatchMember("PrepareForSleep"),
+	})
+	// GNOME only announces its lock screen on its own interface.
+	subscribe("screensaver", dbus.ConnectSessionBus, decodeActiveChanged, []dbus.MatchOption{
+		dbus.WithMatchInterface("org.freedesktop.ScreenSaver"),
+		dbus.WithMatchMember("ActiveChanged"),
+	}, []dbus.MatchOption{
+		dbus.WithMatchInterface("org.gnome.ScreenSaver"),
+		dbus.WithMatchMember("ActiveChanged"),
+	})
+
+	if len(w.conns) == 0 {
+		return nil, fmt.Errorf("%w (%w)", ErrUnsupported, errors.Join(errs...))
+	}
+	return w, nil
+}
+
+// decodePrepareForSleep maps PrepareForSleep(true) to Suspend and
+// PrepareForSleep(false) to Resume.
+func decodePrepareForSleep(signal *dbus.Signal) (SleepKind, bool) {
+	if signal.Name != "org.freedesktop.login1.Manager.PrepareForSleep" || len(signal.Body) != 1 {
+		return 0, false
+	}
+	start, ok := signal.Body[0].(bool)
+	if !ok {
+		return 0, false
+	}
+	if start {
+		return Suspend, true
+	}
+	return Resume, true
+}
+
+// decodeActiveChanged maps ActiveChanged(true) to Lock and
+// ActiveChanged(false) to Unlock.
+func decodeActiveChanged(signal *dbus.Signal) (SleepKind, bool) {
+	if len(signal.Body) != 1 {
+		return 0, false
+	}
+	active, ok := signal.Body[0].(bool)
+	if !ok {
+		return 0, false
+	}
+	if active {
+		return Lock, true
+	}
+	return Unlock, true
+}
+
+func (w *dbusSleepWatcher) Events() <-chan SleepEvent { return w.events }
+
+func (w *dbusSleepWatcher) Close() error {
+	close(w.done)
+	var errs []error
+	for _, conn := range w.conns {
+		errs = append(errs, conn.Close())
+	}
+	w.wg.Wait()
+	close(w.events)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,36 @@
+//go:build linux || freebsd || openbsd || netbsd || dragonfly
+
+package idle
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestDecodeSleepSignals(t *testing.T) {
+	prepare := func(body ...any) *dbus.Signal {
+		return &dbus.Signal{Name: "org.freedesktop.login1.Manager.PrepareForSleep", Body: body}
+	}
+	if kind, ok := decodePrepareForSleep(prepare(true)); !ok || kind != Suspend {
+		t.Errorf("expected PrepareForSleep(true) to be a suspend, got %v (%v)", kind, ok)
+	}
+	if kind, ok := decodePrepareForSleep(prepare(false)); !ok || kind != Resume {
+		t.Errorf("expected PrepareForSleep(false) to be a resume, got %v (%v)", kind, ok)
+	}
+	if _, ok := decodePrepareForSleep(prepare("yes")); ok {
+		t.Error("expected a malformed signal to be ignored")
+	}
+	if _, ok := decodePrepareForSleep(&dbus.Signal{Name: "org.freedesktop.login1.Manager.SessionNew", Body: []any{true}}); ok {
+		t.Error("expected other logind signals to be ignored")
+	}
+
+	changed := &dbus.Signal{Name: "org.gnome.ScreenSaver.ActiveChanged", Body: []any{true}}
+	if kind, ok := decodeActiveChanged(changed); !ok || kind != Lock {
+		t.Errorf("expected ActiveChanged(true) to be a lock, got %v (%v)", kind, ok)
+	}
+	changed.Body = []any{false}
+	if kind, ok := decodeActiveChanged(changed); !ok || kind != Unlock {
+		t.Errorf("expected ActiveChanged(false) to be an unlock, got %v (%v)", kind, ok)
+	}
+}
@@ -0,0 +1,280 @@
+package idle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// screenSaverExtension is the X11 extension that reports input idle time.
+const screenSaverExtension = "MIT-SCREEN-SAVER"
+
+// X11 protocol constants used by the minimal client below.
+const (
+	x11OpQueryExtension        = 98
+	x11ScreenSaverQueryInfo    = 1
+	x11FamilyLocal             = 256
+	x11FamilyWild              = 65535
+	x11AuthCookie              = "MIT-MAGIC-COOKIE-1"
+	x11ReplyError              = 0
+	x11ReplyOK                 = 1
+	x11SetupFixedLength        = 32
+	x11SetupFormatLength       = 8
+	x11ScreenSaverIdleOffset   = 16
+	x11QueryExtensionPresent   = 8
+	x11QueryExtensionMajorCode = 9
+)
+
+// X11 queries the MIT-SCREEN-SAVER extension over the local X socket named
+// by $DISPLAY. It speaks just enough of the X protocol for that one
+// request, so it needs no C libraries.
+type X11 struct {
+	display string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	root   uint32
+	opcode byte
+}
+
+// NewX11 returns a source for the display named by $DISPLAY.
+func NewX11() *X11 {
+	return &X11{display: os.Getenv("DISPLAY")}
+}
+
+func (x *X11) Name() string { return "x11-screensaver" }
+
+func (x *X11) IdleTime() (time.Duration, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.conn == nil {
+		if err := x.connect(); err != nil {
+			return 0, err
+		}
+	}
+	idle, err := x.queryInfo()
+	if err != nil {
+		// Reconnect on the next call, e.g. after the X server restarts.
+		x.conn.Close()
+		x.conn = nil
+	}
+	return idle, err
+}
+
+func (x *X11) connect() error {
+	socket, number, err := parseDisplay(x.display)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("unix", socket, queryTimeout)
+	if err != nil {
+		// Some servers only listen on the Linux abstract socket.
+		var abstractErr error
+		if conn, abstractErr = net.DialTimeout("unix", "@"+socket, queryTimeout); abstractErr != nil {
+			return err
+		}
+	}
+	conn.SetDeadline(time.Now().Add(queryTimeout))
+
+	name, data := readXauthority(number)
+	root, err := x11Setup(conn, name, data)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	opcode, err := x11QueryExtension(conn, screenSaverExtension)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	x.conn, x.root, x.opcode = conn, root, opcode
+	return nil
+}
+
+func (x *X11) queryInfo() (time.Duration, error) {
+	x.conn.SetDeadline(time.Now().Add(queryTimeout))
+	req := make([]byte, 8)
+	req[0] = x.opcode
+	req[1] = x11ScreenSaverQueryInfo
+	binary.LittleEndian.PutUint16(req[2:], 2) // length in 4-byte units
+	binary.LittleEndian.PutUint32(req[4:], x.root)
+	if _, err := x.conn.Write(req); err != nil {
+		return 0, err
+	}
+	reply, err := x11ReadReply(x.conn)
+	if err != nil {
+		return 0, err
+	}
+	ms := binary.LittleEndian.Uint32(reply[x11ScreenSaverIdleOffset:])
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// parseDisplay maps a local $DISPLAY such as ":0" or "unix:1.0" to its
+// socket path and display number.
+func parseDisplay(display string) (socket, number string, err error) {
+	if display == "" {
+		return "", "", errors.New("DISPLAY is not set")
+	}
+	host, rest, ok := strings.Cut(display, ":")
+	if !ok || (host != "" && host != "unix") {
+		return "", "", fmt.Errorf("unsupported DISPLAY %q: only local displays are supported", display)
+	}
+	number, _, _ = strings.Cut(rest, ".")
+	if number == "" || strings.Trim(number, "0123456789") != "" {
+		return "", "", fmt.Errorf("invalid DISPLAY %q", display)
+	}
+	return "/tmp/.X11-unix/X" + number, number, nil
+}
+
+// readXauthority returns the MIT-MAGIC-COOKIE-1 for the local display, or
+// nothing when there is none, in which case the server may still accept
+// the connection through host-based access control.
+func readXauthority(number string) (name, data []byte) {
+	path := os.Getenv("XAUTHORITY")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil
+		}
+		path = filepath.Join(home, ".Xauthority")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+	hostname, _ := os.Hostname()
+
+	r := bufio.NewReader(f)
+	readField := func() ([]byte, error) {
+		var n uint16
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return nil, err
+		}
+		field := make([]byte, n)
+		_, err := io.ReadFull(r, field)
+		return field, err
+	}
+	for {
+		var family uint16
+		if err := binary.Read(r, binary.BigEndian, &family); err != nil {
+			return nil, nil
+		}
+		address, err1 := readField()
+		num, err2 := readField()
+		authName, err3 := readField()
+		authData, err4 := readField()
+		if err := errors.Join(err1, err2, err3, err4); err != nil {
+			return nil, nil
+		}
+		local := family == x11FamilyWild || (family == x11FamilyLocal && string(address) == hostname)
+		if local && (len(num) == 0 || string(num) == number) && string(authName) == x11AuthCookie {
+			return authName, authData
+		}
+	}
+}
+
+// x11Setup performs the connection handshake and returns the first
+// screen's root window.
+func x11Setup(conn net.Conn, authName, authData []byte) (uint32, error) {
+	req := make([]byte, 12, 12+pad4(len(authName))+pad4(len(authData)))
+	req[0] = 'l' // little-endian
+	binary.LittleEndian.PutUint16(req[2:], 11)
+	binary.LittleEndian.PutUint16(req[4:], 0)
+	binary.LittleEndian.PutUint16(req[6:], uint16(len(authName)))
+	binary.LittleEndian.PutUint16(req[8:], uint16(len(authData)))
+	req = append(req, padded(authName)...)
+	req = append(req, padded(authData)...)
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return 0, err
+	}
+	body := make([]byte, int(binary.LittleEndian.Uint16(header[6:]))*4)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return 0, err
+	}
+	if header[0] != x11ReplyOK {
+		reason := body
+		if header[0] == x11ReplyError && int(header[1]) <= len(body) {
+			reason = body[:header[1]]
+		}
+		return 0, fmt.Errorf("X server refused connection: %s", bytes.TrimRight(reason, "\x00"))
+	}
+
+	if len(body) < x11SetupFixedLength {
+		return 0, errors.New("short X setup reply")
+	}
+	vendorLength := int(binary.LittleEndian.Uint16(body[16:]))
+	formats := int(body[21])
+	rootOffset := x11SetupFixedLength + pad4(vendorLength) + formats*x11SetupFormatLength
+	if len(body) < rootOffset+4 {
+		return 0, errors.New("X setup reply has no screens")
+	}
+	return binary.LittleEndian.Uint32(body[rootOffset:]), nil
+}
+
+// x11QueryExtension returns the major opcode of the named extension.
+func x11QueryExtension(conn net.Conn, name string) (byte, error) {
+	req := make([]byte, 8, 8+pad4(len(name)))
+	req[0] = x11OpQueryExtension
+	binary.LittleEndian.PutUint16(req[2:], uint16(2+pad4(len(name))/4))
+	binary.LittleEndian.PutUint16(req[4:], uint16(len(name)))
+	req = append(req, padded([]byte(name))...)
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+	reply, err := x11ReadReply(conn)
+	if err != nil {
+		return 0, err
+	}
+	if reply[x11QueryExtensionPresent] == 0 {
+		return 0, fmt.Errorf("X server lacks the %s extension", name)
+	}
+	return reply[x11QueryExtensionMajorCode], nil
+}
+
+// x11ReadReply returns the next reply, skipping events and failing on
+// errors.
+func x11ReadReply(conn net.Conn) ([]byte, error) {
+	for {
+		packet := make([]byte, 32)
+		if _, err := io.ReadFull(conn, packet); err != nil {
+			return nil, err
+		}
+		switch packet[0] {
+		case x11ReplyError:
+			return nil, fmt.Errorf("X request failed with error code %d", packet[1])
+		case x11ReplyOK:
+			if extra := binary.LittleEndian.Uint32(packet[4:]); extra > 0 {
+				if _, err := io.CopyN(io.Discard, conn, int64(extra)*4); err != nil {
+					return nil, err
+				}
+			}
+			return packet, nil
+		}
+	}
+}
+
+func pad4(n int) int {
+	return (n + 3) &^ 3
+}
+
+func padded(b []byte) []byte {
+	out := make([]byte, pad4(len(b)))
+	copy(out, b)
+	return out
+}
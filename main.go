@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"image/color"
 	"math"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"trackyou/api"
+	"trackyou/cli"
+	"trackyou/config"
 	"trackyou/database"
+	"trackyou/idle"
 	"trackyou/models"
+	"trackyou/state"
 	"trackyou/ui"
 
 	"fyne.io/fyne/v2"
@@ -71,6 +78,61 @@ func resolveTaskEditEndTime(startTime, endTime time.Time, durationInput string,
 	return endTime, nil
 }
 
+// validateTaskStart checks the start of a new task against now and the saved
+// tasks: it may neither lie in the future nor fall inside another task.
+func validateTaskStart(start, now time.Time, tasks []*models.Task) error {
+	if start.After(now) {
+		return errStartInFuture
+	}
+	for _, t := range tasks {
+		if t.EndTime.After(start) && t.StartTime.Before(now) {
+			return fmt.Errorf("%w: %s ran until %s", errStartOverlaps, t.ProjectName, t.EndTime.In(time.Local).Format("15:04"))
+		}
+	}
+	return nil
+}
+
+// segmentSeparator divides a segment's start and end in the edit dialog.
+const segmentSeparator = " - "
+
+// formatSegmentsInput renders segments one per line for the edit dialog.
+func formatSegmentsInput(segments []models.Segment) string {
+	lines := make([]string, len(segments))
+	for i, seg := range segments {
+		lines[i] = seg.Start.In(time.Local).Format(taskTimeLayout) + segmentSeparator + seg.End.In(time.Local).Format(taskTimeLayout)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseSegmentsInput reads the edit dialog's segment lines. Blank lines are
+// skipped; ordering and overlap are checked by Task.SetSegments.
+func parseSegmentsInput(value string) ([]models.Segment, error) {
+	var segments []models.Segment
+	for i, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		startText, endText, ok := strings.Cut(line, segmentSeparator)
+		if !ok {
+			return nil, fmt.Errorf("segment %d: expected \"start%send\"", i+1, segmentSeparator)
+		}
+		start, err := time.ParseInLocation(taskTimeLayout, strings.TrimSpace(startText), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d: invalid start time: %w", i+1, err)
+		}
+		end, err := time.ParseInLocation(taskTimeLayout, strings.TrimSpace(endText), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d: invalid end time: %w", i+1, err)
+		}
+		segments = append(segments, models.Segment{Start: start, End: end})
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("at least one segment is required")
+	}
+	return segments, nil
+}
+
 type App struct {
 	window      fyne.Window
 	app         fyne.App
@@ -86,10 +148,34 @@ type App struct {
 	idleSince     time.Time
 	idleCtx       context.Context
 	idleCancel    context.CancelFunc
+	idleSource    idle.Source // nil when the session reports no idle time
+	awaySince     time.Time   // last input before the user went away from a running task
+	awayPrompting bool
+	lastActivity  time.Time // last input before the running task's latest away period
+
+	sleepWatcher      idle.SleepWatcher // nil when the session reports no suspends or locks
+	suspended, locked bool
+	sleepSince        time.Time // start of the current suspend or lock
+	sleepHandledUntil time.Time // end of the last period the sleep policy was applied to
+
+	forgottenNotified bool
+	estimateNotified  bool
+	budgetAlerted     map[budgetWindow]int // highest alert threshold notified per budget window
+	digestSent        time.Time            // when the last daily digest was sent
+	pomodoro          *pomodoroRun         // nil unless the running timer is in Pomodoro mode
 
 	workdayLength    float64
+	schedule         models.WorkSchedule // daily targets and holidays
+	budgets          []models.Budget
 	goalReachedToday bool
 	desk             desktop.App
+	apiServer        *api.Server
+	statePath        string
+	configPath       string
+	configFile       *config.File
+	prefs            config.Preferences
+	dbPath           string
+	workspace        string // empty when the database was chosen by path
 
 	// UI Components
 	timerLabel       *widget.Label
@@ -97,10 +183,58 @@ type App struct {
 	timerStop        chan struct{}
 	projectEntry     *widget.SelectEntry
 	descriptionEntry *widget.Entry
+	estimateEntry    *widget.Entry
+	startAtEntry     *widget.Entry
+	snapCheck        *widget.Check
+	estimateBar      *widget.ProgressBar
+	budgetBar        *widget.ProgressBar
+	budgetLabel      *widget.Label
 	startButton      *widget.Button
 	stopButton       *widget.Button
+	pauseButton      *widget.Button
 	recordingIcon    *canvas.Circle
 	weeklyCard       *widget.Card
+	roundedCheck     *widget.Check
+	periodSelect     *widget.Select
+	compareSelect    *widget.Select
+
+	// The period shown in the Summary tab: the period of summaryKind
+	// containing now, or customPeriod, moved by summaryOffset periods, and
+	// the period it is compared with. A project drilled into from the
+	// summary, and a description of it, replace the chart with its
+	// breakdown.
+	summaryKind        models.PeriodKind
+	summaryOffset      int
+	customPeriod       models.Period
+	summaryCompare     models.Comparison
+	summaryProject     string
+	summaryDescription *string // nil until a description is drilled into
+
+	// The flex-time balance from saved tasks as of flexComputed's day, and
+	// the Flex tab showing the month flexMonth months from the current one.
+	flexEnabled    bool
+	flexLedger     models.FlexLedger
+	flexComputed   time.Time
+	flexMonth      int
+	flexLabel      *widget.Label
+	flexMonthLabel *widget.Label
+	flexTotals     *widget.Label
+	flexRows       *fyne.Container
+
+	// The Absences tab, showing the year absenceYear years from the current
+	// one.
+	absenceYear      int
+	absenceYearLabel *widget.Label
+	absenceBalances  *fyne.Container
+	absenceRows      *fyne.Container
+
+	// The Activity tab's heatmap of the past year and its project filter.
+	activityProject *widget.Select
+	activityTotals  *widget.Label
+	activityDay     *widget.Label
+	activityHeatmap *fyne.Container
+
+	tabs *container.AppTabs
 }
 
 func (a *App) updateTaskGroups() {
@@ -112,15 +246,38 @@ func (a *App) refreshWeeklyChart() {
 	if a.weeklyCard == nil {
 		return
 	}
+	period := a.summaryPeriod(time.Now())
+	// Rounding only changes what is reported, never the stored durations.
+	var rules models.RoundingRules
 	a.mu.RLock()
-	now := time.Now()
-	summaries := models.ComputeWeeklySummaries(a.tasks, now, models.StartOfCurrentWeek(now))
+	if a.roundedCheck != nil && a.roundedCheck.Checked {
+		rules = a.prefs.RoundingRules()
+	}
+	summary := models.ComputeSummary(a.tasks, period.Start, period.End, period.BucketSize(), a.prefs.WeekStart, rules)
+	summary.Absences = a.schedule.AbsenceTotals(period.Start, period.End)
+	summary.Targets = a.schedule.BucketTargets(summary.Buckets)
+	summary.Budgets = a.budgetUsagesUnlocked(time.Now())
+	subtitle := period.Label()
+	if a.summaryProject != "" {
+		breakdown := models.ComputeProjectBreakdown(a.tasks, a.summaryProject, period.Start, period.End, rules)
+		a.mu.RUnlock()
+		a.weeklyCard.SetSubTitle(subtitle)
+		a.weeklyCard.SetContent(a.makeWeeklyCardContent(a.makeDrillDownContent(breakdown)))
+		return
+	}
+	if a.summaryCompare != models.CompareNone {
+		compared := period.Compared(a.summaryCompare)
+		before := models.ComputeSummary(a.tasks, compared.Start, compared.End, compared.BucketSize(), a.prefs.WeekStart, rules)
+		summary.Deltas = models.CompareProjects(summary.Projects, before.Projects)
+		subtitle += " vs. " + compared.Label()
+	}
 	a.mu.RUnlock()
-	a.weeklyCard.SetContent(a.makeWeeklyCardContent(summaries))
+	a.weeklyCard.SetSubTitle(subtitle)
+	a.weeklyCard.SetContent(a.makeWeeklyCardContent(ui.MakeSummaryChartContent(summary, a.openSummaryProject)))
 }
 
-func (a *App) makeWeeklyCardContent(summaries []models.WeeklySummary) fyne.CanvasObject {
-	weeklyContent := container.NewPadded(ui.MakeWeeklyChartContent(summaries))
+func (a *App) makeWeeklyCardContent(content fyne.CanvasObject) fyne.CanvasObject {
+	weeklyContent := container.NewPadded(content)
 	scroll := container.NewVScroll(weeklyContent)
 	scroll.SetMinSize(fyne.NewSize(0, weeklyCardMinHeight))
 	return scroll
@@ -133,21 +290,29 @@ func (a *App) setGoalReachedToday(reached bool) {
 }
 
 func (a *App) updateSummaryUI(silent bool) {
+	now := time.Now()
 	a.mu.RLock()
-	goal := a.workdayLength
+	goal := a.schedule.Target(now)
+	dayOff := dayOffLabel(a.schedule, now)
 	reached := a.goalReachedToday
 	total := a.calculateTotalDurationTodayUnlocked()
 	a.mu.RUnlock()
 
-	totalText := fmt.Sprintf("Total Today: %v / %.1fh", total.Round(time.Second), goal)
-	if total.Hours() >= goal {
+	totalText := fmt.Sprintf("Total Today: %v / %.1fh", total.Round(time.Second), goal.Hours())
+	if goal <= 0 {
+		// Days off have no goal to reach.
+		a.totalLabel.SetText(fmt.Sprintf("Total Today: %v · %s", total.Round(time.Second), dayOff))
+		if reached {
+			a.setGoalReachedToday(false)
+		}
+	} else if total >= goal {
 		a.totalLabel.SetText("✅ " + totalText)
 		if !reached {
 			a.setGoalReachedToday(true)
 			if !silent {
 				a.app.SendNotification(fyne.NewNotification(
 					"Goal Reached!",
-					fmt.Sprintf("You've completed your %.1f hour workday goal!", goal),
+					fmt.Sprintf("You've completed your %.1f hour workday goal!", goal.Hours()),
 				))
 				a.window.RequestFocus()
 			}
@@ -159,56 +324,75 @@ func (a *App) updateSummaryUI(silent bool) {
 		}
 	}
 	a.totalLabel.Refresh()
+	a.updateFlexLabel(total)
 }
 
 func (a *App) calculateTotalDurationTodayUnlocked() time.Duration {
-	var total time.Duration
 	now := time.Now()
+	total := a.completedDurationTodayUnlocked(now)
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	tomorrow := today.AddDate(0, 0, 1)
 
-	for _, t := range a.tasks {
-		taskStart := t.StartTime
-		taskEnd := t.StartTime.Add(t.Duration)
+	if a.currentTask != nil {
+		total += models.SumWithin(a.currentTask.RunningIntervals(now), today, tomorrow)
+	}
 
-		overlapStart := taskStart
-		if overlapStart.Before(today) {
-			overlapStart = today
-		}
-		overlapEnd := taskEnd
-		if overlapEnd.After(tomorrow) {
-			overlapEnd = tomorrow
-		}
-		if overlapEnd.After(now) {
-			overlapEnd = now
-		}
+	return total
+}
 
-		overlap := overlapEnd.Sub(overlapStart)
-		if overlap > 0 {
-			total += overlap
-		}
+// completedDurationTodayUnlocked sums saved tasks' active time clipped to
+// now's day, excluding the running task.
+func (a *App) completedDurationTodayUnlocked(now time.Time) time.Duration {
+	var total time.Duration
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	tomorrow := today.AddDate(0, 0, 1)
+	end := now
+	if end.After(tomorrow) {
+		end = tomorrow
 	}
 
-	if a.currentTask != nil {
-		taskStart := a.currentTask.StartTime
-		taskEnd := now
-
-		overlapStart := taskStart
-		if overlapStart.Before(today) {
-			overlapStart = today
-		}
-		overlapEnd := taskEnd
-		if overlapEnd.After(tomorrow) {
-			overlapEnd = tomorrow
-		}
+	for _, t := range a.tasks {
+		total += models.SumWithin(t.Intervals(), today, end)
+	}
+	return total
+}
 
-		overlap := overlapEnd.Sub(overlapStart)
-		if overlap > 0 {
-			total += overlap
+// writeStateFile publishes the timer state for `trackyou prompt` and status
+// bars so they never have to open the database.
+func (a *App) writeStateFile() {
+	if a.statePath == "" {
+		return
+	}
+	now := time.Now()
+	a.mu.RLock()
+	s := &state.State{
+		Day:                   state.DayKey(now),
+		CompletedTodaySeconds: int64(a.completedDurationTodayUnlocked(now) / time.Second),
+		WorkdayGoalHours:      a.schedule.Target(now).Hours(),
+		UpdatedAt:             now,
+	}
+	if task := a.currentTask; task != nil {
+		s.Running = true
+		s.Project = task.ProjectName
+		s.Description = task.Description
+		s.StartTime = task.StartTime
+		s.Paused = task.IsPaused()
+
+		// Closed segments are summed here; the prompt adds the open one.
+		closed := task.Segments
+		if n := len(closed); n > 0 && !s.Paused {
+			s.ResumedAt = closed[n-1].Start
+			closed = closed[:n-1]
 		}
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		s.ActiveSeconds = int64(models.SumWithin(closed, time.Time{}, time.Time{}) / time.Second)
+		s.ActiveTodaySeconds = int64(models.SumWithin(closed, today, today.AddDate(0, 0, 1)) / time.Second)
 	}
+	a.mu.RUnlock()
 
-	return total
+	if err := state.Write(a.statePath, s); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write state file: %v\n", err)
+	}
 }
 
 func (a *App) calculateTotalDurationToday() time.Duration {
@@ -222,6 +406,7 @@ func (a *App) updateTimer() {
 	defer ticker.Stop()
 
 	blink := false
+	var lastTick time.Time
 
 	for {
 		select {
@@ -230,12 +415,53 @@ func (a *App) updateTimer() {
 			task := a.currentTask
 			a.mu.RUnlock()
 
+			// Ticks compare wall-clock times so that a suspend shows up as
+			// a jump.
+			last := lastTick
+			lastTick = time.Now().Round(0)
+			tick := lastTick
+
 			fyne.Do(func() {
 				if task != nil {
-					duration := time.Since(task.StartTime)
+					a.checkClockJump(last, tick)
+					now := time.Now()
+					// A Pomodoro phase change may pause the task or hand over
+					// to a new one.
+					a.tickPomodoro(now.Round(0))
+					a.checkEstimate(now.Round(0))
+					a.checkBudget(now.Round(0))
+					a.mu.RLock()
+					current := a.currentTask
+					var duration, estimate time.Duration
+					paused := false
+					if current != nil {
+						duration = current.Elapsed(now)
+						estimate = current.Estimate
+						paused = current.IsPaused()
+					}
+					a.mu.RUnlock()
+					if current == nil {
+						// Stopped since this tick was scheduled.
+						return
+					}
 					blink = !blink
-					a.timerLabel.SetText(fmt.Sprintf("%v", duration.Round(time.Second)))
-					if blink {
+					text := fmt.Sprintf("%v", duration.Round(time.Second))
+					if status := a.pomodoroStatus(now); status != "" {
+						// The phase countdown leads; the task's time follows.
+						text = status + " · " + text
+					} else if paused {
+						text = "Paused · " + text
+					}
+					if estimate > 0 {
+						text += " · " + estimateStatus(duration, estimate)
+					}
+					a.timerLabel.SetText(text)
+					a.showEstimateProgress(duration, estimate)
+					a.showBudgetProgress(now)
+					if paused {
+						// A paused timer holds still with a dimmed icon.
+						a.recordingIcon.FillColor = color.RGBA{R: 255, G: 0, B: 0, A: 100}
+					} else if blink {
 						a.recordingIcon.FillColor = color.RGBA{R: 255, G: 0, B: 0, A: 255}
 					} else {
 						a.recordingIcon.FillColor = color.RGBA{R: 255, G: 0, B: 0, A: 100}
@@ -268,10 +494,19 @@ func (a *App) normalizeTheme(themeName string) string {
 	}
 }
 
+// applyTheme switches to themeName and saves it as the database preference.
 func (a *App) applyTheme(themeName string) {
 	normalizedTheme := a.normalizeTheme(themeName)
+	a.useTheme(normalizedTheme)
 
-	switch normalizedTheme {
+	if err := a.db.SetTheme(normalizedTheme); err != nil {
+		a.showDialogError(err)
+	}
+}
+
+// useTheme switches to themeName without saving it.
+func (a *App) useTheme(themeName string) {
+	switch a.normalizeTheme(themeName) {
 	case "dark":
 		a.app.Settings().SetTheme(ui.NewMaterialTheme(theme.VariantDark))
 	case "system":
@@ -279,10 +514,6 @@ func (a *App) applyTheme(themeName string) {
 	default: // "light"
 		a.app.Settings().SetTheme(ui.NewMaterialTheme(theme.VariantLight))
 	}
-
-	if err := a.db.SetTheme(normalizedTheme); err != nil {
-		a.showDialogError(err)
-	}
 }
 
 func (a *App) getTaskItem(id widget.ListItemID) (title, subtitle string, itemType models.ItemType) {
@@ -304,24 +535,111 @@ func (a *App) getTask(id widget.ListItemID) *models.Task {
 	return a.flatItems[id].Task
 }
 
+var (
+	errTaskAlreadyRunning = errors.New("a task is already running")
+	errNoTaskRunning      = errors.New("no task is running")
+	errProjectRequired    = errors.New("project name is required")
+	errTaskNotFound       = errors.New("task not found")
+	errInvalidTrimTime    = errors.New("trim time must be between the task's start and now")
+	errStartInFuture      = errors.New("start time must not be in the future")
+	errStartOverlaps      = errors.New("start time overlaps another task")
+	errNoPreviousTask     = errors.New("there is no previous task to start after")
+)
+
 func (a *App) startTask(projectName, description string) {
-	a.mu.Lock()
-	if a.currentTask != nil {
-		a.mu.Unlock()
+	estimate, err := models.ParseEstimate(a.estimateEntry.Text)
+	if err != nil {
+		a.showDialogError(err)
+		return
+	}
+	start, err := a.startTimeInput(time.Now().Round(0))
+	if err != nil {
+		a.showDialogError(err)
+		return
+	}
+	if _, err := a.beginTaskAt(start, projectName, description, estimate); err != nil {
+		a.showStartError(err)
+		return
+	}
+	a.startAtEntry.SetText("")
+	a.snapCheck.SetChecked(false)
+}
+
+// startTimeInput reads when the task about to start began from the Start
+// area: the end of the last saved task when snapping to it, or the offset or
+// clock time entered, or now.
+func (a *App) startTimeInput(now time.Time) (time.Time, error) {
+	if !a.snapCheck.Checked {
+		return models.ParseStartTime(a.startAtEntry.Text, now)
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var last time.Time
+	for _, t := range a.tasks {
+		if t.EndTime.After(last) {
+			last = t.EndTime
+		}
+	}
+	if last.IsZero() {
+		return time.Time{}, errNoPreviousTask
+	}
+	return last, nil
+}
+
+func (a *App) showStartError(err error) {
+	if errors.Is(err, errTaskAlreadyRunning) {
 		if os.Getenv("FYNE_TEST_SKIP_GUI") == "" {
 			dialog.ShowInformation("Error", "A task is already running", a.window)
 		}
 		return
 	}
+	a.showDialogError(err)
+}
 
+// beginTask starts a new running task, expected to take estimate unless that
+// is zero, and updates the timer UI. A running task is switched away from
+// when task switching is on. It is shared by the Start button and the local
+// API.
+func (a *App) beginTask(projectName, description string, estimate time.Duration) (*models.Task, error) {
+	return a.beginTaskAt(time.Now().Round(0), projectName, description, estimate)
+}
+
+// beginTaskAt is beginTask with the task starting at start, which may be in
+// the past but not inside another task. A running task being switched away
+// from ends at start.
+func (a *App) beginTaskAt(start time.Time, projectName, description string, estimate time.Duration) (*models.Task, error) {
+	a.mu.Lock()
 	if projectName == "" {
 		a.mu.Unlock()
-		a.showDialogError(fmt.Errorf("project name is required"))
-		return
+		return nil, errProjectRequired
+	}
+	running := a.currentTask
+	if running != nil && !a.prefs.SwitchTasks {
+		a.mu.Unlock()
+		return nil, errTaskAlreadyRunning
+	}
+	if running != nil && start.Before(running.StartTime) {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s has been running since %s", errStartOverlaps, running.ProjectName, running.StartTime.In(time.Local).Format("15:04"))
+	}
+	if err := validateTaskStart(start, time.Now(), a.tasks); err != nil {
+		a.mu.Unlock()
+		return nil, err
+	}
+	if running != nil {
+		a.mu.Unlock()
+		return a.switchTaskAt(start, projectName, description, estimate)
 	}
 
-	a.currentTask = models.NewTask(projectName, description)
+	task := models.NewTask(projectName, description)
+	task.StartTime, task.EndTime = start, start
+	task.Estimate = estimate
+	a.currentTask = task
 	a.idleSince = time.Time{}
+	a.lastActivity = time.Time{}
+	a.forgottenNotified = false
+	a.estimateNotified = false
+	a.startPomodoroUnlocked(task)
 	a.mu.Unlock()
 
 	a.updateButtonsState(true)
@@ -330,30 +648,78 @@ func (a *App) startTask(projectName, description string) {
 	// Sync entries
 	a.projectEntry.SetText(projectName)
 	a.descriptionEntry.SetText(description)
+	a.estimateEntry.SetText(formatEstimateInput(estimate))
+	a.showEstimateProgress(0, estimate)
 
 	if a.recordingIcon != nil {
 		a.recordingIcon.Show()
 	}
 
 	go a.updateTimer()
+	a.writeStateFile()
+	return task, nil
+}
+
+// switchTaskAt stops the running task at at and starts the new one at the
+// same instant, so the stored entries neither overlap nor leave a gap. A
+// running Pomodoro cycle carries over to the new task, ending any break.
+func (a *App) switchTaskAt(at time.Time, projectName, description string, estimate time.Duration) (*models.Task, error) {
+	a.mu.Lock()
+	if run := a.pomodoro; run != nil {
+		if run.Phase.IsBreak() {
+			run.SkipTo(models.PhaseWork, at)
+			run.pausedForBreak = false
+		}
+		run.project, run.description = projectName, description
+	}
+	a.mu.Unlock()
+
+	task, err := a.handOverAt(at, projectName, description)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	task.Estimate = estimate
+	a.mu.Unlock()
+	a.estimateEntry.SetText(formatEstimateInput(estimate))
+	a.showEstimateProgress(0, estimate)
+	a.writeStateFile()
+	return task, nil
 }
 
 func (a *App) stopTask() {
+	if forgotten, ok := a.forgottenTimer(time.Now().Round(0)); ok {
+		a.showForgottenDialog(forgotten)
+		return
+	}
+	if _, err := a.finishTask(); err != nil && !errors.Is(err, errNoTaskRunning) {
+		a.showDialogError(err)
+	}
+}
+
+// finishTask stops and saves the running task, then refreshes the UI. It is
+// shared by the Stop button and the local API.
+func (a *App) finishTask() (*models.Task, error) {
+	return a.finishTaskAt(time.Now().Round(0))
+}
+
+// finishTaskAt is finishTask with the task ending at end instead of now.
+func (a *App) finishTaskAt(end time.Time) (*models.Task, error) {
 	a.mu.Lock()
 	if a.currentTask == nil {
 		a.mu.Unlock()
-		return
+		return nil, errNoTaskRunning
 	}
 
-	a.currentTask.StopTask()
+	a.currentTask.StopTaskAt(end)
 	task := a.currentTask
 	a.currentTask = nil
+	a.pomodoro = nil
 	a.idleSince = time.Now().Round(0)
 	a.mu.Unlock()
 
 	if err := a.db.SaveTask(task); err != nil {
-		a.showDialogError(err)
-		return
+		return nil, err
 	}
 
 	a.refreshProjectSuggestions()
@@ -375,6 +741,8 @@ func (a *App) stopTask() {
 		a.taskList.Refresh()
 	}
 	a.refreshWeeklyChart()
+	a.refreshFlex()
+	a.refreshActivityView()
 
 	select {
 	case a.timerStop <- struct{}{}:
@@ -388,6 +756,12 @@ func (a *App) stopTask() {
 	if a.recordingIcon != nil {
 		a.recordingIcon.Hide()
 	}
+	// The estimate belonged to the stopped task.
+	a.estimateEntry.SetText("")
+	a.showEstimateProgress(0, 0)
+	a.showBudgetProgress(time.Now())
+	a.writeStateFile()
+	return task, nil
 }
 
 func (a *App) monitorIdle(ctx context.Context) {
@@ -404,6 +778,7 @@ func (a *App) monitorIdle(ctx context.Context) {
 			if a.checkIdle(&lastNotified) {
 				lastNotified = time.Now().Round(0)
 			}
+			a.checkForgotten(time.Now().Round(0))
 		}
 	}
 }
@@ -413,11 +788,12 @@ func (a *App) monitorIdle(ctx context.Context) {
 func (a *App) checkIdle(lastNotified *time.Time) bool {
 	a.mu.RLock()
 	task := a.currentTask
+	paused := task != nil && task.IsPaused()
 	idleSince := a.idleSince
 	threshold := time.Duration(a.idleThreshold) * time.Minute
 	a.mu.RUnlock()
 
-	if task == nil && !idleSince.IsZero() {
+	if (task == nil || paused) && !idleSince.IsZero() {
 		idleDuration := time.Since(idleSince)
 
 		rearmInterval := threshold
@@ -426,10 +802,11 @@ func (a *App) checkIdle(lastNotified *time.Time) bool {
 		}
 
 		if idleDuration >= threshold && time.Since(*lastNotified) >= rearmInterval {
-			a.app.SendNotification(fyne.NewNotification(
-				"TrackYou",
-				fmt.Sprintf("You've been idle for %d minutes. Don't forget to start a task!", int(idleDuration.Minutes())),
-			))
+			message := fmt.Sprintf("You've been idle for %d minutes. Don't forget to start a task!", int(idleDuration.Minutes()))
+			if paused {
+				message = fmt.Sprintf("%s has been paused for %d minutes. Don't forget to resume it!", task.ProjectName, int(idleDuration.Minutes()))
+			}
+			a.app.SendNotification(fyne.NewNotification("TrackYou", message))
 			return true
 		}
 	}
@@ -457,49 +834,252 @@ func (a *App) monitorMidnightRollover(ctx context.Context) {
 				fyne.Do(func() {
 					a.updateSummaryUI(false)
 				})
+				a.writeStateFile()
 			}
+			a.checkDigests(now)
 		}
 	}
 }
 
 func (a *App) updateButtonsState(running bool) {
-	if a.startButton == nil || a.stopButton == nil || a.projectEntry == nil || a.descriptionEntry == nil {
+	if a.startButton == nil || a.stopButton == nil || a.projectEntry == nil || a.descriptionEntry == nil || a.estimateEntry == nil || a.startAtEntry == nil {
 		return
 	}
+	a.mu.RLock()
+	switching := running && a.prefs.SwitchTasks
+	a.mu.RUnlock()
 	if running {
-		a.startButton.Disable()
 		a.stopButton.Enable()
+	} else {
+		a.stopButton.Disable()
+	}
+	// With task switching on, the Start area stays editable to pick the
+	// next task.
+	if running && !switching {
+		a.startButton.Disable()
 		a.projectEntry.Disable()
 		a.descriptionEntry.Disable()
+		a.estimateEntry.Disable()
+		a.startAtEntry.Disable()
+		a.snapCheck.Disable()
 	} else {
 		a.startButton.Enable()
-		a.stopButton.Disable()
 		a.projectEntry.Enable()
 		a.descriptionEntry.Enable()
+		a.estimateEntry.Enable()
+		a.snapCheck.Enable()
+		if !a.snapCheck.Checked {
+			a.startAtEntry.Enable()
+		}
+	}
+	if switching {
+		a.startButton.SetText("Switch")
+	} else {
+		a.startButton.SetText("Start")
+	}
+	if a.pauseButton != nil {
+		a.updatePauseButton(false)
+		if running {
+			a.pauseButton.Enable()
+		} else {
+			a.pauseButton.Disable()
+		}
+	}
+}
+
+// updatePauseButton offers Resume while paused and Pause otherwise.
+func (a *App) updatePauseButton(paused bool) {
+	if a.pauseButton == nil {
+		return
+	}
+	if paused {
+		a.pauseButton.SetText("Resume")
+		a.pauseButton.SetIcon(theme.MediaPlayIcon())
+	} else {
+		a.pauseButton.SetText("Pause")
+		a.pauseButton.SetIcon(theme.MediaPauseIcon())
+	}
+}
+
+// togglePause pauses the running task, or resumes it when paused.
+func (a *App) togglePause() {
+	a.mu.RLock()
+	paused := a.currentTask != nil && a.currentTask.IsPaused()
+	a.mu.RUnlock()
+
+	var err error
+	if paused {
+		err = a.resumeTask()
+	} else {
+		err = a.pauseTask()
+	}
+	if err != nil && !errors.Is(err, errNoTaskRunning) {
+		a.showDialogError(err)
 	}
 }
 
+// pauseTask stops counting time on the running task without ending it, so
+// a short interruption does not split the Log entry.
+func (a *App) pauseTask() error {
+	now := time.Now().Round(0)
+	a.mu.Lock()
+	if a.currentTask == nil {
+		a.mu.Unlock()
+		return errNoTaskRunning
+	}
+	a.currentTask.Pause(now)
+	a.idleSince = now
+	a.mu.Unlock()
+
+	a.updatePauseButton(true)
+	a.refreshTimerSummary()
+	return nil
+}
+
+// resumeTask starts counting time on a paused task again.
+func (a *App) resumeTask() error {
+	a.mu.Lock()
+	if a.currentTask == nil {
+		a.mu.Unlock()
+		return errNoTaskRunning
+	}
+	now := time.Now().Round(0)
+	a.currentTask.Resume(now)
+	a.endPomodoroBreakUnlocked(now)
+	a.idleSince = time.Time{}
+	a.mu.Unlock()
+
+	a.updatePauseButton(false)
+	a.refreshTimerSummary()
+	return nil
+}
+
+// refreshTimerSummary redraws today's total and republishes the state file
+// after the running task changes.
+func (a *App) refreshTimerSummary() {
+	if a.totalLabel != nil {
+		a.updateSummaryUI(false)
+	}
+	a.writeStateFile()
+}
+
+// continueTask starts a new task like a saved one, switching to it when
+// another task is running and task switching is on.
 func (a *App) continueTask(task *models.Task) {
-	a.startTask(task.ProjectName, task.Description)
+	// The estimate in the Start area was for another task.
+	if _, err := a.beginTask(task.ProjectName, task.Description, 0); err != nil {
+		a.showStartError(err)
+	}
 }
 
 // editTask updates a completed task's fields, persists the change, and refreshes all UI state.
 func (a *App) editTask(task *models.Task, projectName, description string, startTime, endTime time.Time) {
+	if err := a.applyTaskEdit(task, projectName, description, startTime, endTime); err != nil {
+		a.showDialogError(err)
+	}
+}
+
+// applyTaskEdit is editTask without the error dialog, for callers such as the
+// local API that report errors themselves.
+func (a *App) applyTaskEdit(task *models.Task, projectName, description string, startTime, endTime time.Time) error {
+	if len(task.Segments) > 0 {
+		// Moving a paused task's start or end moves its first and last segments.
+		segments := slices.Clone(task.Segments)
+		segments[0].Start = startTime
+		segments[len(segments)-1].End = endTime
+		return a.applySegmentsEdit(task, projectName, description, segments)
+	}
+
+	a.mu.Lock()
 	task.ProjectName = projectName
 	task.Description = description
 	task.StartTime = startTime
 	task.EndTime = endTime
 	task.UpdateDuration()
+	a.mu.Unlock()
+
+	return a.saveEditedTask(task)
+}
+
+// applySegmentsEdit replaces a completed task's active intervals, keeping
+// the task unchanged if they are invalid.
+func (a *App) applySegmentsEdit(task *models.Task, projectName, description string, segments []models.Segment) error {
+	edited := *task
+	edited.ProjectName = projectName
+	edited.Description = description
+	if err := edited.SetSegments(segments); err != nil {
+		return err
+	}
 
+	a.mu.Lock()
+	*task = edited
+	a.mu.Unlock()
+
+	return a.saveEditedTask(task)
+}
+
+// saveEditedTask persists an edited task and refreshes the views.
+func (a *App) saveEditedTask(task *models.Task) error {
 	if err := a.db.UpdateTask(task); err != nil {
-		a.showDialogError(err)
-		return
+		return err
 	}
 
 	a.mu.Lock()
 	a.updateTaskGroups()
 	a.mu.Unlock()
 
+	a.refreshTaskViews()
+	return nil
+}
+
+// addTask saves a completed task created outside the timer and shows it in
+// the Log.
+func (a *App) addTask(task *models.Task) error {
+	if err := a.db.SaveTask(task); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.tasks = append([]*models.Task{task}, a.tasks...)
+	a.updateTaskGroups()
+	a.mu.Unlock()
+
+	a.refreshTaskViews()
+	return nil
+}
+
+// removeTask deletes a saved task and drops it from the Log.
+func (a *App) removeTask(id int64) error {
+	if a.findTask(id) == nil {
+		return errTaskNotFound
+	}
+	if err := a.db.DeleteTask(id); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.tasks = slices.DeleteFunc(a.tasks, func(t *models.Task) bool { return t.ID == id })
+	a.updateTaskGroups()
+	a.mu.Unlock()
+
+	a.refreshTaskViews()
+	return nil
+}
+
+// findTask returns the in-memory task with the given ID, or nil.
+func (a *App) findTask(id int64) *models.Task {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, t := range a.tasks {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// refreshTaskViews redraws everything derived from the saved task list.
+func (a *App) refreshTaskViews() {
 	a.refreshProjectSuggestions()
 	a.updateSummaryUI(false)
 
@@ -507,6 +1087,9 @@ func (a *App) editTask(task *models.Task, projectName, description string, start
 		a.taskList.Refresh()
 	}
 	a.refreshWeeklyChart()
+	a.refreshFlex()
+	a.refreshActivityView()
+	a.writeStateFile()
 }
 
 // showEditTaskDialog opens a form dialog to edit a completed task's details.
@@ -538,13 +1121,31 @@ func (a *App) showEditTaskDialog(task *models.Task) {
 	durationEntry.SetPlaceHolder("1h30m")
 	durationEntry.SetText(originalDurationRounded.String())
 
+	// A paused task is edited through its segments; start, end and duration
+	// follow from them.
+	segmentsEntry := widget.NewMultiLineEntry()
+	segmentsEntry.SetText(formatSegmentsInput(task.Segments))
+	segmentsEntry.SetMinRowsVisible(3)
+	hasSegments := len(task.Segments) > 0
+
 	items := []*widget.FormItem{
 		widget.NewFormItem("Project", projectEntry),
 		widget.NewFormItem("Description", descEntry),
 		widget.NewFormItem("Time Format", widget.NewLabel(taskTimeLayout)),
-		widget.NewFormItem("Start Time", startEntry),
-		widget.NewFormItem("End Time", endEntry),
-		widget.NewFormItem("Duration", durationEntry),
+	}
+	if hasSegments {
+		segmentsItem := widget.NewFormItem("Segments", segmentsEntry)
+		segmentsItem.HintText = "One \"start" + segmentSeparator + "end\" per line"
+		items = append(items,
+			segmentsItem,
+			widget.NewFormItem("Duration", widget.NewLabel(originalDurationRounded.String())),
+		)
+	} else {
+		items = append(items,
+			widget.NewFormItem("Start Time", startEntry),
+			widget.NewFormItem("End Time", endEntry),
+			widget.NewFormItem("Duration", durationEntry),
+		)
 	}
 
 	formDialog := dialog.NewForm("Edit Task", "Save", "Cancel", items, func(confirmed bool) {
@@ -558,6 +1159,17 @@ func (a *App) showEditTaskDialog(task *models.Task) {
 			return
 		}
 
+		if hasSegments {
+			segments, err := parseSegmentsInput(segmentsEntry.Text)
+			if err == nil {
+				err = a.applySegmentsEdit(task, projectName, descEntry.Text, segments)
+			}
+			if err != nil {
+				a.showDialogError(err)
+			}
+			return
+		}
+
 		startTime, err := time.ParseInLocation(taskTimeLayout, startEntry.Text, time.Local)
 		if err != nil {
 			a.showDialogError(fmt.Errorf("invalid start time: %w", err))
@@ -611,6 +1223,12 @@ func (a *App) showSettings() {
 	a.mu.RLock()
 	currentThreshold := a.idleThreshold
 	currentGoal := a.workdayLength
+	currentTheme := a.prefs.Theme
+	apiEnabled := a.prefs.APIEnabled
+	apiPort := a.prefs.APIPort
+	maxTimerHours := a.prefs.MaxTimerHours
+	endOfDay := a.prefs.EndOfDay
+	prefs := a.prefs
 	a.mu.RUnlock()
 
 	thresholdEntry := widget.NewEntry()
@@ -619,7 +1237,136 @@ func (a *App) showSettings() {
 	goalEntry := widget.NewEntry()
 	goalEntry.SetText(fmt.Sprintf("%.1f", currentGoal))
 
-	currentTheme, _ := a.db.GetTheme()
+	maxTimerEntry := widget.NewEntry()
+	maxTimerEntry.SetText(strconv.FormatFloat(maxTimerHours, 'f', -1, 64))
+
+	endOfDayEntry := widget.NewEntry()
+	endOfDayEntry.SetPlaceHolder("HH:MM")
+	endOfDayEntry.SetText(endOfDay)
+
+	// Empty targets follow the workday goal from Monday to Friday.
+	targetsEntry := widget.NewEntry()
+	targetsEntry.SetPlaceHolder(models.FormatWeekdayTargets(models.DefaultWeekdays(time.Duration(currentGoal * float64(time.Hour)))))
+	if prefs.Sources[config.KeyTargets] != config.SourceDefault {
+		targetsEntry.SetText(models.FormatWeekdayTargets(prefs.Targets))
+	}
+
+	holidaysFileEntry := widget.NewEntry()
+	holidaysFileEntry.SetPlaceHolder("/path/to/holidays.ics")
+	holidaysFileEntry.SetText(prefs.HolidaysFile)
+
+	// Empty alerts turn budget notifications off.
+	budgetAlertsEntry := widget.NewEntry()
+	budgetAlertsEntry.SetPlaceHolder("Off")
+	budgetAlertsEntry.SetText(models.FormatBudgetAlerts(prefs.BudgetAlerts))
+
+	// An empty digest time follows the end of day.
+	digestCheck := widget.NewCheck("Notify with the day's totals", nil)
+	digestCheck.SetChecked(prefs.Digest.Enabled)
+	digestTimeEntry := widget.NewEntry()
+	digestTimeEntry.SetPlaceHolder(endOfDay)
+	if endOfDay == "" {
+		digestTimeEntry.SetPlaceHolder(time.Time{}.Add(models.DefaultDigestTime).Format("15:04"))
+	}
+	digestTimeEntry.SetText(prefs.Digest.Time)
+	digestWeeklyCheck := widget.NewCheck("Also the week's on its last workday", nil)
+	digestWeeklyCheck.SetChecked(prefs.Digest.Weekly)
+	reportDirEntry := widget.NewEntry()
+	reportDirEntry.SetPlaceHolder("Off")
+	reportDirEntry.SetText(prefs.Digest.ReportDir)
+	// Capitalized labels for display, format names for storage
+	reportFormats := map[string]string{
+		"Markdown": models.ReportMarkdown,
+		"HTML":     models.ReportHTML,
+	}
+	reportFormatSelect := widget.NewSelect([]string{"Markdown", "HTML"}, nil)
+	reportFormatSelect.SetSelected("Markdown")
+	for label, format := range reportFormats {
+		if format == prefs.Digest.ReportFormat {
+			reportFormatSelect.SetSelected(label)
+		}
+	}
+	timeZoneEntry := widget.NewEntry()
+	timeZoneEntry.SetPlaceHolder("Local")
+	timeZoneEntry.SetText(prefs.Digest.TimeZone)
+
+	weekStartSelect := widget.NewSelect([]string{
+		time.Monday.String(), time.Tuesday.String(), time.Wednesday.String(), time.Thursday.String(),
+		time.Friday.String(), time.Saturday.String(), time.Sunday.String(),
+	}, nil)
+	weekStartSelect.SetSelected(prefs.WeekStart.String())
+
+	switchCheck := widget.NewCheck("Starting a task stops the running one", nil)
+	switchCheck.SetChecked(prefs.SwitchTasks)
+
+	roundingEntry := widget.NewEntry()
+	roundingEntry.SetText(strconv.Itoa(prefs.RoundingMinutes))
+
+	// Capitalized labels for display, mode names for storage
+	roundingModes := map[string]string{
+		"Nearest": models.RoundNearest,
+		"Up":      models.RoundUp,
+		"Down":    models.RoundDown,
+	}
+	roundingModeSelect := widget.NewSelect([]string{"Nearest", "Up", "Down"}, nil)
+	roundingScopes := map[string]string{
+		"Entry": models.RoundPerEntry,
+		"Day":   models.RoundPerDay,
+	}
+	roundingApplySelect := widget.NewSelect([]string{"Entry", "Day"}, nil)
+	roundingModeSelect.SetSelected("Nearest")
+	roundingApplySelect.SetSelected("Entry")
+	for label, mode := range roundingModes {
+		if mode == prefs.RoundingMode {
+			roundingModeSelect.SetSelected(label)
+		}
+	}
+	for label, scope := range roundingScopes {
+		if scope == prefs.RoundingApply {
+			roundingApplySelect.SetSelected(label)
+		}
+	}
+
+	flexStartEntry := widget.NewEntry()
+	flexStartEntry.SetPlaceHolder("YYYY-MM-DD")
+	flexStartEntry.SetText(prefs.FlexStart)
+	flexOpeningEntry := widget.NewEntry()
+	flexOpeningEntry.SetText(strconv.FormatFloat(prefs.FlexOpeningBalance, 'f', -1, 64))
+
+	pomodoroCheck := widget.NewCheck("Enabled", nil)
+	pomodoroCheck.SetChecked(prefs.PomodoroEnabled)
+
+	pomodoroWorkEntry := widget.NewEntry()
+	pomodoroWorkEntry.SetText(strconv.Itoa(prefs.PomodoroWork))
+	pomodoroShortEntry := widget.NewEntry()
+	pomodoroShortEntry.SetText(strconv.Itoa(prefs.PomodoroShortBreak))
+	pomodoroLongEntry := widget.NewEntry()
+	pomodoroLongEntry.SetText(strconv.Itoa(prefs.PomodoroLongBreak))
+
+	// Capitalized labels for display, mode names for storage
+	breakModes := map[string]string{
+		"Excluded gaps": database.PomodoroBreaksGap,
+		"Break project": database.PomodoroBreaksProject,
+	}
+	breaksSelect := widget.NewSelect([]string{"Excluded gaps", "Break project"}, nil)
+	breaksSelect.SetSelected("Excluded gaps")
+	if prefs.PomodoroBreaks == database.PomodoroBreaksProject {
+		breaksSelect.SetSelected("Break project")
+	}
+
+	sleepPolicies := map[string]string{
+		"Ask":           database.SleepPolicyAsk,
+		"Pause timer":   database.SleepPolicyPause,
+		"Keep counting": database.SleepPolicyIgnore,
+	}
+	sleepSelect := widget.NewSelect([]string{"Ask", "Pause timer", "Keep counting"}, nil)
+	sleepSelect.SetSelected("Ask")
+	for label, policy := range sleepPolicies {
+		if policy == prefs.SleepPolicy {
+			sleepSelect.SetSelected(label)
+		}
+	}
+
 	themeSelect := widget.NewSelect([]string{"Light", "Dark", "System"}, nil)
 	// Capitalize for display, lower case for storage
 	themeDisplay := "Light"
@@ -631,15 +1378,73 @@ func (a *App) showSettings() {
 	}
 	themeSelect.SetSelected(themeDisplay)
 
+	apiCheck := widget.NewCheck("Enabled", nil)
+	apiCheck.SetChecked(apiEnabled)
+
+	apiPortEntry := widget.NewEntry()
+	apiPortEntry.SetText(strconv.Itoa(apiPort))
+
+	copyTokenButton := widget.NewButtonWithIcon("Copy Token", theme.ContentCopyIcon(), func() {
+		token, err := a.ensureAPIToken()
+		if err != nil {
+			a.showDialogError(err)
+			return
+		}
+		a.app.Clipboard().SetContent(token)
+	})
+
+	// Values set in config.toml win over the database, so they are shown
+	// read-only and edited in the file instead.
+	preferenceItem := func(label, key string, w fyne.Disableable) *widget.FormItem {
+		if a.preferenceFromFile(key) {
+			w.Disable()
+		}
+		item := widget.NewFormItem(label, w.(fyne.CanvasObject))
+		item.HintText = a.preferenceHint(key)
+		return item
+	}
+
 	items := []*widget.FormItem{
-		widget.NewFormItem("Idle Threshold (min)", thresholdEntry),
-		widget.NewFormItem("Workday Goal (hours)", goalEntry),
-		widget.NewFormItem("Theme", themeSelect),
+		preferenceItem("Idle Threshold (min)", config.KeyIdleThreshold, thresholdEntry),
+		preferenceItem("Workday Goal (hours)", config.KeyWorkdayLength, goalEntry),
+		preferenceItem("Daily Targets (h, Mon–Sun)", config.KeyTargets, targetsEntry),
+		preferenceItem("Holidays File (.ics)", config.KeyHolidaysFile, holidaysFileEntry),
+		preferenceItem("Budget Alerts (%)", config.KeyBudgetAlerts, budgetAlertsEntry),
+		preferenceItem("Max Timer Length (hours, 0 = off)", config.KeyMaxTimerHours, maxTimerEntry),
+		preferenceItem("End of Day (empty = off)", config.KeyEndOfDay, endOfDayEntry),
+		preferenceItem("Digests", config.KeyDigestEnabled, digestCheck),
+		preferenceItem("Digest Time", config.KeyDigestTime, digestTimeEntry),
+		preferenceItem("Weekly Digest", config.KeyDigestWeekly, digestWeeklyCheck),
+		preferenceItem("Report Folder", config.KeyDigestReportDir, reportDirEntry),
+		preferenceItem("Report Format", config.KeyDigestReportFormat, reportFormatSelect),
+		preferenceItem("Time Zone (e.g. Europe/Berlin)", config.KeyDigestTimeZone, timeZoneEntry),
+		preferenceItem("After Sleep or Lock", config.KeySleepPolicy, sleepSelect),
+		preferenceItem("Task Switching", config.KeySwitchTasks, switchCheck),
+		preferenceItem("First Day of Week", config.KeyWeekStart, weekStartSelect),
+		preferenceItem("Rounding (min, 0 = off)", config.KeyRoundingMinutes, roundingEntry),
+		preferenceItem("Round To", config.KeyRoundingMode, roundingModeSelect),
+		preferenceItem("Round Each", config.KeyRoundingApply, roundingApplySelect),
+		preferenceItem("Flex-Time Start (empty = off)", config.KeyFlexStart, flexStartEntry),
+		preferenceItem("Opening Balance (hours)", config.KeyFlexOpeningBalance, flexOpeningEntry),
+		preferenceItem("Pomodoro Mode", config.KeyPomodoroEnabled, pomodoroCheck),
+		preferenceItem("Pomodoro Work (min)", config.KeyPomodoroWork, pomodoroWorkEntry),
+		preferenceItem("Short Break (min)", config.KeyPomodoroShortBreak, pomodoroShortEntry),
+		preferenceItem("Long Break (min)", config.KeyPomodoroLongBreak, pomodoroLongEntry),
+		preferenceItem("Breaks", config.KeyPomodoroBreaks, breaksSelect),
+		preferenceItem("Theme", config.KeyTheme, themeSelect),
+		preferenceItem("Local API", config.KeyAPIEnabled, apiCheck),
+		preferenceItem("API Port", config.KeyAPIPort, apiPortEntry),
+		widget.NewFormItem("API Token", copyTokenButton),
 	}
 
 	dialog.ShowForm("Settings", "Save", "Cancel", items, func(confirmed bool) {
-		if confirmed {
-			// Update Threshold
+		if !confirmed {
+			return
+		}
+		defer a.reloadPreferences()
+
+		// Update Threshold
+		if !thresholdEntry.Disabled() {
 			val, err := strconv.Atoi(thresholdEntry.Text)
 			if err != nil || val < 1 {
 				a.showDialogError(fmt.Errorf("invalid threshold value"))
@@ -647,42 +1452,213 @@ func (a *App) showSettings() {
 			}
 			if err := a.db.SetIdleThreshold(val); err != nil {
 				a.showDialogError(err)
-			} else {
-				a.mu.Lock()
-				a.idleThreshold = val
-				a.mu.Unlock()
 			}
+		}
 
-			// Update Workday Goal
+		// Update Workday Goal
+		if !goalEntry.Disabled() {
 			goalVal, err := strconv.ParseFloat(goalEntry.Text, 64)
 			if err != nil || goalVal <= 0 || math.IsNaN(goalVal) || math.IsInf(goalVal, 0) {
 				a.showDialogError(fmt.Errorf("invalid workday goal value"))
 				return
 			}
-
-			// Save to DB first to maintain consistency
 			if err := a.db.SetWorkdayLength(goalVal); err != nil {
 				a.showDialogError(err)
-			} else {
-				// Compute total before acquiring Lock to avoid deadlock if calculateTotalDurationToday was called
-				// but calculateTotalDurationToday is safe to call now because we are not holding any lock.
-				total := a.calculateTotalDurationToday()
+			}
+		}
 
-				a.mu.Lock()
-				a.workdayLength = goalVal
-				// Reset goalReachedToday if we increased the goal and it was reached but no longer is
-				if total.Hours() < goalVal {
-					a.goalReachedToday = false
+		// Update Daily Targets and Holidays
+		if !targetsEntry.Disabled() {
+			targets := strings.TrimSpace(targetsEntry.Text)
+			if targets != "" {
+				weekdays, err := models.ParseWeekdayTargets(targets)
+				if err != nil {
+					a.showDialogError(err)
+					return
 				}
-				a.mu.Unlock()
+				targets = models.FormatWeekdayTargets(weekdays)
+			}
+			if err := a.db.SetWeekdayTargets(targets); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+		if !holidaysFileEntry.Disabled() {
+			if err := a.db.SetHolidaysFile(strings.TrimSpace(holidaysFileEntry.Text)); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
 
-				// Refresh UI immediately
-				fyne.Do(func() {
-					a.updateSummaryUI(false)
-				})
+		// Update Budget Alerts
+		if !budgetAlertsEntry.Disabled() {
+			alerts, err := models.ParseBudgetAlerts(budgetAlertsEntry.Text)
+			if err == nil {
+				err = a.db.SetBudgetAlerts(alerts)
+			}
+			if err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+
+		// Update Forgotten Timer Rules
+		if !maxTimerEntry.Disabled() {
+			hours, err := strconv.ParseFloat(strings.TrimSpace(maxTimerEntry.Text), 64)
+			if err != nil {
+				a.showDialogError(fmt.Errorf("invalid max timer length value"))
+				return
+			}
+			if err := a.db.SetMaxTimerHours(hours); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+		if !endOfDayEntry.Disabled() {
+			if err := a.db.SetEndOfDay(strings.TrimSpace(endOfDayEntry.Text)); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+
+		// Update Sleep Policy
+		if !sleepSelect.Disabled() {
+			if err := a.db.SetSleepPolicy(sleepPolicies[sleepSelect.Selected]); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+
+		// Update Task Switching
+		if !switchCheck.Disabled() {
+			if err := a.db.SetSwitchTasks(switchCheck.Checked); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+
+		// Update First Day of Week
+		if !weekStartSelect.Disabled() {
+			day, err := models.ParseWeekday(weekStartSelect.Selected)
+			if err == nil {
+				err = a.db.SetWeekStart(day)
 			}
+			if err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+
+		// Update Digests
+		digestWidgets := []fyne.Disableable{digestCheck, digestTimeEntry, digestWeeklyCheck, reportDirEntry, reportFormatSelect, timeZoneEntry}
+		if slices.ContainsFunc(digestWidgets, func(w fyne.Disableable) bool { return !w.Disabled() }) {
+			// Values set in config.toml keep their saved values underneath.
+			digest, _ := a.db.GetDigest()
+			if !digestCheck.Disabled() {
+				digest.Enabled = digestCheck.Checked
+			}
+			if !digestTimeEntry.Disabled() {
+				digest.Time = strings.TrimSpace(digestTimeEntry.Text)
+			}
+			if !digestWeeklyCheck.Disabled() {
+				digest.Weekly = digestWeeklyCheck.Checked
+			}
+			if !reportDirEntry.Disabled() {
+				digest.ReportDir = strings.TrimSpace(reportDirEntry.Text)
+			}
+			if !reportFormatSelect.Disabled() {
+				digest.ReportFormat = reportFormats[reportFormatSelect.Selected]
+			}
+			if !timeZoneEntry.Disabled() {
+				digest.TimeZone = strings.TrimSpace(timeZoneEntry.Text)
+			}
+			if err := a.db.SetDigest(digest); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+
+		// Update Rounding
+		if !roundingEntry.Disabled() || !roundingModeSelect.Disabled() || !roundingApplySelect.Disabled() {
+			// Values set in config.toml keep their saved values underneath.
+			minutes, mode, per, _ := a.db.GetRounding()
+			if !roundingEntry.Disabled() {
+				val, err := strconv.Atoi(strings.TrimSpace(roundingEntry.Text))
+				if err != nil || val < 0 {
+					a.showDialogError(fmt.Errorf("invalid rounding value"))
+					return
+				}
+				minutes = val
+			}
+			if !roundingModeSelect.Disabled() {
+				mode = roundingModes[roundingModeSelect.Selected]
+			}
+			if !roundingApplySelect.Disabled() {
+				per = roundingScopes[roundingApplySelect.Selected]
+			}
+			if err := a.db.SetRounding(minutes, mode, per); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+
+		// Update Flex-Time Balance
+		if !flexStartEntry.Disabled() || !flexOpeningEntry.Disabled() {
+			// Values set in config.toml keep their saved values underneath.
+			start, opening, _ := a.db.GetFlex()
+			if !flexStartEntry.Disabled() {
+				start = strings.TrimSpace(flexStartEntry.Text)
+			}
+			if !flexOpeningEntry.Disabled() {
+				val, err := strconv.ParseFloat(strings.TrimSpace(flexOpeningEntry.Text), 64)
+				if err != nil {
+					a.showDialogError(fmt.Errorf("invalid opening balance value"))
+					return
+				}
+				opening = val
+			}
+			if err := a.db.SetFlex(start, opening); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
 
-			// Update Theme
+		// Update Pomodoro Mode
+		if !pomodoroCheck.Disabled() {
+			if err := a.db.SetPomodoroEnabled(pomodoroCheck.Checked); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+		if !pomodoroWorkEntry.Disabled() || !pomodoroShortEntry.Disabled() || !pomodoroLongEntry.Disabled() {
+			// Lengths set in config.toml keep their saved values underneath.
+			var lengths [3]int
+			lengths[0], lengths[1], lengths[2], _ = a.db.GetPomodoroLengths()
+			for i, entry := range []*widget.Entry{pomodoroWorkEntry, pomodoroShortEntry, pomodoroLongEntry} {
+				if entry.Disabled() {
+					continue
+				}
+				val, err := strconv.Atoi(strings.TrimSpace(entry.Text))
+				if err != nil || val < 1 {
+					a.showDialogError(fmt.Errorf("invalid Pomodoro length value"))
+					return
+				}
+				lengths[i] = val
+			}
+			if err := a.db.SetPomodoroLengths(lengths[0], lengths[1], lengths[2]); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+		if !breaksSelect.Disabled() {
+			if err := a.db.SetPomodoroBreaks(breakModes[breaksSelect.Selected]); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+
+		// Update Theme
+		if !themeSelect.Disabled() {
 			newTheme := "light"
 			switch themeSelect.Selected {
 			case "Dark":
@@ -692,6 +1668,25 @@ func (a *App) showSettings() {
 			}
 			a.applyTheme(newTheme)
 		}
+
+		// Update Local API
+		if !apiPortEntry.Disabled() {
+			portVal, err := strconv.Atoi(strings.TrimSpace(apiPortEntry.Text))
+			if err != nil {
+				a.showDialogError(fmt.Errorf("invalid API port value"))
+				return
+			}
+			if err := a.db.SetAPIPort(portVal); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
+		if !apiCheck.Disabled() {
+			if err := a.db.SetAPIEnabled(apiCheck.Checked); err != nil {
+				a.showDialogError(err)
+				return
+			}
+		}
 	}, a.window)
 }
 
@@ -704,6 +1699,17 @@ func (a *App) makeUI() fyne.CanvasObject {
 	a.projectEntry.SetPlaceHolder("Project")
 	a.descriptionEntry = widget.NewEntry()
 	a.descriptionEntry.SetPlaceHolder("What are you working on?")
+	a.estimateEntry = widget.NewEntry()
+	a.estimateEntry.SetPlaceHolder("Estimate, e.g. 45m (optional)")
+	a.startAtEntry = widget.NewEntry()
+	a.startAtEntry.SetPlaceHolder("Started, e.g. -20m or 09:15 (optional)")
+	a.snapCheck = widget.NewCheck("After last task", func(checked bool) {
+		if checked {
+			a.startAtEntry.Disable()
+		} else {
+			a.startAtEntry.Enable()
+		}
+	})
 
 	a.refreshProjectSuggestions()
 
@@ -715,11 +1721,29 @@ func (a *App) makeUI() fyne.CanvasObject {
 	a.totalLabel = widget.NewLabel("Total Today: 0s")
 	a.totalLabel.Alignment = fyne.TextAlignCenter
 
+	// Flex-time balance, shown once a start date is set
+	a.flexLabel = widget.NewLabel("")
+	a.flexLabel.Hide()
+
 	// Recording Icon (Red Circle)
 	a.recordingIcon = canvas.NewCircle(color.RGBA{R: 255, G: 0, B: 0, A: 255})
 	a.recordingIcon.Resize(fyne.NewSize(12, 12))
 	a.recordingIcon.Hide()
 
+	// Share of the running task's estimate used so far
+	a.estimateBar = widget.NewProgressBar()
+	a.estimateBar.Hide()
+
+	// Budget of the project entered or running, shown for projects with one
+	a.budgetBar = widget.NewProgressBar()
+	a.budgetBar.Hide()
+	a.budgetLabel = widget.NewLabel("")
+	a.budgetLabel.Wrapping = fyne.TextWrapWord
+	a.budgetLabel.Hide()
+	a.projectEntry.OnChanged = func(string) {
+		a.showBudgetProgress(time.Now())
+	}
+
 	timerContainer := container.NewVBox(
 		container.NewHBox(
 			layout.NewSpacer(),
@@ -727,7 +1751,8 @@ func (a *App) makeUI() fyne.CanvasObject {
 			a.timerLabel,
 			layout.NewSpacer(),
 		),
-		a.totalLabel,
+		a.estimateBar,
+		container.NewHBox(layout.NewSpacer(), a.totalLabel, a.flexLabel, layout.NewSpacer()),
 	)
 
 	// Buttons
@@ -742,19 +1767,32 @@ func (a *App) makeUI() fyne.CanvasObject {
 	a.stopButton.Importance = widget.DangerImportance
 	a.stopButton.Disable()
 
+	a.pauseButton = widget.NewButtonWithIcon("Pause", theme.MediaPauseIcon(), func() {
+		a.togglePause()
+	})
+	a.pauseButton.Disable()
+
 	inputContainer := container.NewVBox(
 		a.projectEntry,
+		a.budgetBar,
+		a.budgetLabel,
 		a.descriptionEntry,
+		a.estimateEntry,
+		container.NewBorder(nil, nil, nil, a.snapCheck, a.startAtEntry),
 		timerContainer,
-		container.NewGridWithColumns(2, a.startButton, a.stopButton),
+		container.NewGridWithColumns(3, a.startButton, a.pauseButton, a.stopButton),
 	)
 
 	inputCard := widget.NewCard("New Task", "", container.NewPadded(inputContainer))
 
-	// Weekly Chart (lives in the Summary tab)
-	a.weeklyCard = widget.NewCard("This Week by Project", "",
-		a.makeWeeklyCardContent(nil),
+	// Summary Chart (lives in the Summary tab)
+	a.weeklyCard = widget.NewCard("Time by Project", "",
+		a.makeWeeklyCardContent(ui.MakeSummaryChartContent(models.Summary{}, nil)),
 	)
+	a.roundedCheck = widget.NewCheck("Rounded totals", func(bool) {
+		a.refreshWeeklyChart()
+	})
+	periodPicker := a.makePeriodPicker()
 
 	// Task List
 	a.taskList = widget.NewList(
@@ -855,70 +1893,89 @@ func (a *App) makeUI() fyne.CanvasObject {
 		},
 	)
 
-	tabs := container.NewAppTabs(
+	a.tabs = container.NewAppTabs(
 		container.NewTabItemWithIcon("Log", theme.ListIcon(), container.NewPadded(a.taskList)),
-		container.NewTabItemWithIcon("Summary", theme.ViewRestoreIcon(), container.NewPadded(a.weeklyCard)),
+		container.NewTabItemWithIcon("Summary", theme.ViewRestoreIcon(), container.NewPadded(
+			container.NewBorder(periodPicker, nil, nil, nil, a.weeklyCard),
+		)),
+		container.NewTabItemWithIcon("Flex", theme.HistoryIcon(), container.NewPadded(a.makeFlexView())),
+		container.NewTabItemWithIcon("Absences", theme.CalendarIcon(), container.NewPadded(a.makeAbsencesView())),
+		container.NewTabItemWithIcon("Activity", theme.GridIcon(), container.NewPadded(a.makeActivityView())),
 	)
 
 	mainContent := container.NewBorder(
 		container.NewVBox(topBar, inputCard), // Top
 		nil, nil, nil,
-		tabs, // Center
+		a.tabs, // Center
 	)
 
 	return mainContent
 }
 
+// runCLI executes a command-line subcommand instead of starting the GUI.
+func runCLI(opts cli.Options, args []string) int {
+	dbPath, _, err := opts.Resolve()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get database path: %v\n", err)
+		return 1
+	}
+	return cli.Run(cli.Env{DBPath: dbPath, Stdout: os.Stdout, Stderr: os.Stderr}, args)
+}
+
 func main() {
+	opts, args, err := cli.ParseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "trackyou: %v\n", err)
+		os.Exit(2)
+	}
+	if len(args) > 0 && cli.IsCommand(args[0]) {
+		os.Exit(runCLI(opts, args))
+	}
+
 	myApp := app.NewWithID(appID)
 	configureApplication(myApp)
 	window := myApp.NewWindow("TrackYou")
 	window.SetIcon(myApp.Icon())
 
 	// Initialize DB
-	dbPath, err := database.GetDefaultDBPath()
+	dbPath, workspace, err := opts.Resolve()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to get database path: %v\n", err)
 		return
 	}
-	db, err := database.NewDB(dbPath)
+	db, err := openDatabase(dbPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
-		return
-	}
-	if err := db.InitDB(); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		fmt.Fprintln(os.Stderr, err)
 		return
 	}
 
-	// Load Idle Threshold
-	idleThreshold, err := db.GetIdleThreshold()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load idle threshold: %v\n", err)
-		idleThreshold = 5
-	}
-
-	// Load Workday Goal
-	workdayGoal, err := db.GetWorkdayLength()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load workday goal: %v\n", err)
-		workdayGoal = 8.0
-	}
-
 	idleCtx, idleCancel := context.WithCancel(context.Background())
 
 	application := &App{
-		window:        window,
-		app:           myApp,
-		db:            db,
-		tasks:         make([]*models.Task, 0),
-		timerStop:     make(chan struct{}),
-		idleThreshold: idleThreshold,
-		workdayLength: workdayGoal,
-		idleSince:     time.Now().Round(0), // Assume idle from start
-		idleCtx:       idleCtx,
-		idleCancel:    idleCancel,
+		window:     window,
+		app:        myApp,
+		db:         db,
+		dbPath:     dbPath,
+		workspace:  workspace,
+		tasks:      make([]*models.Task, 0),
+		timerStop:  make(chan struct{}),
+		idleSince:  time.Now().Round(0), // Assume idle from start
+		idleCtx:    idleCtx,
+		idleCancel: idleCancel,
+		statePath:  state.PathForDB(dbPath),
+	}
+	application.updateWindowTitle()
+
+	// Load idle threshold, workday goal and theme, preferring config.toml
+	if configPath, err := config.DefaultPath(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get config path: %v\n", err)
+	} else {
+		application.configPath = configPath
+		if err := application.loadConfigFile(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
 	}
+	application.loadPreferences()
 
 	// Set up tray icon if supported
 	if desk, ok := myApp.(desktop.App); ok {
@@ -937,41 +1994,63 @@ func main() {
 		desk.SetSystemTrayIcon(myApp.Icon())
 	}
 
+	if source, err := idle.Detect(); err != nil {
+		fmt.Fprintf(os.Stderr, "Away detection disabled: %v\n", err)
+	} else {
+		application.idleSource = source
+	}
+
 	go application.monitorIdle(idleCtx)
-	go application.monitorMidnightRollover(idleCtx)
+	go application.monitorAway(idleCtx)
 
-	// Load Theme
-	savedTheme, err := db.GetTheme()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load theme preference: %v\n", err)
-		savedTheme = "light"
+	if watcher, err := idle.WatchSleep(); err != nil {
+		fmt.Fprintf(os.Stderr, "Suspend and lock events unavailable: %v\n", err)
+	} else {
+		application.sleepWatcher = watcher
+		go application.monitorSleep(idleCtx)
 	}
-	application.applyTheme(savedTheme)
+	go application.monitorMidnightRollover(idleCtx)
+	application.watchConfig(idleCtx)
 
 	// --- UI Construction ---
 	mainContent := application.makeUI()
 
 	// Load Tasks
-	tasks, err := application.db.GetTasks()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load tasks: %v\n", err)
+	if err := application.loadTasks(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		return
 	}
-	application.mu.Lock()
-	application.tasks = tasks
-	application.updateTaskGroups()
-	application.mu.Unlock()
 	application.refreshProjectSuggestions()
 
 	// Initial goal check and UI update
 	application.updateSummaryUI(true)
 	application.refreshWeeklyChart()
+	application.refreshFlex()
+	application.refreshAbsencesView()
+	application.refreshActivityView()
+	application.writeStateFile()
+
+	if err := application.startAPIServer(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start local API: %v\n", err)
+	}
 
 	// --- Menu Construction ---
 	settingsMenu := fyne.NewMenu("File",
 		fyne.NewMenuItem("Settings", func() {
 			application.showSettings()
 		}),
+		fyne.NewMenuItem("Holidays…", func() {
+			application.showHolidaysDialog()
+		}),
+		fyne.NewMenuItem("Budgets…", func() {
+			application.showBudgetsDialog()
+		}),
+		fyne.NewMenuItem("Rounding Rules…", func() {
+			application.showRoundingDialog()
+		}),
+		fyne.NewMenuItem("Switch Workspace…", func() {
+			application.showWorkspaceSwitcher()
+		}),
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("Quit", func() {
 			application.idleCancel()
@@ -990,4 +2069,14 @@ func main() {
 	window.Resize(fyne.NewSize(500, 700)) // Portrait mobile-ish size
 	window.ShowAndRun()
 	application.idleCancel()
+	application.stopAPIServer()
+	if application.sleepWatcher != nil {
+		application.sleepWatcher.Close()
+	}
+
+	// A running task is not saved on quit, so the prompt must stop showing it.
+	application.mu.Lock()
+	application.currentTask = nil
+	application.mu.Unlock()
+	application.writeStateFile()
 }
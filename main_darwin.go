@@ -9,6 +9,8 @@ import (
 	"syscall"
 	"time"
 
+	"trackyou/cli"
+
 	"golang.org/x/term"
 )
 
@@ -51,6 +53,11 @@ func formatWaitStatus(status syscall.WaitStatus) string {
 // binary as a subprocess whose stdin is closed or redirected to a pipe/null,
 // so IsTerminal returns false and the self-detach is correctly skipped.
 func init() {
+	// Command-line subcommands print to the terminal and must never detach.
+	if cli.IsCommandLine(os.Args[1:]) {
+		return
+	}
+
 	isInteractiveTTY := term.IsTerminal(int(os.Stdin.Fd()))
 
 	if !shouldDetachForInteractiveLaunch(isInteractiveTTY, os.Getenv(detachMarkerEnv), os.Getenv(detachEnabledEnv)) {
@@ -1,15 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"trackyou/assets"
+	"trackyou/config"
 	"trackyou/database"
+	"trackyou/idle"
 	"trackyou/models"
+	"trackyou/state"
+	"trackyou/ui"
 
 	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
 )
 
 func TestAppIconResource_IsEmbedded(t *testing.T) {
@@ -447,6 +457,305 @@ func TestIntegration_WeeklyChart_UpdatesAfterStopTask(t *testing.T) {
 	}
 }
 
+// summaryTexts returns the texts of the labels shown in the Summary tab.
+func summaryTexts(app *App) []string {
+	var texts []string
+	for _, obj := range test.LaidOutObjects(app.weeklyCard.Content) {
+		if label, ok := obj.(*widget.Label); ok {
+			texts = append(texts, label.Text)
+		}
+	}
+	return texts
+}
+
+func TestIntegration_SummaryDrillDown(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	y, m, d := time.Now().Date()
+	start := time.Date(y, m, d, 9, 0, 0, 0, time.Local)
+	app.tasks = []*models.Task{
+		{ProjectName: "Acme", Description: "API", StartTime: start, EndTime: start.Add(time.Hour), Duration: time.Hour},
+		{ProjectName: "Acme", Description: "API", StartTime: start.Add(time.Hour), EndTime: start.Add(90 * time.Minute), Duration: 30 * time.Minute},
+		{ProjectName: "Acme", Description: "Design", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(150 * time.Minute), Duration: 30 * time.Minute},
+		{ProjectName: "Other", Description: "API", StartTime: start.Add(150 * time.Minute), EndTime: start.Add(3 * time.Hour), Duration: 30 * time.Minute},
+	}
+	app.periodSelect.SetSelected("Day")
+
+	app.openSummaryProject("Acme")
+	texts := summaryTexts(app)
+	if !slices.Contains(texts, "Acme") || !slices.Contains(texts, "API") || !slices.Contains(texts, "1h 30m · 2 entries") || !slices.Contains(texts, "30m · 1 entry") {
+		t.Fatalf("expected Acme's descriptions, got %q", texts)
+	}
+	if slices.Contains(texts, "Other") {
+		t.Error("expected other projects left out of the breakdown")
+	}
+
+	app.openSummaryDescription("API")
+	texts = summaryTexts(app)
+	if !slices.Contains(texts, "Acme › API") || !slices.Contains(texts, "1h 0m") || !slices.Contains(texts, "30m") {
+		t.Fatalf("expected the API entries, got %q", texts)
+	}
+
+	// A new period keeps the drill-down.
+	app.stepSummaryPeriod(-1)
+	if texts := summaryTexts(app); !slices.Contains(texts, "No entries in this period.") {
+		t.Errorf("expected no entries the day before, got %q", texts)
+	}
+	app.stepSummaryPeriod(1)
+
+	app.summaryBack()
+	if app.summaryDescription != nil || app.summaryProject != "Acme" {
+		t.Fatalf("expected Back to return to the descriptions, got %q", app.summaryProject)
+	}
+	app.summaryBack()
+	if app.summaryProject != "" || !slices.Contains(summaryTexts(app), "Other") {
+		t.Errorf("expected Back to return to all projects, got %q", summaryTexts(app))
+	}
+}
+
+func TestIntegration_SummaryPeriods(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	now := time.Now()
+	weekStart := app.prefs.WeekStart
+	if got, want := app.weeklyCard.Subtitle, models.PeriodOf(models.PeriodWeek, now, weekStart).Label(); got != want {
+		t.Fatalf("expected this week shown by default, got %q want %q", got, want)
+	}
+
+	app.periodSelect.SetSelected("Month")
+	app.stepSummaryPeriod(-1)
+	if got, want := app.weeklyCard.Subtitle, models.PeriodOf(models.PeriodMonth, now, weekStart).Shift(-1).Label(); got != want {
+		t.Errorf("expected last month, got %q want %q", got, want)
+	}
+	app.periodSelect.SetSelected("Quarter")
+	if got, want := app.weeklyCard.Subtitle, models.PeriodOf(models.PeriodQuarter, now, weekStart).Label(); got != want {
+		t.Errorf("expected a new kind to start at the current period, got %q want %q", got, want)
+	}
+
+	if err := app.setCustomPeriod("2024-03-20", "2024-03-01"); !errors.Is(err, models.ErrInvalidPeriod) {
+		t.Errorf("expected ErrInvalidPeriod, got %v", err)
+	}
+	if err := app.setCustomPeriod("March 1", "2024-03-20"); err == nil {
+		t.Error("expected an error for a malformed date")
+	}
+	if err := app.setCustomPeriod("2024-03-01", "2024-03-20"); err != nil {
+		t.Fatalf("setCustomPeriod: %v", err)
+	}
+	if app.periodSelect.Selected != "Custom" || app.weeklyCard.Subtitle != "Mar 1 – Mar 20, 2024" {
+		t.Errorf("expected the custom range shown, got %q / %q", app.periodSelect.Selected, app.weeklyCard.Subtitle)
+	}
+	app.stepSummaryPeriod(1)
+	if app.weeklyCard.Subtitle != "Mar 21 – Apr 9, 2024" {
+		t.Errorf("expected the next 20 days, got %q", app.weeklyCard.Subtitle)
+	}
+
+	// Weeks follow the first day of the week preference.
+	app.prefs.WeekStart = time.Saturday
+	app.periodSelect.SetSelected("Week")
+	if period := app.summaryPeriod(now); period.Start.Weekday() != time.Saturday || app.weeklyCard.Subtitle != period.Label() {
+		t.Errorf("expected a Saturday-start week, got %q", app.weeklyCard.Subtitle)
+	}
+}
+
+func TestIntegration_FlexBalance(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if app.flexLabel.Visible() {
+		t.Error("expected no flex-time balance before a start date is set")
+	}
+
+	yesterday := models.PeriodOf(models.PeriodDay, time.Now(), time.Monday).Start.AddDate(0, 0, -1)
+	task := &models.Task{
+		ProjectName: "Work",
+		StartTime:   yesterday.Add(8 * time.Hour),
+		EndTime:     yesterday.Add(18 * time.Hour),
+		Duration:    10 * time.Hour,
+	}
+	if err := app.db.SaveTask(task); err != nil {
+		t.Fatalf("failed to save task: %v", err)
+	}
+	app.tasks = append(app.tasks, task)
+	app.schedule = models.WorkSchedule{Weekdays: models.DefaultWeekdays(8 * time.Hour)}
+	app.prefs.FlexStart = yesterday.Format(time.DateOnly)
+	app.prefs.FlexOpeningBalance = 1
+	app.refreshFlex()
+
+	want := time.Hour + task.Duration - app.schedule.Target(yesterday)
+	if !app.flexLabel.Visible() || app.flexLabel.Text != "Flex: "+models.FormatFlex(want) {
+		t.Errorf("expected the balance %s in the header, got %q", models.FormatFlex(want), app.flexLabel.Text)
+	}
+
+	if err := app.addFlexCorrection(yesterday.Format(time.DateOnly), "-2h", "paid out"); err != nil {
+		t.Fatalf("addFlexCorrection: %v", err)
+	}
+	want -= 2 * time.Hour
+	if app.flexLabel.Text != "Flex: "+models.FormatFlex(want) {
+		t.Errorf("expected the correction applied, got %q", app.flexLabel.Text)
+	}
+	if !strings.HasSuffix(app.flexTotals.Text, "closing balance "+models.FormatFlex(want)) {
+		t.Errorf("expected the month to close at %s, got %q", models.FormatFlex(want), app.flexTotals.Text)
+	}
+	if err := app.addFlexCorrection("yesterday", "1h", ""); err == nil {
+		t.Error("expected an error for a malformed date")
+	}
+	if err := app.addFlexCorrection(yesterday.Format(time.DateOnly), "lots", ""); !errors.Is(err, models.ErrInvalidFlexAmount) {
+		t.Errorf("expected ErrInvalidFlexAmount, got %v", err)
+	}
+
+	corrections, err := app.db.GetFlexCorrections()
+	if err != nil || len(corrections) != 1 {
+		t.Fatalf("expected 1 correction, got %d (err %v)", len(corrections), err)
+	}
+	if err := app.removeFlexCorrection(corrections[0].ID); err != nil {
+		t.Fatalf("removeFlexCorrection: %v", err)
+	}
+	if app.flexLabel.Text != "Flex: "+models.FormatFlex(want+2*time.Hour) {
+		t.Errorf("expected the correction removed, got %q", app.flexLabel.Text)
+	}
+}
+
+func TestIntegration_DailyTargetsAndHolidays(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	today := time.Now().Format(time.DateOnly)
+	if err := app.db.SetWeekdayTargets("4 4 4 4 4 4 4"); err != nil {
+		t.Fatalf("failed to set targets: %v", err)
+	}
+	app.reloadPreferences()
+	if !strings.HasSuffix(app.totalLabel.Text, "/ 4.0h") {
+		t.Errorf("expected today's target in the total, got %q", app.totalLabel.Text)
+	}
+
+	if err := app.addHoliday(today, "Company Day", false); err != nil {
+		t.Fatalf("addHoliday: %v", err)
+	}
+	if !strings.HasSuffix(app.totalLabel.Text, "· Company Day") || app.schedule.Target(time.Now()) != 0 {
+		t.Errorf("expected today off for the holiday, got %q", app.totalLabel.Text)
+	}
+	if err := app.addHoliday("someday", "", false); err == nil {
+		t.Error("expected an error for a malformed date")
+	}
+
+	holidays, err := app.db.GetHolidays()
+	if err != nil || len(holidays) != 1 {
+		t.Fatalf("expected 1 holiday, got %d (err %v)", len(holidays), err)
+	}
+	if err := app.removeHoliday(holidays[0].ID); err != nil {
+		t.Fatalf("removeHoliday: %v", err)
+	}
+
+	// Holidays are also read from an .ics file next to config.toml.
+	app.configPath = filepath.Join(t.TempDir(), config.FileName)
+	ics := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:" + time.Now().Format("20060102") +
+		"\nSUMMARY:Calendar Day\nEND:VEVENT\nEND:VCALENDAR\n"
+	if err := os.WriteFile(filepath.Join(filepath.Dir(app.configPath), "holidays.ics"), []byte(ics), 0600); err != nil {
+		t.Fatalf("failed to write calendar: %v", err)
+	}
+	if err := app.db.SetHolidaysFile("holidays.ics"); err != nil {
+		t.Fatalf("failed to set holidays file: %v", err)
+	}
+	app.reloadPreferences()
+	if !strings.HasSuffix(app.totalLabel.Text, "· Calendar Day") {
+		t.Errorf("expected the calendar's holiday today, got %q", app.totalLabel.Text)
+	}
+}
+
+func TestIntegration_Absences(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if err := app.db.SetWeekdayTargets("8 8 8 8 8 0 0"); err != nil {
+		t.Fatalf("failed to set targets: %v", err)
+	}
+	if err := app.db.SetAbsencePolicy(models.AbsenceVacation, models.AbsencePolicy{Allowance: 30}); err != nil {
+		t.Fatalf("failed to set allowance: %v", err)
+	}
+	app.reloadPreferences()
+
+	// A week from Monday through Sunday takes only its five workdays.
+	monday := time.Date(2025, 8, 4, 0, 0, 0, 0, time.Local)
+	sunday := monday.AddDate(0, 0, 6)
+	if err := app.addAbsences(monday.Format(time.DateOnly), sunday.Format(time.DateOnly), models.AbsenceVacation, false, "Trip"); err != nil {
+		t.Fatalf("addAbsences: %v", err)
+	}
+	if len(app.schedule.Absences) != 5 {
+		t.Fatalf("expected 5 absences, got %d", len(app.schedule.Absences))
+	}
+	if app.schedule.Target(monday) != 0 {
+		t.Errorf("expected no target on a vacation day, got %v", app.schedule.Target(monday))
+	}
+	balance := models.ComputeAbsenceBalance(app.schedule, models.AbsenceVacation, app.prefs.AbsencePolicies[models.AbsenceVacation], monday.Year())
+	if balance.Taken != 5 {
+		t.Errorf("expected 5 vacation days taken, got %v", balance.Taken)
+	}
+	if err := app.addAbsences(sunday.Format(time.DateOnly), monday.Format(time.DateOnly), models.AbsenceSick, false, ""); err == nil {
+		t.Error("expected an error for a range ending before it starts")
+	}
+
+	// A half sick day today halves today's target.
+	today := time.Now().Format(time.DateOnly)
+	if err := app.db.SetWeekdayTargets("8 8 8 8 8 8 8"); err != nil {
+		t.Fatalf("failed to set targets: %v", err)
+	}
+	if err := app.addAbsences(today, "", models.AbsenceSick, true, ""); err != nil {
+		t.Fatalf("addAbsences: %v", err)
+	}
+	if !strings.HasSuffix(app.totalLabel.Text, "/ 4.0h") {
+		t.Errorf("expected half of today's target in the total, got %q", app.totalLabel.Text)
+	}
+
+	for _, absence := range app.schedule.Absences {
+		if err := app.removeAbsence(absence.ID); err != nil {
+			t.Fatalf("removeAbsence: %v", err)
+		}
+	}
+	if len(app.schedule.Absences) != 0 {
+		t.Errorf("expected no absences left, got %d", len(app.schedule.Absences))
+	}
+}
+
+func TestIntegration_ActivityHeatmap(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	yesterday := models.PeriodOf(models.PeriodDay, time.Now(), time.Monday).Start.AddDate(0, 0, -1)
+	for _, task := range []*models.Task{
+		{ProjectName: "Work", StartTime: yesterday.Add(9 * time.Hour), EndTime: yesterday.Add(12 * time.Hour), Duration: 3 * time.Hour},
+		{ProjectName: "Side", StartTime: yesterday.AddDate(0, 0, -3).Add(9 * time.Hour), EndTime: yesterday.AddDate(0, 0, -3).Add(10 * time.Hour), Duration: time.Hour},
+	} {
+		if err := app.db.SaveTask(task); err != nil {
+			t.Fatalf("failed to save task: %v", err)
+		}
+	}
+	if err := app.loadTasks(); err != nil {
+		t.Fatalf("loadTasks: %v", err)
+	}
+	app.refreshActivityView()
+	if app.activityTotals.Text != "4h 0m on 2 days in the past year" {
+		t.Errorf("unexpected totals %q", app.activityTotals.Text)
+	}
+	if len(app.activityProject.Options) != 3 {
+		t.Errorf("expected every project as a filter, got %v", app.activityProject.Options)
+	}
+
+	app.activityProject.SetSelected("Side")
+	if app.activityTotals.Text != "1h 0m on 1 day in the past year" {
+		t.Errorf("unexpected totals for Side %q", app.activityTotals.Text)
+	}
+
+	// Clicking a day opens its entries in the Log.
+	app.tabs.SelectIndex(1)
+	heatmap := app.activityHeatmap.Objects[0].(*ui.Heatmap)
+	heatmap.OnTapped(models.HeatmapDay{Date: yesterday})
+	if app.tabs.SelectedIndex() != 0 {
+		t.Errorf("expected the Log to be shown, got tab %d", app.tabs.SelectedIndex())
+	}
+}
+
 func TestIntegration_ProjectSuggestions_Refresh(t *testing.T) {
 	app, cleanup := setupTestApp(t)
 	defer cleanup()
@@ -628,7 +937,7 @@ func TestIntegration_EditTask_WeeklySummaryReflectsChange(t *testing.T) {
 
 	// Weekly summary should now report ProjectB with 2h duration
 	app.mu.RLock()
-	summaries := models.ComputeWeeklySummaries(app.tasks, now, models.StartOfCurrentWeek(now))
+	summaries := models.ComputeSummary(app.tasks, models.StartOfWeek(now, time.Monday), now, models.BucketDay, time.Monday, models.RoundingRules{}).Projects
 	app.mu.RUnlock()
 
 	if len(summaries) != 1 {
@@ -719,3 +1028,935 @@ func TestResolveTaskEditEndTime(t *testing.T) {
 		}
 	})
 }
+
+func TestIntegration_StateFile_TracksTimer(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	app.statePath = filepath.Join(t.TempDir(), "tasks"+state.Suffix)
+	app.schedule = models.WorkSchedule{Weekdays: [7]time.Duration{6 * time.Hour, 6 * time.Hour, 6 * time.Hour, 6 * time.Hour, 6 * time.Hour, 6 * time.Hour, 6 * time.Hour}}
+
+	app.startTask("Prompt Project", "shell")
+	s, err := state.Read(app.statePath)
+	if err != nil {
+		t.Fatalf("failed to read state: %v", err)
+	}
+	if !s.Running || s.Project != "Prompt Project" || s.Description != "shell" || s.WorkdayGoalHours != 6 {
+		t.Fatalf("expected running state after start, got %+v", s)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	app.stopTask()
+	s, err = state.Read(app.statePath)
+	if err != nil {
+		t.Fatalf("failed to read state: %v", err)
+	}
+	if s.Running || s.Project != "" {
+		t.Fatalf("expected idle state after stop, got %+v", s)
+	}
+	if s.Day != state.DayKey(time.Now()) {
+		t.Errorf("expected state for today, got %q", s.Day)
+	}
+}
+
+func TestIntegration_SwitchWorkspace(t *testing.T) {
+	tests := []struct {
+		name      string
+		carryOver bool
+	}{
+		{name: "stop and save", carryOver: false},
+		{name: "carry over", carryOver: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app, cleanup := setupTestApp(t)
+			defer cleanup()
+
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			app.dbPath = "test_integration_tasks.db"
+			app.statePath = filepath.Join(t.TempDir(), "tasks"+state.Suffix)
+
+			app.startTask("Carry Project", "moving")
+			time.Sleep(10 * time.Millisecond)
+
+			if err := app.switchWorkspace("client", tc.carryOver); err != nil {
+				t.Fatalf("switchWorkspace failed: %v", err)
+			}
+			defer app.db.Close()
+
+			if app.workspace != "client" {
+				t.Errorf("expected workspace client, got %q", app.workspace)
+			}
+			if active, _ := database.GetActiveWorkspace(); active != "client" {
+				t.Errorf("expected client to be remembered, got %q", active)
+			}
+
+			oldDB, err := database.NewDB("test_integration_tasks.db")
+			if err != nil {
+				t.Fatalf("failed to reopen old db: %v", err)
+			}
+			defer oldDB.Close()
+			oldTasks, err := oldDB.GetTasks()
+			if err != nil {
+				t.Fatalf("failed to read old tasks: %v", err)
+			}
+
+			if !tc.carryOver {
+				if app.currentTask != nil {
+					t.Fatal("expected no running task after stop and save")
+				}
+				if len(oldTasks) != 1 {
+					t.Fatalf("expected task saved in old workspace, got %d", len(oldTasks))
+				}
+				if len(app.tasks) != 0 {
+					t.Fatalf("expected empty new workspace, got %d tasks", len(app.tasks))
+				}
+				return
+			}
+
+			if app.currentTask == nil || app.currentTask.ProjectName != "Carry Project" {
+				t.Fatal("expected running task to be carried over")
+			}
+			app.stopTask()
+			if len(oldTasks) != 0 {
+				t.Errorf("expected nothing saved in old workspace, got %d", len(oldTasks))
+			}
+			newTasks, err := app.db.GetTasks()
+			if err != nil {
+				t.Fatalf("failed to read new tasks: %v", err)
+			}
+			if len(newTasks) != 1 {
+				t.Fatalf("expected carried task saved in new workspace, got %d", len(newTasks))
+			}
+		})
+	}
+}
+
+func TestIntegration_SwitchWorkspace_RefreshesViews(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app.dbPath = "test_integration_tasks.db"
+	app.statePath = filepath.Join(t.TempDir(), "tasks"+state.Suffix)
+
+	// Both workspaces keep a balance from today, with different openings.
+	today := time.Now().Format(time.DateOnly)
+	if err := app.db.SetFlex(today, 1); err != nil {
+		t.Fatalf("failed to set flex: %v", err)
+	}
+	clientPath, err := database.GetWorkspaceDBPath("client")
+	if err != nil {
+		t.Fatalf("failed to get workspace path: %v", err)
+	}
+	clientDB, err := openDatabase(clientPath)
+	if err != nil {
+		t.Fatalf("failed to open workspace: %v", err)
+	}
+	if err := clientDB.SetFlex(today, 5); err != nil {
+		t.Fatalf("failed to set flex: %v", err)
+	}
+	if err := clientDB.SetBudget(models.Budget{ProjectName: "Acme", Limit: time.Hour, Period: models.BudgetTotal}); err != nil {
+		t.Fatalf("failed to set budget: %v", err)
+	}
+	clientDB.Close()
+
+	app.reloadPreferences()
+	app.projectEntry.SetText("Acme")
+	before := app.flexLabel.Text
+	if app.budgetBar.Visible() {
+		t.Error("expected no budget shown before switching")
+	}
+
+	if err := app.switchWorkspace("client", false); err != nil {
+		t.Fatalf("switchWorkspace failed: %v", err)
+	}
+	defer app.db.Close()
+
+	if before == "" || app.flexLabel.Text == before {
+		t.Errorf("expected the new workspace's balance, got %q (was %q)", app.flexLabel.Text, before)
+	}
+	if !app.budgetBar.Visible() {
+		t.Error("expected the new workspace's budget shown in the Start area")
+	}
+}
+
+func TestIntegration_ConfigFile_OverridesDatabase(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	app.statePath = filepath.Join(t.TempDir(), "tasks"+state.Suffix)
+	app.configPath = filepath.Join(t.TempDir(), config.FileName)
+	if err := app.db.SetIdleThreshold(12); err != nil {
+		t.Fatalf("failed to set threshold: %v", err)
+	}
+
+	app.reloadConfig()
+	if app.idleThreshold != 12 || app.prefs.Sources[config.KeyIdleThreshold] != config.SourceDatabase {
+		t.Fatalf("expected threshold 12 from database, got %d", app.idleThreshold)
+	}
+
+	if err := os.WriteFile(app.configPath, []byte("idle_threshold = 3\nworkday_length = 6.5\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	app.reloadConfig()
+	if app.idleThreshold != 3 || app.workdayLength != 6.5 {
+		t.Fatalf("expected values from config.toml, got %d / %v", app.idleThreshold, app.workdayLength)
+	}
+	if !app.preferenceFromFile(config.KeyIdleThreshold) || app.preferenceHint(config.KeyIdleThreshold) != "Set in config.toml" {
+		t.Errorf("expected threshold to be reported as set in config.toml")
+	}
+	if stored, _ := app.db.GetIdleThreshold(); stored != 12 {
+		t.Errorf("expected database value to be left alone, got %d", stored)
+	}
+	if app.schedule.Weekdays[time.Monday] != 6*time.Hour+30*time.Minute {
+		t.Errorf("expected the new goal from Monday to Friday, got %v", app.schedule.Weekdays)
+	}
+	s, err := state.Read(app.statePath)
+	if err != nil || s.WorkdayGoalHours != app.schedule.Target(time.Now()).Hours() {
+		t.Errorf("expected state file to carry today's goal, got %+v (err %v)", s, err)
+	}
+
+	// An invalid file keeps the last good values.
+	if err := os.WriteFile(app.configPath, []byte("idle_threshold = 0\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	app.reloadConfig()
+	if app.idleThreshold != 3 {
+		t.Errorf("expected invalid config to be ignored, got %d", app.idleThreshold)
+	}
+
+	if err := os.Remove(app.configPath); err != nil {
+		t.Fatalf("failed to remove config: %v", err)
+	}
+	app.reloadConfig()
+	if app.idleThreshold != 12 || app.workdayLength != 8.0 {
+		t.Errorf("expected database values after removing config.toml, got %d / %v", app.idleThreshold, app.workdayLength)
+	}
+}
+
+func TestIntegration_PauseResume(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+	app.statePath = filepath.Join(t.TempDir(), "tasks"+state.Suffix)
+
+	app.startTask("Pause Project", "interrupted")
+	if app.pauseButton.Disabled() {
+		t.Fatal("pause button should be enabled while running")
+	}
+
+	// Pretend the task started 30 minutes ago and was paused after 10.
+	start := time.Now().Add(-30 * time.Minute).Round(0)
+	app.currentTask.StartTime = start
+	test.Tap(app.pauseButton)
+	app.currentTask.Segments[0].End = start.Add(10 * time.Minute)
+	if !app.currentTask.IsPaused() || app.pauseButton.Text != "Resume" {
+		t.Fatalf("expected paused task with a Resume button, got %q", app.pauseButton.Text)
+	}
+	s, err := state.Read(app.statePath)
+	if err != nil || !s.Paused {
+		t.Fatalf("expected paused state file, got %+v (err %v)", s, err)
+	}
+
+	test.Tap(app.pauseButton)
+	if app.currentTask.IsPaused() || app.pauseButton.Text != "Pause" {
+		t.Fatal("expected task to resume")
+	}
+	app.currentTask.Segments[1].Start = time.Now().Add(-5 * time.Minute).Round(0)
+
+	test.Tap(app.stopButton)
+	if app.pauseButton.Text != "Pause" || !app.pauseButton.Disabled() {
+		t.Error("expected pause button reset and disabled after stop")
+	}
+
+	tasks, err := app.db.GetTasks()
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("expected 1 saved task, got %d (err %v)", len(tasks), err)
+	}
+	saved := tasks[0]
+	if len(saved.Segments) != 2 {
+		t.Fatalf("expected 2 saved segments, got %d", len(saved.Segments))
+	}
+	if d := saved.Duration.Round(time.Minute); d != 15*time.Minute {
+		t.Errorf("expected ~15m of active time, got %v", saved.Duration)
+	}
+	if start.Day() == time.Now().Day() {
+		if total := app.calculateTotalDurationToday().Round(time.Minute); total != 15*time.Minute {
+			t.Errorf("expected today's total to exclude the pause, got %v", total)
+		}
+	}
+}
+
+func TestIntegration_AwayTime(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+	app.statePath = filepath.Join(t.TempDir(), "tasks"+state.Suffix)
+	source := &idle.Fake{}
+	app.idleSource = source
+
+	app.startTask("Deep Work", "writing")
+	start := time.Now().Add(-time.Hour).Round(0)
+	app.currentTask.StartTime = start
+	now := start.Add(50 * time.Minute)
+
+	// Idle for 20 minutes: nothing to ask yet, the user is still away.
+	source.Set(20 * time.Minute)
+	if _, _, ok := app.checkAway(now); ok {
+		t.Fatal("expected no prompt while still away")
+	}
+
+	// Back 10 minutes later after 30 minutes away (threshold is 5).
+	source.Set(time.Minute)
+	from, to, ok := app.checkAway(now.Add(10 * time.Minute))
+	if !ok {
+		t.Fatal("expected a prompt once input resumes")
+	}
+	if !from.Equal(start.Add(30*time.Minute)) || !to.Equal(start.Add(59*time.Minute)) {
+		t.Fatalf("expected away 00:30-00:59 after start, got %v-%v", from.Sub(start), to.Sub(start))
+	}
+	if _, _, ok := app.checkAway(now.Add(11 * time.Minute)); ok {
+		t.Fatal("expected a single prompt per away period")
+	}
+
+	if err := app.resolveAwayTime(awaySplit, from, to, "", ""); !errors.Is(err, errProjectRequired) {
+		t.Fatalf("expected split without a project to fail, got %v", err)
+	}
+	if err := app.resolveAwayTime(awaySplit, from, to, "Meeting", "standup"); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if got := app.currentTask.Elapsed(start.Add(time.Hour)); got != 31*time.Minute {
+		t.Errorf("expected the away time removed from the running task, got %v", got)
+	}
+	tasks, err := app.db.GetTasks()
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("expected 1 split-off task, got %d (err %v)", len(tasks), err)
+	}
+	if tasks[0].ProjectName != "Meeting" || tasks[0].Duration != 29*time.Minute {
+		t.Errorf("expected a 29m Meeting task, got %s %v", tasks[0].ProjectName, tasks[0].Duration)
+	}
+
+	// Short breaks below the threshold are never reported.
+	source.Set(3 * time.Minute)
+	app.checkAway(now.Add(20 * time.Minute))
+	source.Set(0)
+	if _, _, ok := app.checkAway(now.Add(21 * time.Minute)); ok {
+		t.Error("expected no prompt for a break under the idle threshold")
+	}
+
+	// Paused tasks are not watched.
+	app.pauseTask()
+	source.Set(time.Hour)
+	app.checkAway(now.Add(30 * time.Minute))
+	source.Set(0)
+	if _, _, ok := app.checkAway(now.Add(31 * time.Minute)); ok {
+		t.Error("expected no prompt while paused")
+	}
+}
+
+func TestIntegration_ForgottenTimer(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+	app.prefs.MaxTimerHours = 10
+
+	app.startTask("Overnight", "left running")
+	start := time.Now().Add(-14 * time.Hour).Round(0)
+	app.currentTask.StartTime = start
+
+	if !app.checkForgotten(time.Now()) {
+		t.Fatal("expected a notification for a 14h timer")
+	}
+	if app.checkForgotten(time.Now()) {
+		t.Error("expected a single notification per task")
+	}
+	forgotten, ok := app.forgottenTimer(time.Now())
+	if !ok || !forgotten.Cutoff.Equal(start.Add(10*time.Hour)) {
+		t.Fatalf("expected a cutoff 10h after the start, got %v (%v)", forgotten.Cutoff, ok)
+	}
+
+	if err := app.stopTaskAt(start.Add(-time.Minute)); !errors.Is(err, errInvalidTrimTime) {
+		t.Errorf("expected a trim before the start to fail, got %v", err)
+	}
+	if err := app.stopTaskAt(time.Now().Add(time.Hour)); !errors.Is(err, errInvalidTrimTime) {
+		t.Errorf("expected a trim in the future to fail, got %v", err)
+	}
+
+	if err := app.stopTaskAt(forgotten.Cutoff); err != nil {
+		t.Fatalf("trim failed: %v", err)
+	}
+	if app.currentTask != nil {
+		t.Fatal("expected the task to be stopped")
+	}
+	tasks, err := app.db.GetTasks()
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("expected 1 saved task, got %d (err %v)", len(tasks), err)
+	}
+	if tasks[0].Duration != 10*time.Hour || !tasks[0].EndTime.Equal(forgotten.Cutoff) {
+		t.Errorf("expected a 10h entry ending at the cutoff, got %v ending %v", tasks[0].Duration, tasks[0].EndTime)
+	}
+
+	// Within the limit, stopping needs no prompt.
+	app.startTask("Short", "")
+	if _, ok := app.forgottenTimer(time.Now()); ok {
+		t.Error("expected a fresh task not to look forgotten")
+	}
+}
+
+func pomodoroPrefs(breaks string) config.Preferences {
+	return config.Preferences{
+		PomodoroEnabled:    true,
+		PomodoroWork:       25,
+		PomodoroShortBreak: 5,
+		PomodoroLongBreak:  15,
+		PomodoroBreaks:     breaks,
+	}
+}
+
+func TestIntegration_Pomodoro_GapBreaks(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+	app.prefs = pomodoroPrefs(database.PomodoroBreaksGap)
+
+	app.startTask("Focus", "deep work")
+	if app.pomodoro == nil {
+		t.Fatal("expected a Pomodoro cycle to start with the task")
+	}
+	start := time.Now().Add(-26 * time.Minute).Round(0)
+	app.currentTask.StartTime = start
+	app.pomodoro.PhaseStart = start
+
+	if !app.tickPomodoro(time.Now().Round(0)) {
+		t.Fatal("expected the work phase to end")
+	}
+	if !app.currentTask.IsPaused() || app.currentTask.Pomodoros != 1 {
+		t.Fatalf("expected the break to pause the task after 1 pomodoro, got paused=%v pomodoros=%d",
+			app.currentTask.IsPaused(), app.currentTask.Pomodoros)
+	}
+	if status := app.pomodoroStatus(time.Now()); !strings.HasPrefix(status, "Short break") {
+		t.Errorf("expected a short-break countdown, got %q", status)
+	}
+
+	// Resuming ends the break early.
+	if err := app.resumeTask(); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if app.pomodoro.Phase != models.PhaseWork {
+		t.Errorf("expected a new work phase after resuming, got %v", app.pomodoro.Phase)
+	}
+
+	test.Tap(app.stopButton)
+	tasks, err := app.db.GetTasks()
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("expected 1 saved task, got %d (err %v)", len(tasks), err)
+	}
+	if tasks[0].Pomodoros != 1 || len(tasks[0].Segments) != 2 {
+		t.Errorf("expected 1 pomodoro and a gap for the break, got %d pomodoros and %d segments", tasks[0].Pomodoros, len(tasks[0].Segments))
+	}
+	if app.pomodoro != nil {
+		t.Error("expected the cycle to end with the task")
+	}
+}
+
+func TestIntegration_Pomodoro_BreakProject(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+	app.prefs = pomodoroPrefs(database.PomodoroBreaksProject)
+
+	app.startTask("Focus", "deep work")
+	now := time.Now().Round(0)
+	start := now.Add(-26 * time.Minute)
+	app.currentTask.StartTime = start
+	app.pomodoro.PhaseStart = start
+
+	app.tickPomodoro(now)
+	if app.currentTask.ProjectName != breakProject || !app.currentTask.StartTime.Equal(start.Add(25*time.Minute)) {
+		t.Fatalf("expected a Break task from the end of the pomodoro, got %s at %v", app.currentTask.ProjectName, app.currentTask.StartTime)
+	}
+
+	app.tickPomodoro(now.Add(5 * time.Minute))
+	if app.currentTask.ProjectName != "Focus" || app.currentTask.Description != "deep work" {
+		t.Fatalf("expected the work task to restart after the break, got %s", app.currentTask.ProjectName)
+	}
+	if !app.currentTask.StartTime.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("expected work to restart when the break ended, got %v", app.currentTask.StartTime)
+	}
+
+	tasks, err := app.db.GetTasks()
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("expected the pomodoro and the break saved, got %d (err %v)", len(tasks), err)
+	}
+	for _, task := range tasks {
+		switch task.ProjectName {
+		case "Focus":
+			if task.Pomodoros != 1 || task.Duration != 25*time.Minute {
+				t.Errorf("expected a 25m pomodoro, got %v with %d", task.Duration, task.Pomodoros)
+			}
+		case breakProject:
+			if task.Duration != 5*time.Minute || !task.StartTime.Equal(start.Add(25*time.Minute)) {
+				t.Errorf("expected a 5m break right after it, got %v at %v", task.Duration, task.StartTime)
+			}
+		}
+	}
+}
+
+func TestIntegration_EditTaskSegments(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	task := &models.Task{ProjectName: "Seg"}
+	if err := task.SetSegments([]models.Segment{
+		{Start: base, End: base.Add(time.Hour)},
+		{Start: base.Add(2 * time.Hour), End: base.Add(3 * time.Hour)},
+	}); err != nil {
+		t.Fatalf("failed to set segments: %v", err)
+	}
+	if err := app.addTask(task); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+
+	segments, err := parseSegmentsInput(formatSegmentsInput(task.Segments))
+	if err != nil || len(segments) != 2 || !segments[1].End.Equal(base.Add(3*time.Hour)) {
+		t.Fatalf("expected segments to round-trip, got %v (err %v)", segments, err)
+	}
+	if _, err := parseSegmentsInput("2026-03-02 09:00:00"); err == nil {
+		t.Error("expected error for a line without an end")
+	}
+
+	segments[1].Start = base.Add(90 * time.Minute)
+	if err := app.applySegmentsEdit(task, "Seg", "fixed", segments); err != nil {
+		t.Fatalf("failed to edit segments: %v", err)
+	}
+	if task.Duration != 150*time.Minute || task.Description != "fixed" {
+		t.Errorf("expected 2h30m after the edit, got %v", task.Duration)
+	}
+
+	overlapping := []models.Segment{{Start: base, End: base.Add(2 * time.Hour)}, {Start: base.Add(time.Hour), End: base.Add(3 * time.Hour)}}
+	if err := app.applySegmentsEdit(task, "Seg", "broken", overlapping); err == nil {
+		t.Fatal("expected overlapping segments to be rejected")
+	}
+	if task.Description != "fixed" || task.Duration != 150*time.Minute {
+		t.Error("expected a rejected edit to leave the task unchanged")
+	}
+
+	// Moving the end through the plain editor moves the last segment.
+	if err := app.applyTaskEdit(task, "Seg", "fixed", base, base.Add(4*time.Hour)); err != nil {
+		t.Fatalf("failed to edit task: %v", err)
+	}
+	saved, err := app.db.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("failed to reload task: %v", err)
+	}
+	if len(saved.Segments) != 2 || saved.Duration != 210*time.Minute {
+		t.Errorf("expected 2 segments totalling 3h30m, got %d / %v", len(saved.Segments), saved.Duration)
+	}
+}
+
+func TestIntegration_Estimate(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	app.estimateEntry.SetText("soon")
+	app.startTask("Focus", "estimate")
+	if app.currentTask != nil {
+		t.Fatal("expected an invalid estimate to keep the timer stopped")
+	}
+
+	app.estimateEntry.SetText("45m")
+	app.startTask("Focus", "estimate")
+	if app.currentTask == nil || app.currentTask.Estimate != 45*time.Minute {
+		t.Fatalf("expected a running task with a 45m estimate, got %+v", app.currentTask)
+	}
+	if app.checkEstimate(time.Now()) {
+		t.Error("expected no notification within the estimate")
+	}
+	app.currentTask.StartTime = time.Now().Add(-50 * time.Minute).Round(0)
+	if !app.checkEstimate(time.Now()) {
+		t.Fatal("expected a notification once the estimate is exceeded")
+	}
+	if app.checkEstimate(time.Now()) {
+		t.Error("expected a single notification per task")
+	}
+
+	app.stopTask()
+	if app.estimateEntry.Text != "" {
+		t.Errorf("expected the estimate entry cleared after stopping, got %q", app.estimateEntry.Text)
+	}
+	tasks, err := app.db.GetTasks()
+	if err != nil || len(tasks) != 1 || tasks[0].Estimate != 45*time.Minute {
+		t.Fatalf("expected the estimate saved with the task, got %+v (err %v)", tasks, err)
+	}
+}
+
+func TestIntegration_Budgets(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if err := app.setBudget("Acme", "none", models.BudgetWeekly); err == nil {
+		t.Error("expected an error for a budget that is not a number of hours")
+	}
+	if err := app.setBudget("Acme", "1", models.BudgetTotal); err != nil {
+		t.Fatalf("failed to set budget: %v", err)
+	}
+	if len(app.budgets) != 1 || app.budgets[0].Limit != time.Hour {
+		t.Fatalf("expected the 1h budget loaded, got %+v", app.budgets)
+	}
+
+	// The Start area shows the budget of the project entered.
+	app.projectEntry.SetText("Other")
+	if app.budgetBar.Visible() {
+		t.Error("expected no budget shown for a project without one")
+	}
+	app.projectEntry.SetText("Acme")
+	if !app.budgetBar.Visible() || !strings.HasPrefix(app.budgetLabel.Text, "Budget: 0m of 1h 0m (0%)") {
+		t.Errorf("expected Acme's budget shown, got %q", app.budgetLabel.Text)
+	}
+
+	app.startTask("Acme", "fixed price")
+	if app.checkBudget(time.Now()) {
+		t.Error("expected no alert below 80%")
+	}
+	app.currentTask.StartTime = time.Now().Add(-50 * time.Minute).Round(0)
+	if !app.checkBudget(time.Now()) {
+		t.Fatal("expected an alert at 80%")
+	}
+	if app.checkBudget(time.Now()) {
+		t.Error("expected a single alert per threshold")
+	}
+	app.currentTask.StartTime = time.Now().Add(-70 * time.Minute).Round(0)
+	if !app.checkBudget(time.Now()) {
+		t.Fatal("expected an alert once the budget is used up")
+	}
+	app.stopTask()
+	if !strings.HasSuffix(app.budgetLabel.Text, "over") {
+		t.Errorf("expected the overrun shown after stopping, got %q", app.budgetLabel.Text)
+	}
+
+	if err := app.removeBudget("Acme"); err != nil {
+		t.Fatalf("failed to remove budget: %v", err)
+	}
+	if len(app.budgets) != 0 || app.budgetBar.Visible() {
+		t.Errorf("expected no budgets left, got %+v", app.budgets)
+	}
+}
+
+func TestIntegration_RoundingRules(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if err := app.setRoundingRule(models.RoundingForClient, "Acme Corp", "six", models.RoundUp, models.RoundPerEntry); err == nil {
+		t.Error("expected an error for an increment that is not a number of minutes")
+	}
+	if err := app.setRoundingRule(models.RoundingForClient, "Acme Corp", "6", models.RoundUp, models.RoundPerEntry); err != nil {
+		t.Fatalf("failed to set rounding rule: %v", err)
+	}
+	if err := app.setProjectClient("Website", "Acme Corp"); err != nil {
+		t.Fatalf("failed to set project client: %v", err)
+	}
+	want := models.RoundingRule{Increment: 6 * time.Minute, Mode: models.RoundUp, Per: models.RoundPerEntry}
+	if got := app.prefs.RoundingRules().For("Website"); got != want {
+		t.Errorf("expected the client's rule for its project, got %+v", got)
+	}
+	if err := app.setRoundingRule(models.RoundingForProject, "Website", "0", models.RoundNearest, models.RoundPerEntry); err != nil {
+		t.Fatalf("failed to set rounding rule: %v", err)
+	}
+	if app.prefs.RoundingRules().For("Website").Enabled() {
+		t.Error("expected the project's own rule to win over its client's")
+	}
+
+	if err := app.removeRoundingRule(models.RoundingForProject, "Website"); err != nil {
+		t.Fatalf("failed to remove rounding rule: %v", err)
+	}
+	if err := app.setProjectClient("Website", ""); err != nil {
+		t.Fatalf("failed to remove project client: %v", err)
+	}
+	if app.prefs.RoundingRules().For("Website").Enabled() || len(app.prefs.ProjectClients) != 0 {
+		t.Errorf("expected no rounding once the project has no client, got %+v", app.prefs.RoundingRules())
+	}
+}
+
+func TestIntegration_Digests(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	// Friday, Oct 23 ends the default Monday-to-Friday week.
+	friday := time.Date(2026, 10, 23, 9, 0, 0, 0, time.Local)
+	if err := app.addTask(&models.Task{ProjectName: "Acme", StartTime: friday, EndTime: friday.Add(6 * time.Hour), Duration: 6 * time.Hour}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	evening := friday.Add(9 * time.Hour)
+	if got := app.checkDigests(evening); len(got) != 0 {
+		t.Fatalf("expected no digests while they are off, got %+v", got)
+	}
+
+	dir := filepath.Join(t.TempDir(), "reports")
+	if err := app.db.SetDigest(models.DigestSettings{Enabled: true, Weekly: true, ReportDir: dir, ReportFormat: models.ReportMarkdown}); err != nil {
+		t.Fatalf("failed to set digest settings: %v", err)
+	}
+	app.reloadPreferences()
+	if got := app.checkDigests(friday.Add(8 * time.Hour)); len(got) != 0 {
+		t.Errorf("expected no digests before 18:00, got %+v", got)
+	}
+	digests := app.checkDigests(evening)
+	if len(digests) != 2 || digests[1].Period.Kind != models.PeriodWeek {
+		t.Fatalf("expected the daily and weekly digests on Friday, got %+v", digests)
+	}
+	if digests[0].Total != 6*time.Hour || digests[0].Goal != 8*time.Hour {
+		t.Errorf("expected 6h of 8h, got %v of %v", digests[0].Total, digests[0].Goal)
+	}
+	for _, name := range []string{"digest-2026-10-23.md", "digest-week-2026-10-19.md"} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || !strings.Contains(string(content), "| Acme | 6h 0m | 100% |") {
+			t.Errorf("expected the report %s, got %q (err %v)", name, content, err)
+		}
+	}
+
+	if got := app.checkDigests(evening.Add(time.Hour)); len(got) != 0 {
+		t.Errorf("expected the digests only once a day, got %+v", got)
+	}
+	// The last digest is kept across restarts.
+	if sent, _ := app.db.GetDigestSent(); !sent.Equal(evening) {
+		t.Errorf("expected the digest time saved, got %v", sent)
+	}
+}
+
+func TestEstimateStatus(t *testing.T) {
+	if got := estimateStatus(10*time.Minute+30*time.Second, 45*time.Minute); got != "35m left" {
+		t.Errorf("expected 35m left, got %q", got)
+	}
+	if got := estimateStatus(44*time.Minute+50*time.Second, 45*time.Minute); got != "1m left" {
+		t.Errorf("expected the last minute to read 1m left, got %q", got)
+	}
+	if got := estimateStatus(time.Hour, 45*time.Minute); got != "15m over" {
+		t.Errorf("expected 15m over, got %q", got)
+	}
+	for _, estimate := range []time.Duration{50 * time.Minute, time.Hour, 110 * time.Minute, 90 * time.Second} {
+		if got, err := models.ParseEstimate(formatEstimateInput(estimate)); err != nil || got != estimate {
+			t.Errorf("expected %v to round-trip through the entry, got %v (err %v)", estimate, got, err)
+		}
+	}
+}
+
+func TestIntegration_SleepPolicy(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+	app.prefs.SleepPolicy = database.SleepPolicyPause
+	fake := idle.NewFakeSleep()
+	app.sleepWatcher = fake
+
+	app.startTask("Laptop", "closed the lid")
+	start := time.Now().Add(-2 * time.Hour).Round(0)
+	app.currentTask.StartTime = start
+
+	// Locked, then suspended for an hour: the period ends with the unlock.
+	done := make(chan struct{})
+	go func() {
+		app.monitorSleep(context.Background())
+		close(done)
+	}()
+	fake.Send(idle.Lock, start.Add(30*time.Minute))
+	fake.Send(idle.Suspend, start.Add(31*time.Minute))
+	fake.Send(idle.Resume, start.Add(90*time.Minute))
+	fake.Send(idle.Unlock, start.Add(91*time.Minute))
+	fake.Close()
+	<-done
+
+	task := app.currentTask
+	if !task.IsPaused() || task.Elapsed(time.Now()) != 30*time.Minute {
+		t.Fatalf("expected the task paused after 30 minutes, got paused=%v elapsed=%v", task.IsPaused(), task.Elapsed(time.Now()))
+	}
+
+	// The clock jump seen by the timer covers the same sleep.
+	app.resumeTask()
+	app.checkClockJump(start.Add(31*time.Minute), start.Add(90*time.Minute))
+	if app.currentTask.IsPaused() {
+		t.Error("expected an already handled sleep to be left alone")
+	}
+	app.stopTask()
+
+	// Unannounced suspends are found from the wall clock alone.
+	app.startTask("Laptop", "no logind")
+	resumed := time.Now().Round(0)
+	app.currentTask.StartTime = resumed.Add(-25 * time.Minute)
+	app.checkClockJump(resumed.Add(-15*time.Minute), resumed.Add(-20*time.Second))
+	if !app.currentTask.IsPaused() {
+		t.Fatal("expected a 15 minute clock jump to pause the task")
+	}
+	app.stopTask()
+	tasks, err := app.db.GetTasks()
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("expected 2 saved tasks, got %d (err %v)", len(tasks), err)
+	}
+	for _, saved := range tasks {
+		if saved.Description == "no logind" && saved.Duration != 10*time.Minute {
+			t.Errorf("expected 10 minutes before the jump, got %v", saved.Duration)
+		}
+	}
+
+	// The ignore policy and short gaps leave the time counted.
+	app.prefs.SleepPolicy = database.SleepPolicyIgnore
+	app.startTask("Laptop", "ignored")
+	later := app.currentTask.StartTime
+	app.checkClockJump(later.Add(time.Minute), later.Add(41*time.Minute))
+	if app.currentTask.IsPaused() {
+		t.Error("expected the ignore policy to keep the timer running")
+	}
+	app.prefs.SleepPolicy = database.SleepPolicyPause
+	app.checkClockJump(later.Add(50*time.Minute), later.Add(50*time.Minute+30*time.Second))
+	if app.currentTask.IsPaused() {
+		t.Error("expected a gap shorter than a minute to be ignored")
+	}
+}
+
+func TestIntegration_SwitchTask(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	// Without task switching a second start is refused.
+	app.startTask("First", "one")
+	first := app.currentTask
+	app.startTask("Second", "two")
+	if app.currentTask != first {
+		t.Fatal("expected the running task to be kept")
+	}
+	if _, err := app.beginTask("Second", "two", 0); !errors.Is(err, errTaskAlreadyRunning) {
+		t.Fatalf("expected errTaskAlreadyRunning, got %v", err)
+	}
+
+	app.prefs.SwitchTasks = true
+	app.updateButtonsState(true)
+	if app.startButton.Disabled() || app.projectEntry.Disabled() || app.startButton.Text != "Switch" {
+		t.Fatal("expected the Start area to stay usable for switching")
+	}
+	first.StartTime = time.Now().Add(-30 * time.Minute).Round(0)
+	app.estimateEntry.SetText("20m")
+	app.startTask("Second", "two")
+	second := app.currentTask
+	if second == first || second.ProjectName != "Second" || second.Estimate != 20*time.Minute {
+		t.Fatalf("expected to switch to Second with its estimate, got %+v", second)
+	}
+
+	// The Log play button switches too, without carrying the estimate.
+	saved := app.tasks[0]
+	app.continueTask(saved)
+	third := app.currentTask
+	if third.ProjectName != "First" || third.Description != "one" || third.Estimate != 0 {
+		t.Fatalf("expected to continue First, got %+v", third)
+	}
+	app.stopTask()
+
+	tasks, err := app.db.GetTasks()
+	if err != nil || len(tasks) != 3 {
+		t.Fatalf("expected 3 saved tasks, got %d (err %v)", len(tasks), err)
+	}
+	slices.SortFunc(tasks, func(a, b *models.Task) int { return b.StartTime.Compare(a.StartTime) })
+	// Newest first: each entry starts exactly where the previous one ended.
+	for i := 1; i < len(tasks); i++ {
+		if !tasks[i].EndTime.Equal(tasks[i-1].StartTime) {
+			t.Errorf("expected %s to end when %s started, got %v and %v",
+				tasks[i].ProjectName, tasks[i-1].ProjectName, tasks[i].EndTime, tasks[i-1].StartTime)
+		}
+	}
+	if tasks[2].ProjectName != "First" || tasks[2].Duration < 30*time.Minute {
+		t.Errorf("expected the first 30 minutes kept, got %s for %v", tasks[2].ProjectName, tasks[2].Duration)
+	}
+}
+
+func TestIntegration_SwitchTask_SaveFails(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	app.startTask("First", "one")
+	first := app.currentTask
+	start, end := first.StartTime, first.EndTime
+	app.db.Close()
+
+	if _, err := app.switchTaskAt(time.Now().Round(0), "Second", "two", 0); err == nil {
+		t.Fatal("expected the failed save reported")
+	}
+	if app.currentTask != first || !first.StartTime.Equal(start) || !first.EndTime.Equal(end) || len(app.tasks) != 0 {
+		t.Errorf("expected First still running and unsaved, got %+v and %d tasks", app.currentTask, len(app.tasks))
+	}
+}
+
+func TestIntegration_RetroactiveStart(t *testing.T) {
+	app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	// An offset back-dates the start.
+	app.startAtEntry.SetText("-20m")
+	app.startTask("Docs", "intro")
+	first := app.currentTask
+	if first == nil {
+		t.Fatal("expected a running task")
+	}
+	if ago := time.Since(first.StartTime); ago < 20*time.Minute || ago > 21*time.Minute {
+		t.Fatalf("expected the task to start 20 minutes ago, got %v", ago)
+	}
+	if app.startAtEntry.Text != "" {
+		t.Error("expected the start entry cleared after starting")
+	}
+	first.StartTime = time.Now().Add(-time.Hour).Round(0)
+	app.stopTask()
+
+	// A start inside the saved task is refused.
+	if _, err := app.beginTaskAt(time.Now().Add(-30*time.Minute), "Code", "", 0); !errors.Is(err, errStartOverlaps) {
+		t.Fatalf("expected errStartOverlaps, got %v", err)
+	}
+	if _, err := app.beginTaskAt(time.Now().Add(time.Minute), "Code", "", 0); !errors.Is(err, errStartInFuture) {
+		t.Fatalf("expected errStartInFuture, got %v", err)
+	}
+	if app.currentTask != nil {
+		t.Fatal("expected no task started")
+	}
+
+	// Snapping starts exactly where the last task ended.
+	saved := app.tasks[0]
+	app.snapCheck.SetChecked(true)
+	if !app.startAtEntry.Disabled() {
+		t.Error("expected the start entry disabled while snapping")
+	}
+	app.startTask("Code", "review")
+	if app.currentTask == nil || !app.currentTask.StartTime.Equal(saved.EndTime) {
+		t.Fatalf("expected the task to start at %v, got %+v", saved.EndTime, app.currentTask)
+	}
+	if app.snapCheck.Checked {
+		t.Error("expected the snap check cleared after starting")
+	}
+
+	// Switching cannot go back past the running task's start.
+	app.prefs.SwitchTasks = true
+	before := app.currentTask.StartTime.Add(-time.Minute)
+	if _, err := app.beginTaskAt(before, "Docs", "", 0); !errors.Is(err, errStartOverlaps) {
+		t.Fatalf("expected errStartOverlaps, got %v", err)
+	}
+}
+
+func TestValidateTaskStart(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.Local)
+	tasks := []*models.Task{{
+		ProjectName: "Docs",
+		StartTime:   now.Add(-2 * time.Hour),
+		EndTime:     now.Add(-time.Hour),
+	}}
+	tests := []struct {
+		name  string
+		start time.Time
+		want  error
+	}{
+		{"now", now, nil},
+		{"after the last task", now.Add(-30 * time.Minute), nil},
+		{"at the end of the last task", now.Add(-time.Hour), nil},
+		{"inside the last task", now.Add(-90 * time.Minute), errStartOverlaps},
+		{"before the last task", now.Add(-3 * time.Hour), errStartOverlaps},
+		{"future", now.Add(time.Minute), errStartInFuture},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTaskStart(tt.start, now, tasks)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("validateTaskStart() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// Absence types. Comp time is taken from the flex-time balance, so unlike
+// the others it does not credit the day's target.
+const (
+	AbsenceVacation = "vacation"
+	AbsenceSick     = "sick"
+	AbsenceHoliday  = "holiday"
+	AbsenceCompTime = "comp_time"
+)
+
+// AbsenceTypes lists the absence types in display order.
+var AbsenceTypes = []string{AbsenceVacation, AbsenceSick, AbsenceHoliday, AbsenceCompTime}
+
+// AbsenceLabel names an absence type for display.
+func AbsenceLabel(absenceType string) string {
+	switch absenceType {
+	case AbsenceVacation:
+		return "Vacation"
+	case AbsenceSick:
+		return "Sick leave"
+	case AbsenceHoliday:
+		return "Public holiday"
+	case AbsenceCompTime:
+		return "Comp time"
+	}
+	return absenceType
+}
+
+// Absence is a day or half-day away from work. Absences are kept apart from
+// tasks and never count towards project totals.
+type Absence struct {
+	ID   int64
+	Date time.Time // the day it applies to
+	Type string
+	Half bool
+	Note string
+}
+
+// Days returns how much of a day the absence takes: 1, or 0.5 for half-days.
+func (a Absence) Days() float64 {
+	if a.Half {
+		return 0.5
+	}
+	return 1
+}
+
+// credits reports whether the absence counts as time worked against the
+// day's target.
+func (a Absence) credits() bool {
+	return a.Type != AbsenceCompTime
+}
+
+// AbsencePolicy is the yearly allowance of an absence type, in days.
+type AbsencePolicy struct {
+	Allowance float64 // days per calendar year, 0 for none
+	CarryOver float64 // most unused days carried into the next year
+}
+
+// AbsenceBalance is the allowance of an absence type in one year and how
+// much of it was taken.
+type AbsenceBalance struct {
+	Type        string
+	Year        int
+	Allowance   float64
+	CarriedOver float64
+	Taken       float64
+}
+
+// Remaining returns the days of the allowance left.
+func (b AbsenceBalance) Remaining() float64 {
+	return b.Allowance + b.CarriedOver - b.Taken
+}
+
+// AbsenceTotal is the number of days taken of an absence type.
+type AbsenceTotal struct {
+	Type string
+	Days float64
+}
+
+// FormatAbsenceDays formats a number of days such as "1 day" or "2.5 days".
+func FormatAbsenceDays(days float64) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return strconv.FormatFloat(days, 'f', -1, 64) + " days"
+}
+
+// ComputeAbsenceBalance returns the balance of absenceType in year under
+// policy. Unused days are carried over, up to policy.CarryOver, from every
+// year since the first absence of that type. Only workdays under schedule
+// count as taken.
+func ComputeAbsenceBalance(schedule WorkSchedule, absenceType string, policy AbsencePolicy, year int) AbsenceBalance {
+	taken := make(map[int]float64)
+	first := year
+	for _, a := range schedule.Absences {
+		if a.Type != absenceType {
+			continue
+		}
+		y := a.Date.Year()
+		taken[y] += schedule.absenceDays(a)
+		if y < first {
+			first = y
+		}
+	}
+
+	balance := AbsenceBalance{Type: absenceType, Year: year, Allowance: policy.Allowance}
+	for y := first; y < year; y++ {
+		unused := policy.Allowance + balance.CarriedOver - taken[y]
+		balance.CarriedOver = math.Max(0, math.Min(unused, policy.CarryOver))
+	}
+	balance.Taken = taken[year]
+	return balance
+}
+
+// AbsenceTotals returns the days taken of each absence type in [start, end),
+// in the order of AbsenceTypes, leaving out types without any.
+func (s WorkSchedule) AbsenceTotals(start, end time.Time) []AbsenceTotal {
+	days := make(map[string]float64)
+	for _, a := range s.Absences {
+		if !a.Date.Before(start) && a.Date.Before(end) {
+			days[a.Type] += s.absenceDays(a)
+		}
+	}
+	var totals []AbsenceTotal
+	for _, absenceType := range AbsenceTypes {
+		if days[absenceType] > 0 {
+			totals = append(totals, AbsenceTotal{Type: absenceType, Days: days[absenceType]})
+		}
+	}
+	return totals
+}
+
+// absenceDays returns the days an absence takes, which is none on days
+// without a target.
+func (s WorkSchedule) absenceDays(a Absence) float64 {
+	if !s.Workday(a.Date) {
+		return 0
+	}
+	return a.Days()
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkSchedule_AbsenceCredit(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	schedule := WorkSchedule{
+		Weekdays: DefaultWeekdays(8 * time.Hour),
+		Absences: []Absence{
+			{Date: day(8, 12), Type: AbsenceVacation},
+			{Date: day(8, 13), Type: AbsenceSick, Half: true},
+			{Date: day(8, 14), Type: AbsenceCompTime},
+			{Date: day(8, 15), Type: AbsenceVacation, Half: true},
+			{Date: day(8, 15), Type: AbsenceSick, Half: true},
+			{Date: day(8, 15), Type: AbsenceSick, Half: true}, // more than a day credits no more
+		},
+	}
+	tests := []struct {
+		day  time.Time
+		want time.Duration
+	}{
+		{day(8, 12), 0},
+		{day(8, 13), 4 * time.Hour},
+		{day(8, 14), 8 * time.Hour}, // comp time is taken from the flex-time balance
+		{day(8, 15), 0},
+		{day(8, 16), 8 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := schedule.Target(tt.day); got != tt.want {
+			t.Errorf("Target(%s) = %v, want %v", tt.day.Format("Mon Jan 2"), got, tt.want)
+		}
+	}
+	if absences := schedule.AbsencesOn(day(8, 15).Add(9 * time.Hour)); len(absences) != 3 {
+		t.Errorf("expected 3 absences on Aug 15, got %d", len(absences))
+	}
+}
+
+func TestComputeAbsenceBalance(t *testing.T) {
+	vacation := func(y int, m time.Month, d int, half bool) Absence {
+		return Absence{Date: time.Date(y, m, d, 0, 0, 0, 0, time.UTC), Type: AbsenceVacation, Half: half}
+	}
+	schedule := WorkSchedule{Weekdays: DefaultWeekdays(8 * time.Hour)}
+	for d := 5; d <= 9; d++ {
+		schedule.Absences = append(schedule.Absences, vacation(2022, 9, d, false)) // Mon–Fri
+	}
+	schedule.Absences = append(schedule.Absences,
+		vacation(2022, 9, 10, false), // Saturday, takes nothing
+		vacation(2023, 1, 2, false),  // Monday
+		vacation(2024, 8, 12, true),  // Monday
+		Absence{Date: time.Date(2024, 8, 13, 0, 0, 0, 0, time.UTC), Type: AbsenceSick},
+	)
+	policy := AbsencePolicy{Allowance: 10, CarryOver: 3}
+
+	// 2022: 10 - 5 leaves 5, 3 carried over. 2023: 13 - 1 leaves 12, 3
+	// carried over again.
+	balance := ComputeAbsenceBalance(schedule, AbsenceVacation, policy, 2024)
+	if balance.CarriedOver != 3 || balance.Taken != 0.5 || balance.Remaining() != 12.5 {
+		t.Errorf("unexpected 2024 balance %+v", balance)
+	}
+	if first := ComputeAbsenceBalance(schedule, AbsenceVacation, policy, 2022); first.CarriedOver != 0 || first.Taken != 5 {
+		t.Errorf("unexpected 2022 balance %+v", first)
+	}
+	if none := ComputeAbsenceBalance(schedule, AbsenceVacation, AbsencePolicy{Allowance: 10}, 2023); none.CarriedOver != 0 {
+		t.Errorf("expected nothing carried over without a carry-over rule, got %+v", none)
+	}
+
+	totals := schedule.AbsenceTotals(time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC))
+	if len(totals) != 2 || totals[0] != (AbsenceTotal{AbsenceVacation, 0.5}) || totals[1] != (AbsenceTotal{AbsenceSick, 1}) {
+		t.Errorf("unexpected August totals %+v", totals)
+	}
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// EntryTime is the part of a task inside a summary window.
+type EntryTime struct {
+	Task     *Task
+	Duration time.Duration
+	Clipped  bool // part of the task lies outside the window
+}
+
+// DescriptionSeries is the time of a project's tasks sharing a description,
+// with the entries making it up, earliest first.
+type DescriptionSeries struct {
+	Description string
+	Duration    time.Duration
+	Percentage  float64 // fraction of the largest description's duration (0.0–1.0)
+	Entries     []EntryTime
+}
+
+// ProjectBreakdown splits a project's time over a summary window by
+// description and entry.
+type ProjectBreakdown struct {
+	ProjectName  string
+	Duration     time.Duration
+	Descriptions []DescriptionSeries // largest first, description ascending as a tiebreaker
+	// Rounding is the time the project's rule adds to its daily totals,
+	// negative when it takes time off. Daily totals cannot be split between
+	// entries, so with a day rule the descriptions show the time as recorded
+	// and Duration includes Rounding.
+	Rounding time.Duration
+}
+
+// ComputeProjectBreakdown splits project's time over [start, end) by
+// description and task, clipping each task's active intervals to the window
+// as ComputeSummary does. With an entry rule each task's time per day is
+// rounded as in the summary; with a day rule the difference rounding makes
+// to the daily totals is kept in Rounding. Either way the parts add up to the
+// project's total there.
+func ComputeProjectBreakdown(tasks []*Task, project string, start, end time.Time, rules RoundingRules) ProjectBreakdown {
+	breakdown := ProjectBreakdown{ProjectName: project}
+	rule := rules.For(project)
+	var days map[time.Time]time.Duration
+	if rule.perDay() {
+		days = make(map[time.Time]time.Duration)
+	}
+
+	byDescription := make(map[string]*DescriptionSeries)
+	for _, task := range tasks {
+		if task.ProjectName != project {
+			continue
+		}
+		taskDays := taskDayDurations(task, start, end)
+		if len(taskDays) == 0 {
+			continue
+		}
+		var d, recorded time.Duration
+		for dayStart, dayDuration := range taskDays {
+			if days != nil {
+				days[dayStart] += dayDuration
+				d += dayDuration
+			} else {
+				d += rule.Round(dayDuration)
+			}
+			recorded += dayDuration
+		}
+		series, ok := byDescription[task.Description]
+		if !ok {
+			series = &DescriptionSeries{Description: task.Description}
+			byDescription[task.Description] = series
+		}
+		series.Entries = append(series.Entries, EntryTime{Task: task, Duration: d, Clipped: recorded < SumWithin(task.Intervals(), time.Time{}, time.Time{})})
+		series.Duration += d
+		breakdown.Duration += d
+	}
+	for _, dayDuration := range days {
+		breakdown.Rounding += rule.Round(dayDuration) - dayDuration
+	}
+	breakdown.Duration += breakdown.Rounding
+
+	var maxDuration time.Duration
+	for _, series := range byDescription {
+		sort.Slice(series.Entries, func(i, j int) bool {
+			return series.Entries[i].Task.StartTime.Before(series.Entries[j].Task.StartTime)
+		})
+		breakdown.Descriptions = append(breakdown.Descriptions, *series)
+		maxDuration = max(maxDuration, series.Duration)
+	}
+	descriptions := breakdown.Descriptions
+	sort.Slice(descriptions, func(i, j int) bool {
+		if descriptions[i].Duration != descriptions[j].Duration {
+			return descriptions[i].Duration > descriptions[j].Duration
+		}
+		return descriptions[i].Description < descriptions[j].Description
+	})
+	if maxDuration > 0 {
+		for i := range descriptions {
+			descriptions[i].Percentage = float64(descriptions[i].Duration) / float64(maxDuration)
+		}
+	}
+	return breakdown
+}
+
+// Description returns the series of description, or false when the
+// project has no time under it.
+func (b ProjectBreakdown) Description(description string) (DescriptionSeries, bool) {
+	for _, series := range b.Descriptions {
+		if series.Description == description {
+			return series, true
+		}
+	}
+	return DescriptionSeries{}, false
+}
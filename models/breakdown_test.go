@@ -0,0 +1,104 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func breakdownTasks() []*Task {
+	day := func(d, h, m int) time.Time { return time.Date(2026, 10, d, h, m, 0, 0, time.UTC) }
+	return []*Task{
+		{ProjectName: "Acme", Description: "API", StartTime: day(19, 9, 0), Duration: 50 * time.Minute},
+		{ProjectName: "Acme", Description: "Design", StartTime: day(20, 9, 0), Duration: 25 * time.Minute},
+		{ProjectName: "Acme", Description: "API", StartTime: day(20, 14, 0), Duration: 40 * time.Minute},
+		// Started the day before the window; only its last hour counts.
+		{ProjectName: "Acme", Description: "Ops", StartTime: day(18, 23, 0), Duration: 2 * time.Hour},
+		{ProjectName: "Other", Description: "API", StartTime: day(19, 10, 0), Duration: time.Hour},
+	}
+}
+
+func TestComputeProjectBreakdown(t *testing.T) {
+	tasks := breakdownTasks()
+	start, end := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC)
+
+	b := ComputeProjectBreakdown(tasks, "Acme", start, end, RoundingRules{})
+	if b.Duration != 2*time.Hour+55*time.Minute {
+		t.Fatalf("expected 2h 55m, got %v", b.Duration)
+	}
+	want := []struct {
+		description string
+		duration    time.Duration
+		entries     int
+	}{
+		{"API", 90 * time.Minute, 2},
+		{"Ops", time.Hour, 1},
+		{"Design", 25 * time.Minute, 1},
+	}
+	if len(b.Descriptions) != len(want) {
+		t.Fatalf("expected %d descriptions, got %+v", len(want), b.Descriptions)
+	}
+	for i, w := range want {
+		got := b.Descriptions[i]
+		if got.Description != w.description || got.Duration != w.duration || len(got.Entries) != w.entries {
+			t.Errorf("description %d: expected %s %v in %d entries, got %s %v in %d", i, w.description, w.duration, w.entries, got.Description, got.Duration, len(got.Entries))
+		}
+	}
+	api, ok := b.Description("API")
+	if !ok || api.Percentage != 1 || api.Entries[0].Task != tasks[0] || api.Entries[1].Duration != 40*time.Minute {
+		t.Errorf("expected the API entries earliest first, got %+v", api)
+	}
+	if ops, _ := b.Description("Ops"); ops.Entries[0].Duration != time.Hour || !ops.Entries[0].Clipped {
+		t.Errorf("expected the entry clipped to the window, got %+v", ops.Entries[0])
+	}
+	if api.Entries[0].Clipped {
+		t.Error("expected an entry inside the window not to be clipped")
+	}
+	if _, ok := b.Description("Testing"); ok {
+		t.Error("expected no series for a description without time")
+	}
+
+	// The parts add up to the summary's total for the project.
+	summary := ComputeSummary(tasks, start, end, BucketDay, time.Monday, RoundingRules{})
+	if summary.Projects[0].ProjectName != "Acme" || summary.Projects[0].Duration != b.Duration {
+		t.Errorf("expected the summary's %v, got %v", summary.Projects[0].Duration, b.Duration)
+	}
+}
+
+func TestComputeProjectBreakdown_Rounding(t *testing.T) {
+	tasks := breakdownTasks()
+	start, end := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC)
+
+	entry := RoundingRules{Default: RoundingRule{Increment: 15 * time.Minute, Mode: RoundUp, Per: RoundPerEntry}}
+	b := ComputeProjectBreakdown(tasks, "Acme", start, end, entry)
+	summary := ComputeSummary(tasks, start, end, BucketDay, time.Monday, entry)
+	if b.Rounding != 0 || b.Duration != summary.Projects[0].Duration {
+		t.Errorf("expected entry rounding to add up to the summary's %v, got %v", summary.Projects[0].Duration, b.Duration)
+	}
+	if design, _ := b.Description("Design"); design.Duration != 30*time.Minute {
+		t.Errorf("expected 25m rounded up to 30m, got %v", design.Duration)
+	}
+
+	day := RoundingRules{Default: RoundingRule{Increment: time.Hour, Mode: RoundUp, Per: RoundPerDay}}
+	b = ComputeProjectBreakdown(tasks, "Acme", start, end, day)
+	summary = ComputeSummary(tasks, start, end, BucketDay, time.Monday, day)
+	if b.Duration != summary.Projects[0].Duration {
+		t.Errorf("expected day rounding to add up to the summary's %v, got %v", summary.Projects[0].Duration, b.Duration)
+	}
+	var recorded time.Duration
+	for _, series := range b.Descriptions {
+		recorded += series.Duration
+	}
+	if recorded != 2*time.Hour+55*time.Minute || b.Rounding != b.Duration-recorded || b.Rounding <= 0 {
+		t.Errorf("expected the descriptions as recorded and the rest as rounding, got %v and %v", recorded, b.Rounding)
+	}
+	if design, _ := b.Description("Design"); design.Duration != 25*time.Minute {
+		t.Errorf("expected the entries of a day rule as recorded, got %v", design.Duration)
+	}
+
+	down := RoundingRules{Default: RoundingRule{Increment: time.Hour, Mode: RoundDown, Per: RoundPerDay}}
+	b = ComputeProjectBreakdown(tasks, "Acme", start, end, down)
+	summary = ComputeSummary(tasks, start, end, BucketDay, time.Monday, down)
+	if b.Rounding >= 0 || b.Duration != summary.Projects[0].Duration {
+		t.Errorf("expected rounding down to take time off to the summary's %v, got %v (rounding %v)", summary.Projects[0].Duration, b.Duration, b.Rounding)
+	}
+}
@@ -0,0 +1,209 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Budget periods. A total budget counts all time ever tracked on its
+// project; weekly and monthly ones start over each week or month.
+const (
+	BudgetTotal   = "total"
+	BudgetWeekly  = "weekly"
+	BudgetMonthly = "monthly"
+)
+
+// BudgetPeriods lists the budget periods in display order.
+var BudgetPeriods = []string{BudgetTotal, BudgetWeekly, BudgetMonthly}
+
+// DefaultBudgetAlerts are the percentages of a budget at which to notify
+// unless configured otherwise.
+var DefaultBudgetAlerts = []int{80, 100}
+
+var (
+	// ErrInvalidBudget is returned for budgets that are not a positive
+	// number of hours.
+	ErrInvalidBudget = errors.New("budget must be a positive number of hours")
+	// ErrInvalidBudgetAlerts is returned by ParseBudgetAlerts for input that
+	// is not a list of percentages.
+	ErrInvalidBudgetAlerts = errors.New("budget alerts must be percentages from 1 to 1000, such as 80, 100")
+)
+
+// BudgetPeriodLabel names a budget period for display.
+func BudgetPeriodLabel(period string) string {
+	switch period {
+	case BudgetTotal:
+		return "Total"
+	case BudgetWeekly:
+		return "Weekly"
+	case BudgetMonthly:
+		return "Monthly"
+	}
+	return period
+}
+
+// Budget is the most time a project may take, in total or per week or
+// month.
+type Budget struct {
+	ProjectName string
+	Limit       time.Duration
+	Period      string
+}
+
+// Validate checks that the budget has a project, a positive limit and a
+// known period.
+func (b Budget) Validate() error {
+	if strings.TrimSpace(b.ProjectName) == "" {
+		return errors.New("budget needs a project")
+	}
+	if b.Limit <= 0 {
+		return ErrInvalidBudget
+	}
+	if !slices.Contains(BudgetPeriods, b.Period) {
+		return fmt.Errorf("budget period must be one of %s", strings.Join(BudgetPeriods, ", "))
+	}
+	return nil
+}
+
+// Window returns the part of time the budget counts at now: the week or
+// month containing it, or zero times for total budgets.
+func (b Budget) Window(now time.Time, weekStart time.Weekday) (start, end time.Time) {
+	switch b.Period {
+	case BudgetWeekly:
+		p := PeriodOf(PeriodWeek, now, weekStart)
+		return p.Start, p.End
+	case BudgetMonthly:
+		p := PeriodOf(PeriodMonth, now, weekStart)
+		return p.Start, p.End
+	}
+	return time.Time{}, time.Time{}
+}
+
+// BudgetUsage is the time a budget's project took within the budget's
+// current window.
+type BudgetUsage struct {
+	Budget
+	Start, End time.Time // the window, zero for total budgets
+	Used       time.Duration
+	// RunsOut is when the budget runs out at the pace so far, zero when it
+	// is already used up, nothing was tracked yet, or it lasts past End.
+	RunsOut time.Time
+}
+
+// Fraction returns the used time as a fraction of the limit, which exceeds
+// 1 once the budget is overrun.
+func (u BudgetUsage) Fraction() float64 {
+	return EstimateProgress(u.Used, u.Limit)
+}
+
+// Remaining returns the time left on the budget, negative once overrun.
+func (u BudgetUsage) Remaining() time.Duration {
+	return u.Limit - u.Used
+}
+
+// ComputeBudgetUsage totals the time tracked on b's project in its window
+// at now, counting running up to now when it is not nil. The pace is taken
+// from the start of the window, or of the project's first task for total
+// budgets, and is at least a day long so that a quick start does not
+// forecast the end within hours.
+func ComputeBudgetUsage(tasks []*Task, running *Task, b Budget, now time.Time, weekStart time.Weekday) BudgetUsage {
+	u := BudgetUsage{Budget: b}
+	u.Start, u.End = b.Window(now, weekStart)
+	since := u.Start
+	count := func(intervals []Segment) {
+		u.Used += SumWithin(intervals, u.Start, u.End)
+		if !u.Start.IsZero() {
+			return
+		}
+		for _, interval := range intervals {
+			if since.IsZero() || interval.Start.Before(since) {
+				since = interval.Start
+			}
+		}
+	}
+	for _, task := range tasks {
+		if task.ProjectName == b.ProjectName {
+			count(task.Intervals())
+		}
+	}
+	if running != nil && running.ProjectName == b.ProjectName {
+		count(running.RunningIntervals(now))
+	}
+
+	if u.Used <= 0 || u.Used >= u.Limit {
+		return u
+	}
+	elapsed := max(now.Sub(since), 24*time.Hour)
+	left := time.Duration(float64(u.Remaining()) * float64(elapsed) / float64(u.Used))
+	if runsOut := now.Add(left); u.End.IsZero() || runsOut.Before(u.End) {
+		u.RunsOut = runsOut
+	}
+	return u
+}
+
+// BudgetAlert returns the highest of thresholds, in percent, that fraction
+// has reached, or 0 for none.
+func BudgetAlert(fraction float64, thresholds []int) int {
+	reached := 0
+	for _, threshold := range thresholds {
+		if fraction*100 >= float64(threshold) && threshold > reached {
+			reached = threshold
+		}
+	}
+	return reached
+}
+
+// FormatBudget describes a budget's usage, e.g. "12h 0m of 40h 0m this
+// week (30%) · runs out around Thu, Oct 22" or "45h 0m of 40h 0m (113%) ·
+// 5h 0m over".
+func FormatBudget(u BudgetUsage) string {
+	text := FormatEstimate(u.Used) + " of " + FormatEstimate(u.Limit)
+	switch u.Period {
+	case BudgetWeekly:
+		text += " this week"
+	case BudgetMonthly:
+		text += " this month"
+	}
+	text += fmt.Sprintf(" (%.0f%%)", math.Round(u.Fraction()*100))
+	switch {
+	case u.Remaining() < 0:
+		text += " · " + FormatEstimate(-u.Remaining()) + " over"
+	case !u.RunsOut.IsZero():
+		layout := "Mon, Jan 2"
+		if u.RunsOut.Year() != time.Now().Year() {
+			layout = "Jan 2, 2006"
+		}
+		text += " · runs out around " + u.RunsOut.Format(layout)
+	}
+	return text
+}
+
+// ParseBudgetAlerts parses percentages separated by commas or spaces, such
+// as "80, 100", into ascending order. Empty input turns alerts off.
+func ParseBudgetAlerts(s string) ([]int, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	alerts := make([]int, 0, len(fields))
+	for _, field := range fields {
+		percent, err := strconv.Atoi(strings.TrimSuffix(field, "%"))
+		if err != nil || percent < 1 || percent > 1000 {
+			return nil, ErrInvalidBudgetAlerts
+		}
+		alerts = append(alerts, percent)
+	}
+	slices.Sort(alerts)
+	return slices.Compact(alerts), nil
+}
+
+// FormatBudgetAlerts formats percentages as ParseBudgetAlerts reads them.
+func FormatBudgetAlerts(alerts []int) string {
+	parts := make([]string, len(alerts))
+	for i, percent := range alerts {
+		parts[i] = strconv.Itoa(percent)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestComputeBudgetUsage_Weekly(t *testing.T) {
+	now := time.Date(2026, 10, 21, 12, 0, 0, 0, time.UTC) // a Wednesday
+	tasks := []*Task{
+		{ProjectName: "Acme", StartTime: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), Duration: 2 * time.Hour},
+		{ProjectName: "Acme", StartTime: time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC), Duration: 3 * time.Hour},
+		// Last week and other projects do not count.
+		{ProjectName: "Acme", StartTime: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), Duration: 4 * time.Hour},
+		{ProjectName: "Other", StartTime: time.Date(2026, 10, 20, 13, 0, 0, 0, time.UTC), Duration: time.Hour},
+	}
+	running := &Task{ProjectName: "Acme", StartTime: time.Date(2026, 10, 21, 10, 0, 0, 0, time.UTC)}
+	budget := Budget{ProjectName: "Acme", Limit: 10 * time.Hour, Period: BudgetWeekly}
+
+	u := ComputeBudgetUsage(tasks, running, budget, now, time.Monday)
+	if u.Used != 7*time.Hour {
+		t.Fatalf("expected 7h used, got %v", u.Used)
+	}
+	if want := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC); !u.Start.Equal(want) || !u.End.Equal(want.AddDate(0, 0, 7)) {
+		t.Errorf("unexpected window %v – %v", u.Start, u.End)
+	}
+	// 7h in the 60h since Monday leaves 3h for another 60h*3/7.
+	if want := now.Add(3 * 60 * time.Hour / 7); !u.RunsOut.Equal(want) {
+		t.Errorf("expected the budget to run out at %v, got %v", want, u.RunsOut)
+	}
+
+	budget.Limit = 5 * time.Hour
+	u = ComputeBudgetUsage(tasks, running, budget, now, time.Monday)
+	if u.Remaining() != -2*time.Hour || !u.RunsOut.IsZero() {
+		t.Errorf("expected an overrun of 2h and no forecast, got %v and %v", u.Remaining(), u.RunsOut)
+	}
+
+	// At this pace 30h last beyond the week.
+	budget.Limit = 30 * time.Hour
+	if u := ComputeBudgetUsage(tasks, running, budget, now, time.Monday); !u.RunsOut.IsZero() {
+		t.Errorf("expected no forecast within the week, got %v", u.RunsOut)
+	}
+}
+
+func TestComputeBudgetUsage_Total(t *testing.T) {
+	now := time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC)
+	tasks := []*Task{
+		{ProjectName: "Acme", StartTime: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC), Duration: 6 * time.Hour},
+		{ProjectName: "Acme", StartTime: time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), Duration: 4 * time.Hour},
+	}
+	budget := Budget{ProjectName: "Acme", Limit: 20 * time.Hour, Period: BudgetTotal}
+
+	u := ComputeBudgetUsage(tasks, nil, budget, now, time.Monday)
+	if u.Used != 10*time.Hour || !u.Start.IsZero() || !u.End.IsZero() {
+		t.Fatalf("expected 10h used in no window, got %v in %v – %v", u.Used, u.Start, u.End)
+	}
+	// Half the budget in the 20 days since the first task.
+	if want := now.AddDate(0, 0, 20); !u.RunsOut.Equal(want) {
+		t.Errorf("expected the budget to run out at %v, got %v", want, u.RunsOut)
+	}
+	if u.Fraction() != 0.5 {
+		t.Errorf("expected half the budget used, got %v", u.Fraction())
+	}
+
+	if u := ComputeBudgetUsage(nil, nil, budget, now, time.Monday); u.Used != 0 || !u.RunsOut.IsZero() {
+		t.Errorf("expected an unused budget without a forecast, got %v and %v", u.Used, u.RunsOut)
+	}
+}
+
+func TestBudget_Validate(t *testing.T) {
+	if err := (Budget{ProjectName: "Acme", Limit: time.Hour, Period: BudgetMonthly}).Validate(); err != nil {
+		t.Errorf("expected a valid budget, got %v", err)
+	}
+	if err := (Budget{ProjectName: "Acme", Period: BudgetMonthly}).Validate(); !errors.Is(err, ErrInvalidBudget) {
+		t.Errorf("expected ErrInvalidBudget, got %v", err)
+	}
+	for _, b := range []Budget{
+		{Limit: time.Hour, Period: BudgetTotal},
+		{ProjectName: "Acme", Limit: time.Hour, Period: "daily"},
+	} {
+		if err := b.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", b)
+		}
+	}
+}
+
+func TestBudgetAlert(t *testing.T) {
+	thresholds := []int{80, 100}
+	for _, tc := range []struct {
+		fraction float64
+		want     int
+	}{
+		{0.79, 0},
+		{0.8, 80},
+		{0.99, 80},
+		{1.2, 100},
+	} {
+		if got := BudgetAlert(tc.fraction, thresholds); got != tc.want {
+			t.Errorf("BudgetAlert(%v) = %d; want %d", tc.fraction, got, tc.want)
+		}
+	}
+	if got := BudgetAlert(2, nil); got != 0 {
+		t.Errorf("expected no alert without thresholds, got %d", got)
+	}
+}
+
+func TestFormatBudget(t *testing.T) {
+	weekly := BudgetUsage{Budget: Budget{Limit: 40 * time.Hour, Period: BudgetWeekly}, Used: 12 * time.Hour}
+	if got, want := FormatBudget(weekly), "12h 0m of 40h 0m this week (30%)"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	weekly.RunsOut = time.Date(time.Now().Year(), 10, 22, 15, 0, 0, 0, time.Local)
+	if got, want := FormatBudget(weekly), "12h 0m of 40h 0m this week (30%) · runs out around "+weekly.RunsOut.Format("Mon, Jan 2"); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	total := BudgetUsage{Budget: Budget{Limit: 40 * time.Hour, Period: BudgetTotal}, Used: 45 * time.Hour}
+	if got, want := FormatBudget(total), "45h 0m of 40h 0m (113%) · 5h 0m over"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestParseBudgetAlerts(t *testing.T) {
+	for input, want := range map[string][]int{
+		"80, 100":   {80, 100},
+		"100 80 80": {80, 100},
+		"50%,90%":   {50, 90},
+		"":          {},
+	} {
+		got, err := ParseBudgetAlerts(input)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseBudgetAlerts(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"0", "eighty", "1001", "80;100"} {
+		if _, err := ParseBudgetAlerts(input); !errors.Is(err, ErrInvalidBudgetAlerts) {
+			t.Errorf("ParseBudgetAlerts(%q): expected ErrInvalidBudgetAlerts, got %v", input, err)
+		}
+	}
+	if got := FormatBudgetAlerts([]int{80, 100}); got != "80, 100" {
+		t.Errorf("expected %q, got %q", "80, 100", got)
+	}
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidComparison is returned by ParseComparison for unknown names.
+var ErrInvalidComparison = errors.New(`comparison must be "previous" or "last_year"`)
+
+// Comparison selects the period a summary is compared with.
+type Comparison int
+
+const (
+	CompareNone Comparison = iota
+	ComparePrevious
+	CompareLastYear
+)
+
+func (c Comparison) String() string {
+	switch c {
+	case ComparePrevious:
+		return "Previous period"
+	case CompareLastYear:
+		return "Same period last year"
+	default:
+		return "No comparison"
+	}
+}
+
+// ParseComparison parses the API names of comparisons: "previous",
+// "last_year", and "" or "none" for none, ignoring case.
+func ParseComparison(name string) (Comparison, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "none":
+		return CompareNone, nil
+	case "previous":
+		return ComparePrevious, nil
+	case "last_year":
+		return CompareLastYear, nil
+	}
+	return CompareNone, fmt.Errorf("%w: %q", ErrInvalidComparison, name)
+}
+
+// Compared returns the period p is compared with under c: the period before
+// it, or the same period a year earlier. A week a year earlier is the one 52
+// weeks before, starting on the same weekday; a day is the same date.
+func (p Period) Compared(c Comparison) Period {
+	switch c {
+	case ComparePrevious:
+		return p.Shift(-1)
+	case CompareLastYear:
+		if p.Kind == PeriodWeek {
+			return p.Shift(-52)
+		}
+		return Period{Kind: p.Kind, Start: p.Start.AddDate(-1, 0, 0), End: p.End.AddDate(-1, 0, 0)}
+	}
+	return p
+}
+
+// ProjectDelta is a project's time in a period next to its time in the
+// period it is compared with.
+type ProjectDelta struct {
+	ProjectName string
+	Current     time.Duration
+	Previous    time.Duration
+}
+
+// Change returns how much more time the project took than before; negative
+// for less.
+func (d ProjectDelta) Change() time.Duration {
+	return d.Current - d.Previous
+}
+
+// Percent returns the change as a percentage of the previous time, or false
+// when the project was not tracked before.
+func (d ProjectDelta) Percent() (float64, bool) {
+	if d.Previous <= 0 {
+		return 0, false
+	}
+	return 100 * float64(d.Change()) / float64(d.Previous), true
+}
+
+// CompareProjects pairs each project of current with its time in previous.
+// Projects keep the order of current, followed by those only tracked in
+// previous, largest first.
+func CompareProjects(current, previous []ProjectSeries) []ProjectDelta {
+	before := make(map[string]time.Duration, len(previous))
+	for _, p := range previous {
+		before[p.ProjectName] += p.Duration
+	}
+	deltas := make([]ProjectDelta, 0, len(current)+len(previous))
+	for _, p := range current {
+		deltas = append(deltas, ProjectDelta{ProjectName: p.ProjectName, Current: p.Duration, Previous: before[p.ProjectName]})
+		delete(before, p.ProjectName)
+	}
+	gone := make([]ProjectDelta, 0, len(before))
+	for name, d := range before {
+		gone = append(gone, ProjectDelta{ProjectName: name, Previous: d})
+	}
+	sort.Slice(gone, func(i, j int) bool {
+		if gone[i].Previous != gone[j].Previous {
+			return gone[i].Previous > gone[j].Previous
+		}
+		return gone[i].ProjectName < gone[j].ProjectName
+	})
+	return append(deltas, gone...)
+}
+
+// TotalDelta sums deltas into the change of all projects together.
+func TotalDelta(deltas []ProjectDelta) ProjectDelta {
+	var total ProjectDelta
+	for _, d := range deltas {
+		total.Current += d.Current
+		total.Previous += d.Previous
+	}
+	return total
+}
+
+// FormatDelta describes a change with an up or down arrow, e.g.
+// "▲ 2h 30m (+25%)", "▼ 45m (-10%)", "▲ 1h 0m (new)" or "= 0m".
+func FormatDelta(d ProjectDelta) string {
+	change := d.Change()
+	arrow := "="
+	switch {
+	case change > 0:
+		arrow = "▲"
+	case change < 0:
+		arrow = "▼"
+		change = -change
+	}
+	text := arrow + " " + FormatEstimate(change)
+	if percent, ok := d.Percent(); ok {
+		if change != 0 {
+			text += fmt.Sprintf(" (%+.0f%%)", math.Round(percent))
+		}
+	} else if d.Current > 0 {
+		text += " (new)"
+	}
+	return text
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPeriod_Compared(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	week := PeriodOf(PeriodWeek, day(2024, 3, 6), time.Monday) // Mar 4 – Mar 10
+	month := PeriodOf(PeriodMonth, day(2024, 3, 6), time.Monday)
+	custom, _ := CustomPeriod(day(2024, 2, 28), day(2024, 3, 1))
+	tests := []struct {
+		name       string
+		period     Period
+		comparison Comparison
+		start, end time.Time
+	}{
+		{"previous week", week, ComparePrevious, day(2024, 2, 26), day(2024, 3, 4)},
+		{"week last year", week, CompareLastYear, day(2023, 3, 6), day(2023, 3, 13)}, // a Monday too
+		{"previous month", month, ComparePrevious, day(2024, 2, 1), day(2024, 3, 1)},
+		{"month last year", month, CompareLastYear, day(2023, 3, 1), day(2023, 4, 1)},
+		{"previous custom", custom, ComparePrevious, day(2024, 2, 25), day(2024, 2, 28)},
+		{"none", week, CompareNone, week.Start, week.End},
+	}
+	for _, tt := range tests {
+		got := tt.period.Compared(tt.comparison)
+		if !got.Start.Equal(tt.start) || !got.End.Equal(tt.end) || got.Kind != tt.period.Kind {
+			t.Errorf("%s: got %v – %v (%v), want %v – %v", tt.name, got.Start, got.End, got.Kind, tt.start, tt.end)
+		}
+	}
+}
+
+func TestParseComparison(t *testing.T) {
+	for name, want := range map[string]Comparison{"": CompareNone, "none": CompareNone, "previous": ComparePrevious, "Last_Year": CompareLastYear} {
+		if got, err := ParseComparison(name); err != nil || got != want {
+			t.Errorf("ParseComparison(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseComparison("yesterday"); !errors.Is(err, ErrInvalidComparison) {
+		t.Errorf("expected ErrInvalidComparison, got %v", err)
+	}
+}
+
+func TestCompareProjects(t *testing.T) {
+	current := []ProjectSeries{
+		{ProjectName: "Alpha", Duration: 5 * time.Hour},
+		{ProjectName: "Beta", Duration: 90 * time.Minute},
+		{ProjectName: "Delta", Duration: time.Hour},
+	}
+	previous := []ProjectSeries{
+		{ProjectName: "Beta", Duration: 2 * time.Hour},
+		{ProjectName: "Alpha", Duration: 4 * time.Hour},
+		{ProjectName: "Gamma", Duration: 30 * time.Minute},
+		{ProjectName: "Epsilon", Duration: 3 * time.Hour},
+	}
+	deltas := CompareProjects(current, previous)
+	want := []struct {
+		name   string
+		format string
+	}{
+		{"Alpha", "▲ 1h 0m (+25%)"},
+		{"Beta", "▼ 30m (-25%)"},
+		{"Delta", "▲ 1h 0m (new)"},
+		{"Epsilon", "▼ 3h 0m (-100%)"},
+		{"Gamma", "▼ 30m (-100%)"},
+	}
+	if len(deltas) != len(want) {
+		t.Fatalf("expected %d deltas, got %+v", len(want), deltas)
+	}
+	for i, w := range want {
+		if deltas[i].ProjectName != w.name || FormatDelta(deltas[i]) != w.format {
+			t.Errorf("delta %d = %s %q, want %s %q", i, deltas[i].ProjectName, FormatDelta(deltas[i]), w.name, w.format)
+		}
+	}
+	if _, ok := deltas[2].Percent(); ok {
+		t.Error("expected no percentage for a new project")
+	}
+
+	total := TotalDelta(deltas)
+	if total.Current != 7*time.Hour+30*time.Minute || total.Previous != 9*time.Hour+30*time.Minute {
+		t.Errorf("unexpected total %+v", total)
+	}
+	if got := FormatDelta(ProjectDelta{Current: time.Hour, Previous: time.Hour}); got != "= 0m" {
+		t.Errorf("expected no change, got %q", got)
+	}
+}
@@ -0,0 +1,209 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Report formats of the digest files.
+const (
+	ReportMarkdown = "markdown"
+	ReportHTML     = "html"
+)
+
+// ReportFormats lists the report formats in display order.
+var ReportFormats = []string{ReportMarkdown, ReportHTML}
+
+// DefaultDigestTime is when digests are sent unless a digest time or an
+// end of day is configured.
+const DefaultDigestTime = 18 * time.Hour
+
+// ErrInvalidReportFormat is returned for report formats other than
+// ReportFormats.
+var ErrInvalidReportFormat = errors.New("report format must be one of " + strings.Join(ReportFormats, ", "))
+
+// DigestSettings say when digests are sent and where their reports go.
+type DigestSettings struct {
+	Enabled      bool
+	Time         string // "HH:MM", empty for the end of day or DefaultDigestTime
+	Weekly       bool   // send a weekly digest on the week's last workday
+	ReportDir    string // folder to write reports to, empty for none
+	ReportFormat string // one of ReportFormats
+	TimeZone     string // IANA name such as "Europe/Berlin", empty for local time
+}
+
+// DefaultDigestSettings returns the settings used unless configured
+// otherwise: digests off, weekly ones on once enabled, Markdown reports.
+func DefaultDigestSettings() DigestSettings {
+	return DigestSettings{Weekly: true, ReportFormat: ReportMarkdown}
+}
+
+// Validate checks the digest time, report format and time zone.
+func (s DigestSettings) Validate() error {
+	if _, err := ParseTimeOfDay(s.Time); err != nil {
+		return err
+	}
+	if !slices.Contains(ReportFormats, s.ReportFormat) {
+		return ErrInvalidReportFormat
+	}
+	_, err := LoadTimeZone(s.TimeZone)
+	return err
+}
+
+// LoadTimeZone returns the location named by an IANA time zone name, or
+// time.Local for an empty name.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q, expected a name such as Europe/Berlin", name)
+	}
+	return loc, nil
+}
+
+// Digest summarizes the time tracked per project over a day or a week
+// against the goal for it.
+type Digest struct {
+	Period   Period // a PeriodDay or PeriodWeek
+	Projects []ProjectSeries
+	Total    time.Duration
+	Goal     time.Duration // the schedule's target over the period, zero for none
+}
+
+// ComputeDigest totals the time tracked in the day or week of kind that
+// contains now, counting running up to now when it is not nil. Days and
+// weeks start at midnight in now's location.
+func ComputeDigest(tasks []*Task, running *Task, kind PeriodKind, now time.Time, schedule WorkSchedule, weekStart time.Weekday, rules RoundingRules) Digest {
+	period := PeriodOf(kind, now, weekStart)
+	if running != nil {
+		snapshot := *running
+		snapshot.Segments = running.RunningIntervals(now)
+		snapshot.Duration = running.Elapsed(now)
+		tasks = append(slices.Clip(tasks), &snapshot)
+	}
+	summary := ComputeSummary(tasks, period.Start, period.End, BucketDay, weekStart, rules)
+	d := Digest{Period: period, Projects: summary.Projects}
+	for _, project := range summary.Projects {
+		d.Total += project.Duration
+	}
+	for _, target := range schedule.BucketTargets(summary.Buckets) {
+		d.Goal += target
+	}
+	return d
+}
+
+// DigestsDue returns the kinds of digest to send at now, given when the
+// last ones were sent: the daily digest once a workday reaches at, its time
+// of day, and the weekly one along with it on the week's last workday when
+// weekly is set. Days are taken in now's location.
+func DigestsDue(now, lastSent time.Time, at time.Duration, schedule WorkSchedule, weekStart time.Weekday, weekly bool) []PeriodKind {
+	today := PeriodOf(PeriodDay, now, weekStart)
+	if now.Before(today.Start.Add(at)) || today.Contains(lastSent) || !schedule.Workday(today.Start) {
+		return nil
+	}
+	due := []PeriodKind{PeriodDay}
+	if last, ok := schedule.LastWorkday(now, weekStart); weekly && ok && last.Equal(today.Start) {
+		due = append(due, PeriodWeek)
+	}
+	return due
+}
+
+// Title names the digest for a notification, e.g. "Daily Digest".
+func (d Digest) Title() string {
+	if d.Period.Kind == PeriodWeek {
+		return "Weekly Digest"
+	}
+	return "Daily Digest"
+}
+
+// progress describes the total against the goal, e.g. "6h 30m of 8h 0m
+// (81%)", or just the total when there is no goal.
+func (d Digest) progress() string {
+	if d.Goal <= 0 {
+		return FormatEstimate(d.Total) + " tracked"
+	}
+	return fmt.Sprintf("%s of %s (%.0f%%)", FormatEstimate(d.Total), FormatEstimate(d.Goal),
+		math.Round(EstimateProgress(d.Total, d.Goal)*100))
+}
+
+// Message describes the digest in a line for a notification, e.g. "6h 30m
+// of 8h 0m (81%) · Acme 4h 0m, Beta 2h 30m".
+func (d Digest) Message() string {
+	if len(d.Projects) == 0 {
+		return "Nothing tracked · " + d.progress()
+	}
+	parts := make([]string, len(d.Projects))
+	for i, project := range d.Projects {
+		parts[i] = project.ProjectName + " " + FormatEstimate(project.Duration)
+	}
+	return d.progress() + " · " + strings.Join(parts, ", ")
+}
+
+// share returns a project's part of the total in whole percent.
+func (d Digest) share(project ProjectSeries) float64 {
+	if d.Total <= 0 {
+		return 0
+	}
+	return math.Round(float64(project.Duration) / float64(d.Total) * 100)
+}
+
+// Markdown renders the digest as a Markdown report.
+func (d Digest) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s: %s\n\n", d.Title(), d.Period.Label())
+	if len(d.Projects) == 0 {
+		b.WriteString("Nothing tracked.\n\n")
+	} else {
+		b.WriteString("| Project | Time | Share |\n|---|---:|---:|\n")
+		for _, project := range d.Projects {
+			name := strings.ReplaceAll(project.ProjectName, "|", `\|`)
+			fmt.Fprintf(&b, "| %s | %s | %.0f%% |\n", name, FormatEstimate(project.Duration), d.share(project))
+		}
+		fmt.Fprintf(&b, "| **Total** | **%s** | |\n\n", FormatEstimate(d.Total))
+	}
+	fmt.Fprintf(&b, "%s.\n", d.progress())
+	return b.String()
+}
+
+// HTML renders the digest as a standalone HTML report.
+func (d Digest) HTML() string {
+	title := html.EscapeString(d.Title() + ": " + d.Period.Label())
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n", title, title)
+	if len(d.Projects) == 0 {
+		b.WriteString("<p>Nothing tracked.</p>\n")
+	} else {
+		b.WriteString("<table>\n<tr><th>Project</th><th>Time</th><th>Share</th></tr>\n")
+		for _, project := range d.Projects {
+			fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%.0f%%</td></tr>\n",
+				html.EscapeString(project.ProjectName), FormatEstimate(project.Duration), d.share(project))
+		}
+		fmt.Fprintf(&b, "<tr><th>Total</th><th>%s</th><th></th></tr>\n</table>\n", FormatEstimate(d.Total))
+	}
+	fmt.Fprintf(&b, "<p>%s.</p>\n</body>\n</html>\n", d.progress())
+	return b.String()
+}
+
+// Report renders the digest in format, with the name of the file to write it
+// to, e.g. "digest-2026-10-19.md" or "digest-week-2026-10-19.html" for the
+// week starting that day.
+func (d Digest) Report(format string) (name, content string, err error) {
+	name = "digest-" + d.Period.Start.Format(time.DateOnly)
+	if d.Period.Kind == PeriodWeek {
+		name = "digest-week-" + d.Period.Start.Format(time.DateOnly)
+	}
+	switch format {
+	case ReportMarkdown:
+		return name + ".md", d.Markdown(), nil
+	case ReportHTML:
+		return name + ".html", d.HTML(), nil
+	}
+	return "", "", ErrInvalidReportFormat
+}
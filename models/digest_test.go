@@ -0,0 +1,144 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func digestSchedule() WorkSchedule {
+	return WorkSchedule{Weekdays: DefaultWeekdays(8 * time.Hour)}
+}
+
+func TestComputeDigest(t *testing.T) {
+	at := func(d, h, m int) time.Time { return time.Date(2026, 10, d, h, m, 0, 0, time.UTC) }
+	tasks := []*Task{
+		{ProjectName: "Acme", StartTime: at(19, 9, 0), Duration: 3 * time.Hour},
+		{ProjectName: "Beta", StartTime: at(19, 13, 0), Duration: 90 * time.Minute},
+		{ProjectName: "Acme", StartTime: at(20, 9, 0), Duration: 2 * time.Hour},
+	}
+	running := &Task{ProjectName: "Beta", StartTime: at(20, 14, 0)}
+	now := at(20, 15, 0)
+
+	day := ComputeDigest(tasks, running, PeriodDay, now, digestSchedule(), time.Monday, RoundingRules{})
+	if day.Total != 3*time.Hour || day.Goal != 8*time.Hour || len(day.Projects) != 2 {
+		t.Fatalf("expected 3h of 8h over two projects counting the running task, got %+v", day)
+	}
+	if got, want := day.Message(), "3h 0m of 8h 0m (38%) · Acme 2h 0m, Beta 1h 0m"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+	if len(tasks) != 3 {
+		t.Error("expected the tasks not to be changed")
+	}
+
+	week := ComputeDigest(tasks, nil, PeriodWeek, now, digestSchedule(), time.Monday, RoundingRules{})
+	if week.Total != 6*time.Hour+30*time.Minute || week.Goal != 40*time.Hour || week.Title() != "Weekly Digest" {
+		t.Errorf("expected 6h 30m of 40h in the week, got %+v", week)
+	}
+
+	empty := ComputeDigest(nil, nil, PeriodDay, at(24, 18, 0), digestSchedule(), time.Monday, RoundingRules{})
+	if got, want := empty.Message(), "Nothing tracked · 0m tracked"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+}
+
+func TestDigestsDue(t *testing.T) {
+	schedule := digestSchedule()
+	// Friday, Oct 23 is a holiday, so Thursday ends the week.
+	holidays := WorkSchedule{Weekdays: schedule.Weekdays, Holidays: []Holiday{{Date: time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)}}}
+	at := func(d, h int) time.Time { return time.Date(2026, 10, d, h, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name     string
+		now      time.Time
+		lastSent time.Time
+		schedule WorkSchedule
+		weekly   bool
+		want     []PeriodKind
+	}{
+		{"before the time", at(19, 17), time.Time{}, schedule, true, nil},
+		{"workday", at(19, 18), time.Time{}, schedule, true, []PeriodKind{PeriodDay}},
+		{"already sent", at(19, 20), at(19, 18), schedule, true, nil},
+		{"sent yesterday", at(20, 18), at(19, 18), schedule, true, []PeriodKind{PeriodDay}},
+		{"friday", at(23, 18), time.Time{}, schedule, true, []PeriodKind{PeriodDay, PeriodWeek}},
+		{"weekly off", at(23, 18), time.Time{}, schedule, false, []PeriodKind{PeriodDay}},
+		{"weekend", at(24, 18), time.Time{}, schedule, true, nil},
+		{"last workday before a holiday", at(22, 18), time.Time{}, holidays, true, []PeriodKind{PeriodDay, PeriodWeek}},
+		{"holiday", at(23, 18), time.Time{}, holidays, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DigestsDue(tt.now, tt.lastSent, 18*time.Hour, tt.schedule, time.Monday, tt.weekly)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DigestsDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDigestsDue_Location(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	// 10:00 UTC on Monday is 19:00 in Tokyo, after its digest time.
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	if got := DigestsDue(now, time.Time{}, 18*time.Hour, digestSchedule(), time.Monday, true); got != nil {
+		t.Errorf("expected nothing due at 10:00 UTC, got %v", got)
+	}
+	if got := DigestsDue(now.In(tokyo), time.Time{}, 18*time.Hour, digestSchedule(), time.Monday, true); len(got) != 1 {
+		t.Errorf("expected the daily digest due at 19:00 in Tokyo, got %v", got)
+	}
+}
+
+func TestDigest_Report(t *testing.T) {
+	d := Digest{
+		Period:   PeriodOf(PeriodWeek, time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC), time.Monday),
+		Projects: []ProjectSeries{{ProjectName: "A|B <x>", Duration: 3 * time.Hour}, {ProjectName: "Beta", Duration: time.Hour}},
+		Total:    4 * time.Hour,
+		Goal:     8 * time.Hour,
+	}
+
+	name, md, err := d.Report(ReportMarkdown)
+	if err != nil || name != "digest-week-2026-10-19.md" {
+		t.Fatalf("expected the week's Markdown file, got %q, %v", name, err)
+	}
+	for _, want := range []string{"# Weekly Digest: Week 43 · Oct 19 – Oct 25, 2026", `| A\|B <x> | 3h 0m | 75% |`, "| **Total** | **4h 0m** | |", "4h 0m of 8h 0m (50%)."} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected the Markdown to contain %q, got:\n%s", want, md)
+		}
+	}
+
+	name, page, err := d.Report(ReportHTML)
+	if err != nil || name != "digest-week-2026-10-19.html" {
+		t.Fatalf("expected the week's HTML file, got %q, %v", name, err)
+	}
+	if !strings.Contains(page, "<td>A|B &lt;x&gt;</td>") || strings.Contains(page, "<x>") {
+		t.Errorf("expected project names escaped, got:\n%s", page)
+	}
+
+	if _, _, err := d.Report("pdf"); err != ErrInvalidReportFormat {
+		t.Errorf("expected ErrInvalidReportFormat, got %v", err)
+	}
+	d.Period = PeriodOf(PeriodDay, d.Period.Start, time.Monday)
+	if name, _, _ := d.Report(ReportMarkdown); name != "digest-2026-10-19.md" {
+		t.Errorf("expected the day's file, got %q", name)
+	}
+}
+
+func TestDigestSettings_Validate(t *testing.T) {
+	valid := DefaultDigestSettings()
+	valid.Time, valid.TimeZone = "17:30", "Europe/Berlin"
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected valid settings, got %v", err)
+	}
+	for _, s := range []DigestSettings{
+		{Time: "5pm", ReportFormat: ReportMarkdown},
+		{ReportFormat: "pdf"},
+		{ReportFormat: ReportHTML, TimeZone: "Mars/Olympus"},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", s)
+		}
+	}
+	if loc, err := LoadTimeZone(""); err != nil || loc != time.Local {
+		t.Errorf("expected local time for no time zone, got %v, %v", loc, err)
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidEstimate is returned by ParseEstimate for input that is not a
+// positive duration.
+var ErrInvalidEstimate = errors.New("estimate must be a positive duration such as 45m or 1h30m")
+
+// ParseEstimate parses a time estimate such as "45m", "1h 30m" or "1.5h". A
+// bare number counts minutes and empty input means no estimate.
+func ParseEstimate(s string) (time.Duration, error) {
+	s = strings.ReplaceAll(s, " ", "")
+	if s == "" {
+		return 0, nil
+	}
+	if minutes, err := strconv.ParseFloat(s, 64); err == nil {
+		s = strconv.FormatFloat(minutes, 'f', -1, 64) + "m"
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, ErrInvalidEstimate
+	}
+	return d.Round(time.Second), nil
+}
+
+// EstimateProgress returns elapsed as a fraction of estimate, which exceeds
+// 1 once the estimate is overrun. It is 0 without an estimate.
+func EstimateProgress(elapsed, estimate time.Duration) float64 {
+	if estimate <= 0 {
+		return 0
+	}
+	return float64(elapsed) / float64(estimate)
+}
+
+// FormatEstimate formats d as "Xh Ym" or "Ym", rounded to the minute.
+func FormatEstimate(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%dh %dm", h, m)
+	}
+	return fmt.Sprintf("%dm", m)
+}
+
+// FormatEstimateComparison describes actual time against the estimate for
+// it, e.g. "Estimated 2h 0m · actual 2h 30m (+25%)", or returns "" when
+// nothing was estimated.
+func FormatEstimateComparison(estimated, actual time.Duration) string {
+	if estimated <= 0 {
+		return ""
+	}
+	off := int(math.Round((EstimateProgress(actual, estimated) - 1) * 100))
+	return fmt.Sprintf("Estimated %s · actual %s (%+d%%)",
+		FormatEstimate(estimated), FormatEstimate(actual), off)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseEstimate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"45m", 45 * time.Minute},
+		{" 1h30m ", 90 * time.Minute},
+		{"1h 30m", 90 * time.Minute},
+		{"1.5h", 90 * time.Minute},
+		{"20", 20 * time.Minute},
+		{"2.5", 150 * time.Second},
+	}
+	for _, tc := range tests {
+		got, err := ParseEstimate(tc.input)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseEstimate(%q) = %v, %v; want %v", tc.input, got, err, tc.want)
+		}
+	}
+	for _, input := range []string{"soon", "0", "-10m", "1x"} {
+		if _, err := ParseEstimate(input); !errors.Is(err, ErrInvalidEstimate) {
+			t.Errorf("ParseEstimate(%q): expected ErrInvalidEstimate, got %v", input, err)
+		}
+	}
+}
+
+func TestFormatEstimateComparison(t *testing.T) {
+	if got := FormatEstimateComparison(0, time.Hour); got != "" {
+		t.Errorf("expected no comparison without an estimate, got %q", got)
+	}
+	if got, want := FormatEstimateComparison(2*time.Hour, 150*time.Minute), "Estimated 2h 0m · actual 2h 30m (+25%)"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got, want := FormatEstimateComparison(time.Hour, 45*time.Minute), "Estimated 1h 0m · actual 45m (-25%)"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidFlexAmount is returned by ParseFlexAmount for input that is not a
+// signed duration.
+var ErrInvalidFlexAmount = errors.New("amount must be a duration such as -7h30m or +2h")
+
+// FlexCorrection is a manual change to the flex-time balance, such as
+// negative paid-out overtime.
+type FlexCorrection struct {
+	ID     int64
+	Date   time.Time // the day it applies to
+	Amount time.Duration
+	Note   string
+}
+
+// FlexDay is one day of the flex-time ledger.
+type FlexDay struct {
+	Date        time.Time // midnight
+	Tracked     time.Duration
+	Target      time.Duration
+	Corrections []FlexCorrection
+	Balance     time.Duration // running balance at the end of the day
+
+	// Open marks the current day, whose shortfall against the target only
+	// counts once it is over.
+	Open bool
+}
+
+// Difference returns the day's contribution to the balance from tracked
+// time, not counting corrections.
+func (d FlexDay) Difference() time.Duration {
+	diff := d.Tracked - d.Target
+	if d.Open && diff < 0 {
+		return 0
+	}
+	return diff
+}
+
+// Corrected returns the sum of the day's corrections.
+func (d FlexDay) Corrected() time.Duration {
+	var sum time.Duration
+	for _, c := range d.Corrections {
+		sum += c.Amount
+	}
+	return sum
+}
+
+// FlexLedger is the flex-time balance day by day from its start date.
+type FlexLedger struct {
+	Start   time.Time // midnight of the first day
+	Opening time.Duration
+	Days    []FlexDay // from Start through the current day
+}
+
+// ComputeFlexLedger sums each day's tracked time minus its target under
+// schedule, plus the day's corrections, from the day of start through the day
+// of now, starting from the opening balance. Time tracked after now and
+// corrections outside that range are left out.
+func ComputeFlexLedger(tasks []*Task, corrections []FlexCorrection, schedule WorkSchedule, start time.Time, opening time.Duration, now time.Time) FlexLedger {
+	loc := now.Location()
+	first := PeriodOf(PeriodDay, start.In(loc), time.Monday).Start
+	today := PeriodOf(PeriodDay, now, time.Monday)
+	ledger := FlexLedger{Start: first, Opening: opening}
+	if first.After(today.Start) {
+		return ledger
+	}
+
+	tracked := make(map[time.Time]time.Duration)
+	for _, task := range tasks {
+		for day, d := range taskDayDurations(task, first, now) {
+			tracked[dayOf(day, loc)] += d
+		}
+	}
+	corrected := make(map[time.Time][]FlexCorrection)
+	for _, c := range corrections {
+		day := dayOf(c.Date, loc)
+		if !day.Before(first) && !day.After(today.Start) {
+			corrected[day] = append(corrected[day], c)
+		}
+	}
+
+	balance := opening
+	for day := first; !day.After(today.Start); day = day.AddDate(0, 0, 1) {
+		entry := FlexDay{
+			Date:        day,
+			Tracked:     tracked[day],
+			Target:      schedule.Target(day),
+			Corrections: corrected[day],
+			Open:        day.Equal(today.Start),
+		}
+		balance += entry.Difference() + entry.Corrected()
+		entry.Balance = balance
+		ledger.Days = append(ledger.Days, entry)
+	}
+	return ledger
+}
+
+// dayOf returns midnight of t's day in loc.
+func dayOf(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// Balance returns the balance at the end of the ledger, or the opening
+// balance when it has no days yet.
+func (l FlexLedger) Balance() time.Duration {
+	if len(l.Days) == 0 {
+		return l.Opening
+	}
+	return l.Days[len(l.Days)-1].Balance
+}
+
+// BalanceWithToday returns the balance with the current day's tracked time
+// replaced by tracked, so that a running timer can be counted as it runs.
+func (l FlexLedger) BalanceWithToday(tracked time.Duration) time.Duration {
+	if len(l.Days) == 0 {
+		return l.Opening
+	}
+	last := l.Days[len(l.Days)-1]
+	if !last.Open {
+		return last.Balance
+	}
+	updated := last
+	updated.Tracked = tracked
+	return last.Balance - last.Difference() + updated.Difference()
+}
+
+// Month returns the ledger's days in the month of period, along with the
+// balance before the first of them.
+func (l FlexLedger) Month(period Period) (opening time.Duration, days []FlexDay) {
+	opening = l.Opening
+	for _, day := range l.Days {
+		switch {
+		case day.Date.Before(period.Start):
+			opening = day.Balance
+		case day.Date.Before(period.End):
+			days = append(days, day)
+		}
+	}
+	return opening, days
+}
+
+// ParseFlexAmount parses a signed duration such as "-7h30m", "+2h" or "1.5h".
+// A bare number counts minutes and no sign means a positive amount.
+func ParseFlexAmount(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"), strings.HasPrefix(s, "−"):
+		sign = -1
+		s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "−")
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	d, err := ParseEstimate(s)
+	if err != nil || d == 0 {
+		return 0, ErrInvalidFlexAmount
+	}
+	return sign * d, nil
+}
+
+// FormatFlex formats a balance or difference with its sign, e.g. "+1h 30m",
+// "-45m" or "0m", rounded to the minute.
+func FormatFlex(d time.Duration) string {
+	d = d.Round(time.Minute)
+	switch {
+	case d > 0:
+		return "+" + FormatEstimate(d)
+	case d < 0:
+		return "-" + FormatEstimate(-d)
+	}
+	return "0m"
+}
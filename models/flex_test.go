@@ -0,0 +1,91 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestComputeFlexLedger(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2024, 8, day, hour, 0, 0, 0, time.UTC) }
+	task := func(day, hours int) *Task {
+		return &Task{ProjectName: "Work", StartTime: at(day, 9), Duration: time.Duration(hours) * time.Hour}
+	}
+	tasks := []*Task{
+		task(9, 9),  // Fri before the start date
+		task(12, 9), // Mon +1h
+		task(13, 7), // Tue -1h
+		task(14, 8), // Wed ±0
+		task(17, 2), // Sat +2h
+		task(20, 3), // Tue, still running the day
+	}
+	corrections := []FlexCorrection{
+		{Date: at(16, 0), Amount: -3 * time.Hour, Note: "paid out"},
+		{Date: at(21, 0), Amount: time.Hour}, // after now
+	}
+	schedule := WorkSchedule{Weekdays: DefaultWeekdays(8 * time.Hour)}
+	now := at(20, 15)
+
+	ledger := ComputeFlexLedger(tasks, corrections, schedule, at(12, 0), 5*time.Hour, now)
+	if len(ledger.Days) != 9 {
+		t.Fatalf("expected 9 days from Aug 12 through Aug 20, got %d", len(ledger.Days))
+	}
+	// +5 opening +1 -1 ±0 -8 (Thu) -8 -3 (Fri) +2 (Sat) ±0 (Sun) -8 (Mon),
+	// with the open Tuesday not counting yet.
+	if got := ledger.Balance(); got != -20*time.Hour {
+		t.Errorf("Balance() = %v, want -20h", got)
+	}
+	if fri := ledger.Days[4]; fri.Corrected() != -3*time.Hour || fri.Balance != -14*time.Hour {
+		t.Errorf("unexpected Friday %+v", fri)
+	}
+	today := ledger.Days[8]
+	if !today.Open || today.Tracked != 3*time.Hour || today.Difference() != 0 {
+		t.Errorf("unexpected open day %+v", today)
+	}
+	if got := ledger.BalanceWithToday(10 * time.Hour); got != -18*time.Hour {
+		t.Errorf("BalanceWithToday(10h) = %v, want -18h", got)
+	}
+
+	opening, days := ledger.Month(PeriodOf(PeriodWeek, at(19, 0), time.Monday))
+	if opening != -12*time.Hour || len(days) != 2 {
+		t.Errorf("expected 2 days after a -12h balance, got %d after %v", len(days), opening)
+	}
+
+	if future := ComputeFlexLedger(tasks, nil, schedule, at(21, 0), time.Hour, now); future.Balance() != time.Hour || future.Days != nil {
+		t.Errorf("expected only the opening balance before the start date, got %+v", future)
+	}
+}
+
+func TestParseFlexAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"-7h30m", -7*time.Hour - 30*time.Minute},
+		{"+2h", 2 * time.Hour},
+		{"1.5h", 90 * time.Minute},
+		{" -45 ", -45 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got, err := ParseFlexAmount(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseFlexAmount(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "-", "soon"} {
+		if _, err := ParseFlexAmount(in); !errors.Is(err, ErrInvalidFlexAmount) {
+			t.Errorf("ParseFlexAmount(%q): expected ErrInvalidFlexAmount, got %v", in, err)
+		}
+	}
+}
+
+func TestFormatFlex(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		90 * time.Minute:  "+1h 30m",
+		-45 * time.Minute: "-45m",
+		20 * time.Second:  "0m",
+	} {
+		if got := FormatFlex(d); got != want {
+			t.Errorf("FormatFlex(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
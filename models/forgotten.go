@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ForgottenRules decide when a running timer was probably forgotten.
+type ForgottenRules struct {
+	// MaxContinuous is the longest an active interval may run without a
+	// pause. Zero disables the rule.
+	MaxContinuous time.Duration
+	// EndOfDay is the time of day, as an offset from midnight, after which
+	// a timer should no longer be running. Zero disables the rule.
+	EndOfDay time.Duration
+}
+
+// ForgottenTimer describes a triggered rule.
+type ForgottenTimer struct {
+	// Reason explains the rule in a sentence fragment.
+	Reason string
+	// Cutoff is when the rule was first broken, a sensible end for the task.
+	Cutoff time.Time
+}
+
+// Check reports whether the running task t breaks a rule at now. When both
+// rules hold, the earlier cutoff wins.
+func (r ForgottenRules) Check(t *Task, now time.Time) (ForgottenTimer, bool) {
+	if t == nil || t.IsPaused() {
+		return ForgottenTimer{}, false
+	}
+	since := t.ActiveSince()
+
+	var found ForgottenTimer
+	ok := false
+	consider := func(cutoff time.Time, reason string) {
+		if now.After(cutoff) && (!ok || cutoff.Before(found.Cutoff)) {
+			found, ok = ForgottenTimer{Reason: reason, Cutoff: cutoff}, true
+		}
+	}
+
+	if r.MaxContinuous > 0 {
+		consider(since.Add(r.MaxContinuous),
+			fmt.Sprintf("has been running for more than %s without a break", formatHours(r.MaxContinuous)))
+	}
+	if r.EndOfDay > 0 {
+		y, m, d := since.Date()
+		endOfDay := time.Date(y, m, d, 0, 0, 0, 0, since.Location()).Add(r.EndOfDay)
+		if !endOfDay.After(since) {
+			// Started after hours: the rule applies from the next day's end.
+			endOfDay = time.Date(y, m, d+1, 0, 0, 0, 0, since.Location()).Add(r.EndOfDay)
+		}
+		consider(endOfDay, "is still running after the end of the day at "+endOfDay.Format("15:04"))
+	}
+	return found, ok
+}
+
+// ParseTimeOfDay parses "HH:MM" into an offset from midnight. An empty
+// string yields zero.
+func ParseTimeOfDay(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatHours(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
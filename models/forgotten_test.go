@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForgottenRules_Check(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	running := func() *Task { return &Task{ProjectName: "P", StartTime: start, EndTime: start} }
+
+	rules := ForgottenRules{MaxContinuous: 10 * time.Hour}
+	if _, ok := rules.Check(running(), start.Add(9*time.Hour)); ok {
+		t.Error("expected no trigger below the maximum length")
+	}
+	got, ok := rules.Check(running(), start.Add(14*time.Hour))
+	if !ok || !got.Cutoff.Equal(start.Add(10*time.Hour)) {
+		t.Errorf("expected cutoff at 19:00, got %v (%v)", got.Cutoff, ok)
+	}
+
+	// A pause breaks the continuous run.
+	paused := running()
+	paused.Pause(start.Add(4 * time.Hour))
+	paused.Resume(start.Add(5 * time.Hour))
+	if _, ok := rules.Check(paused, start.Add(14*time.Hour)); ok {
+		t.Error("expected a resumed task to be measured from its resume")
+	}
+	paused.Pause(start.Add(14 * time.Hour))
+	if _, ok := rules.Check(paused, start.Add(20*time.Hour)); ok {
+		t.Error("expected paused tasks never to trigger")
+	}
+
+	rules.EndOfDay = 18 * time.Hour
+	got, ok = rules.Check(running(), start.Add(14*time.Hour))
+	if !ok || !got.Cutoff.Equal(start.Add(9*time.Hour)) {
+		t.Errorf("expected the earlier end-of-day cutoff at 18:00, got %v (%v)", got.Cutoff, ok)
+	}
+
+	late := &Task{ProjectName: "P", StartTime: start.Add(11 * time.Hour)}
+	if _, ok := (ForgottenRules{EndOfDay: 18 * time.Hour}).Check(late, start.Add(14*time.Hour)); ok {
+		t.Error("expected a task started after hours to use the next day's end")
+	}
+
+	if _, ok := (ForgottenRules{}).Check(running(), start.Add(48*time.Hour)); ok {
+		t.Error("expected no trigger with both rules disabled")
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	if d, err := ParseTimeOfDay("18:30"); err != nil || d != 18*time.Hour+30*time.Minute {
+		t.Errorf("expected 18h30m, got %v (%v)", d, err)
+	}
+	if d, err := ParseTimeOfDay(""); err != nil || d != 0 {
+		t.Errorf("expected empty to disable, got %v (%v)", d, err)
+	}
+	if _, err := ParseTimeOfDay("25:00"); err == nil {
+		t.Error("expected an error for 25:00")
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// HeatLevels is the number of shades of a heatmap day with tracked time.
+const HeatLevels = 4
+
+// HeatmapDay is the time tracked on one calendar day of a heatmap.
+type HeatmapDay struct {
+	Date    time.Time // midnight
+	Tracked time.Duration
+	Goal    time.Duration // the time Tracked is measured against
+}
+
+// Level returns the shade of the day from 0 for no tracked time to
+// HeatLevels once the goal is reached. Days without a goal take the darkest
+// shade for any tracked time.
+func (d HeatmapDay) Level() int {
+	switch {
+	case d.Tracked <= 0:
+		return 0
+	case d.Tracked >= d.Goal:
+		return HeatLevels
+	}
+	return 1 + int(float64(HeatLevels-1)*float64(d.Tracked)/float64(d.Goal))
+}
+
+// HeatmapRange returns the first and last day of a heatmap of the year up to
+// now: from the start of the week holding the same day a year ago, so that
+// the heatmap's columns are whole weeks, through today.
+func HeatmapRange(now time.Time, weekStart time.Weekday) (first, last time.Time) {
+	y, m, d := now.Date()
+	last = time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	return StartOfWeek(last.AddDate(-1, 0, 1), weekStart), last
+}
+
+// ComputeHeatmap returns the time tracked on each calendar day from first
+// through last, in first's timezone, counting only project unless it is
+// empty. Each day's goal is its target under schedule, or the longest
+// weekday target on days off so that work on them still shows.
+func ComputeHeatmap(tasks []*Task, schedule WorkSchedule, first, last time.Time, project string) []HeatmapDay {
+	loc := first.Location()
+	y, m, d := first.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	y, m, d = last.In(loc).Date()
+	end := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+
+	var fallback time.Duration
+	for _, target := range schedule.Weekdays {
+		fallback = max(fallback, target)
+	}
+
+	var days []HeatmapDay
+	index := make(map[time.Time]int)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		goal := schedule.Target(day)
+		if goal <= 0 {
+			goal = fallback
+		}
+		index[day] = len(days)
+		days = append(days, HeatmapDay{Date: day, Goal: goal})
+	}
+
+	for _, task := range tasks {
+		if project != "" && task.ProjectName != project {
+			continue
+		}
+		for dayStart, tracked := range taskDayDurations(task, start, end) {
+			y, m, d := dayStart.In(loc).Date()
+			if i, ok := index[time.Date(y, m, d, 0, 0, 0, 0, loc)]; ok {
+				days[i].Tracked += tracked
+			}
+		}
+	}
+	return days
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // Europe/Berlin on systems without a zoneinfo database
+)
+
+func TestComputeHeatmap_DST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	at := func(m time.Month, d, h int) time.Time { return time.Date(2024, m, d, h, 0, 0, 0, berlin) }
+	tasks := []*Task{
+		// Clocks go forward at 02:00 on March 31, a 23-hour day: 22:00 to
+		// 04:00 is 2h on the 30th and 3h on the 31st.
+		{ProjectName: "Alpha", StartTime: at(3, 30, 22), Duration: at(3, 31, 4).Sub(at(3, 30, 22))},
+		// Clocks go back at 03:00 on October 27, a 25-hour day: 23:00 to
+		// 04:00 is 1h on the 26th and 5h on the 27th.
+		{ProjectName: "Alpha", StartTime: at(10, 26, 23), Duration: at(10, 27, 4).Sub(at(10, 26, 23))},
+		// All day long on the 25-hour day.
+		{ProjectName: "Beta", StartTime: at(10, 27, 0), Duration: at(10, 28, 0).Sub(at(10, 27, 0))},
+	}
+	schedule := WorkSchedule{Weekdays: DefaultWeekdays(8 * time.Hour)}
+
+	spring := ComputeHeatmap(tasks, schedule, at(3, 30, 0), at(4, 1, 12), "")
+	want := []time.Duration{2 * time.Hour, 3 * time.Hour, 0}
+	if len(spring) != len(want) {
+		t.Fatalf("expected %d days, got %+v", len(want), spring)
+	}
+	for i, day := range spring {
+		if day.Tracked != want[i] {
+			t.Errorf("%s: tracked %v, want %v", day.Date.Format("Jan 2"), day.Tracked, want[i])
+		}
+		if h, m, _ := day.Date.Clock(); h != 0 || m != 0 {
+			t.Errorf("expected days at midnight, got %v", day.Date)
+		}
+	}
+	if got := spring[2].Date; !got.Equal(at(4, 1, 0)) {
+		t.Errorf("expected the last day to start at midnight on April 1, got %v", got)
+	}
+
+	autumn := ComputeHeatmap(tasks, schedule, at(10, 26, 0), at(10, 27, 0), "")
+	if len(autumn) != 2 || autumn[0].Tracked != time.Hour || autumn[1].Tracked != 5*time.Hour+25*time.Hour {
+		t.Errorf("unexpected autumn days %+v", autumn)
+	}
+	if beta := ComputeHeatmap(tasks, schedule, at(10, 26, 0), at(10, 27, 0), "Beta"); beta[0].Tracked != 0 || beta[1].Tracked != 25*time.Hour {
+		t.Errorf("expected only Beta's 25 hours, got %+v", beta)
+	}
+}
+
+func TestComputeHeatmap_Goals(t *testing.T) {
+	schedule := WorkSchedule{Weekdays: DefaultWeekdays(8 * time.Hour)}
+	friday := time.Date(2024, 8, 16, 0, 0, 0, 0, time.UTC)
+	days := ComputeHeatmap(nil, schedule, friday, friday.AddDate(0, 0, 1), "")
+	if days[0].Goal != 8*time.Hour || days[1].Goal != 8*time.Hour {
+		t.Errorf("expected weekends measured against a workday, got %+v", days)
+	}
+
+	tests := []struct {
+		tracked time.Duration
+		want    int
+	}{
+		{0, 0},
+		{time.Hour, 1},
+		{3 * time.Hour, 2},
+		{6 * time.Hour, 3},
+		{8 * time.Hour, HeatLevels},
+		{10 * time.Hour, HeatLevels},
+	}
+	for _, tt := range tests {
+		if got := (HeatmapDay{Tracked: tt.tracked, Goal: 8 * time.Hour}).Level(); got != tt.want {
+			t.Errorf("Level of %v = %d, want %d", tt.tracked, got, tt.want)
+		}
+	}
+	if got := (HeatmapDay{Tracked: time.Minute}).Level(); got != HeatLevels {
+		t.Errorf("expected any time on a day without a goal to be the darkest, got %d", got)
+	}
+}
+
+func TestHeatmapRange(t *testing.T) {
+	first, last := HeatmapRange(time.Date(2024, 8, 14, 15, 0, 0, 0, time.UTC), time.Monday)
+	if !first.Equal(time.Date(2023, 8, 14, 0, 0, 0, 0, time.UTC)) || !last.Equal(time.Date(2024, 8, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected range %v – %v", first, last)
+	}
+	if first, _ := HeatmapRange(time.Date(2024, 8, 14, 15, 0, 0, 0, time.UTC), time.Sunday); first.Weekday() != time.Sunday {
+		t.Errorf("expected the range to start on a Sunday, got %v", first)
+	}
+}
+
+func TestDayHeaderIndex(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 8, d, 0, 0, 0, 0, time.UTC) }
+	items := FlattenTaskGroups([]TaskGroup{
+		{Date: day(14), Tasks: []*Task{{ProjectName: "A"}}},
+		{Date: day(12), Tasks: []*Task{{ProjectName: "B"}, {ProjectName: "C"}}},
+	})
+	tests := []struct {
+		day  time.Time
+		want int
+	}{
+		{day(14), 0},
+		{day(12).Add(15 * time.Hour), 2},
+		{day(13), 2}, // nothing on the 13th, so the day before
+		{day(20), 0},
+		{day(1), -1},
+	}
+	for _, tt := range tests {
+		if got := DayHeaderIndex(items, tt.day); got != tt.want {
+			t.Errorf("DayHeaderIndex(%s) = %d, want %d", tt.day.Format("Jan 2 15:04"), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCalendar is returned by ParseICS for input that is not an
+// iCalendar file.
+var ErrInvalidCalendar = errors.New("not an iCalendar (.ics) file")
+
+// Holiday is a day off, entered by hand or read from a holiday calendar.
+type Holiday struct {
+	ID     int64     // 0 for holidays read from a calendar file
+	Date   time.Time // midnight
+	Name   string
+	Yearly bool // repeats on the same date every year from Date on
+}
+
+// On reports whether the holiday falls on day, comparing dates in day's
+// timezone.
+func (h Holiday) On(day time.Time) bool {
+	y, m, d := day.Date()
+	hy, hm, hd := h.Date.In(day.Location()).Date()
+	if h.Yearly {
+		return m == hm && d == hd && y >= hy
+	}
+	return y == hy && m == hm && d == hd
+}
+
+// ParseICS reads the events of an iCalendar file as holidays, one per day
+// an event covers, with dates in loc. Events repeating with FREQ=YEARLY are
+// yearly holidays; other recurrence rules are not expanded.
+func ParseICS(r io.Reader, loc *time.Location) ([]Holiday, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, ErrInvalidCalendar
+	}
+
+	var holidays []Holiday
+	var event map[string]string // property name to "params:value"
+	for _, line := range lines {
+		switch {
+		case strings.EqualFold(line, "BEGIN:VEVENT"):
+			event = make(map[string]string)
+		case strings.EqualFold(line, "END:VEVENT"):
+			if event == nil {
+				continue
+			}
+			days, err := eventHolidays(event, loc)
+			if err != nil {
+				return nil, err
+			}
+			holidays = append(holidays, days...)
+			event = nil
+		case event != nil:
+			if i := strings.IndexAny(line, ";:"); i > 0 {
+				event[strings.ToUpper(line[:i])] = line[i:]
+			}
+		}
+	}
+	return holidays, nil
+}
+
+// unfoldICS returns the logical lines of an iCalendar file, joining lines
+// continued with a leading space or tab.
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if n := len(lines); n > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[n-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// eventHolidays turns an event's properties, each stored as its parameters
+// and ":value", into one holiday per day it covers.
+func eventHolidays(event map[string]string, loc *time.Location) ([]Holiday, error) {
+	name := icsText(icsValue(event["SUMMARY"]))
+	start, allDay, err := icsDate(icsValue(event["DTSTART"]), loc)
+	if err != nil {
+		return nil, fmt.Errorf("holiday %q: invalid DTSTART: %w", name, err)
+	}
+	last := start
+	if value := icsValue(event["DTEND"]); value != "" {
+		end, endAllDay, err := icsDate(value, loc)
+		if err != nil {
+			return nil, fmt.Errorf("holiday %q: invalid DTEND: %w", name, err)
+		}
+		// All-day events end on the day after their last one.
+		if endAllDay && allDay {
+			end = end.AddDate(0, 0, -1)
+		}
+		if end.After(last) {
+			last = end
+		}
+	}
+	yearly := strings.Contains(strings.ToUpper(icsValue(event["RRULE"])), "FREQ=YEARLY")
+
+	var days []Holiday
+	for day := start; !day.After(last); day = day.AddDate(0, 0, 1) {
+		days = append(days, Holiday{Date: day, Name: name, Yearly: yearly})
+	}
+	return days, nil
+}
+
+// icsValue strips a property's parameters, keeping its value.
+func icsValue(property string) string {
+	_, value, _ := strings.Cut(property, ":")
+	return value
+}
+
+// icsDate reads the day of a DATE ("20241225") or DATE-TIME
+// ("20241225T090000Z") value, reporting whether it was a DATE.
+func icsDate(value string, loc *time.Location) (day time.Time, allDay bool, err error) {
+	if len(value) < 8 {
+		return time.Time{}, false, fmt.Errorf("%q is not a date", value)
+	}
+	day, err = time.ParseInLocation("20060102", value[:8], loc)
+	return day, len(value) == 8, err
+}
+
+// icsText unescapes an iCalendar TEXT value.
+func icsText(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidPeriod is returned for custom periods that end before they
+	// start.
+	ErrInvalidPeriod = errors.New("period must end on or after its first day")
+	// ErrInvalidWeekday is returned for names that are not English weekdays.
+	ErrInvalidWeekday = errors.New("not a day of the week")
+)
+
+// ParseWeekday parses an English weekday name such as "monday", ignoring
+// case.
+func ParseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidWeekday, name)
+}
+
+// PeriodKind is the calendar unit a summary period spans.
+type PeriodKind int
+
+const (
+	PeriodDay PeriodKind = iota
+	PeriodWeek
+	PeriodMonth
+	PeriodQuarter
+	PeriodYear
+	PeriodCustom
+)
+
+func (k PeriodKind) String() string {
+	switch k {
+	case PeriodDay:
+		return "Day"
+	case PeriodWeek:
+		return "Week"
+	case PeriodMonth:
+		return "Month"
+	case PeriodQuarter:
+		return "Quarter"
+	case PeriodYear:
+		return "Year"
+	default:
+		return "Custom"
+	}
+}
+
+// Period is a window of whole days [Start, End) for summaries.
+type Period struct {
+	Kind  PeriodKind
+	Start time.Time
+	End   time.Time
+}
+
+// PeriodOf returns the period of kind that contains t, with weeks starting on
+// weekStart. Custom periods are made with CustomPeriod; for them PeriodOf
+// returns the day of t.
+func PeriodOf(kind PeriodKind, t time.Time, weekStart time.Weekday) Period {
+	y, m, d := t.Date()
+	loc := t.Location()
+	switch kind {
+	case PeriodWeek:
+		start := StartOfWeek(t, weekStart)
+		return Period{Kind: kind, Start: start, End: start.AddDate(0, 0, 7)}
+	case PeriodMonth:
+		start := time.Date(y, m, 1, 0, 0, 0, 0, loc)
+		return Period{Kind: kind, Start: start, End: start.AddDate(0, 1, 0)}
+	case PeriodQuarter:
+		start := time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, loc)
+		return Period{Kind: kind, Start: start, End: start.AddDate(0, 3, 0)}
+	case PeriodYear:
+		start := time.Date(y, 1, 1, 0, 0, 0, 0, loc)
+		return Period{Kind: kind, Start: start, End: start.AddDate(1, 0, 0)}
+	default:
+		start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		return Period{Kind: PeriodDay, Start: start, End: start.AddDate(0, 0, 1)}
+	}
+}
+
+// CustomPeriod returns the period from the day of first through the day of
+// last, both included.
+func CustomPeriod(first, last time.Time) (Period, error) {
+	start := PeriodOf(PeriodDay, first, time.Monday).Start
+	end := PeriodOf(PeriodDay, last, time.Monday).End
+	if !end.After(start) {
+		return Period{}, ErrInvalidPeriod
+	}
+	return Period{Kind: PeriodCustom, Start: start, End: end}, nil
+}
+
+// Shift returns the period n periods later, or earlier for negative n.
+// Custom periods move by their length in days.
+func (p Period) Shift(n int) Period {
+	switch p.Kind {
+	case PeriodDay:
+		return Period{Kind: p.Kind, Start: p.Start.AddDate(0, 0, n), End: p.End.AddDate(0, 0, n)}
+	case PeriodWeek:
+		return Period{Kind: p.Kind, Start: p.Start.AddDate(0, 0, 7*n), End: p.End.AddDate(0, 0, 7*n)}
+	case PeriodMonth:
+		return Period{Kind: p.Kind, Start: p.Start.AddDate(0, n, 0), End: p.End.AddDate(0, n, 0)}
+	case PeriodQuarter:
+		return Period{Kind: p.Kind, Start: p.Start.AddDate(0, 3*n, 0), End: p.End.AddDate(0, 3*n, 0)}
+	case PeriodYear:
+		return Period{Kind: p.Kind, Start: p.Start.AddDate(n, 0, 0), End: p.End.AddDate(n, 0, 0)}
+	default:
+		days := p.Days() * n
+		return Period{Kind: p.Kind, Start: p.Start.AddDate(0, 0, days), End: p.End.AddDate(0, 0, days)}
+	}
+}
+
+// Days returns the number of calendar days in the period.
+func (p Period) Days() int {
+	days := 0
+	for day := p.Start; day.Before(p.End); day = day.AddDate(0, 0, 1) {
+		days++
+	}
+	return days
+}
+
+// Contains reports whether t falls inside the period.
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+// BucketSize returns the bucket size that keeps the period's breakdown
+// readable: days up to two weeks, weeks up to a quarter, months beyond.
+func (p Period) BucketSize() BucketSize {
+	switch days := p.Days(); {
+	case days <= 14:
+		return BucketDay
+	case days <= 92:
+		return BucketWeek
+	default:
+		return BucketMonth
+	}
+}
+
+// Label names the period, e.g. "Monday, March 4, 2024", "Week 10 · Mar 4 –
+// Mar 10, 2024", "March 2024", "Q3 2024", "2024" or "Mar 4 – Mar 20, 2024"
+// for custom ranges. Week numbers follow ISO 8601.
+func (p Period) Label() string {
+	switch p.Kind {
+	case PeriodDay:
+		return p.Start.Format("Monday, January 2, 2006")
+	case PeriodWeek:
+		_, week := ISOWeekOf(p.Start)
+		return fmt.Sprintf("Week %d · %s", week, rangeLabel(p.Start, p.End))
+	case PeriodMonth:
+		return p.Start.Format("January 2006")
+	case PeriodQuarter:
+		return fmt.Sprintf("Q%d %d", (int(p.Start.Month())-1)/3+1, p.Start.Year())
+	case PeriodYear:
+		return p.Start.Format("2006")
+	}
+	return rangeLabel(p.Start, p.End)
+}
+
+// rangeLabel names the days [start, end), e.g. "Mar 4 – Mar 10, 2024".
+func rangeLabel(start, end time.Time) string {
+	last := end.AddDate(0, 0, -1)
+	if last.Year() == start.Year() {
+		return start.Format("Jan 2") + " – " + last.Format("Jan 2, 2006")
+	}
+	return start.Format("Jan 2, 2006") + " – " + last.Format("Jan 2, 2006")
+}
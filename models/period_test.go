@@ -0,0 +1,116 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPeriodOf(t *testing.T) {
+	at := time.Date(2024, 8, 14, 15, 30, 0, 0, time.UTC) // Wednesday
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		kind       PeriodKind
+		start, end time.Time
+		label      string
+		size       BucketSize
+	}{
+		{PeriodDay, date(2024, 8, 14), date(2024, 8, 15), "Wednesday, August 14, 2024", BucketDay},
+		{PeriodWeek, date(2024, 8, 12), date(2024, 8, 19), "Week 33 · Aug 12 – Aug 18, 2024", BucketDay},
+		{PeriodMonth, date(2024, 8, 1), date(2024, 9, 1), "August 2024", BucketWeek},
+		{PeriodQuarter, date(2024, 7, 1), date(2024, 10, 1), "Q3 2024", BucketWeek},
+		{PeriodYear, date(2024, 1, 1), date(2025, 1, 1), "2024", BucketMonth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind.String(), func(t *testing.T) {
+			p := PeriodOf(tt.kind, at, time.Monday)
+			if !p.Start.Equal(tt.start) || !p.End.Equal(tt.end) {
+				t.Errorf("got [%v, %v), want [%v, %v)", p.Start, p.End, tt.start, tt.end)
+			}
+			if p.Label() != tt.label {
+				t.Errorf("Label() = %q, want %q", p.Label(), tt.label)
+			}
+			if p.BucketSize() != tt.size {
+				t.Errorf("BucketSize() = %v, want %v", p.BucketSize(), tt.size)
+			}
+			if !p.Contains(at) || p.Contains(p.End) {
+				t.Error("expected the period to contain t but not its end")
+			}
+		})
+	}
+}
+
+func TestPeriod_Shift(t *testing.T) {
+	at := time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC)
+	if p := PeriodOf(PeriodQuarter, at, time.Monday).Shift(1); p.Label() != "Q1 2025" {
+		t.Errorf("expected the next quarter to be Q1 2025, got %s", p.Label())
+	}
+	if p := PeriodOf(PeriodMonth, at, time.Monday).Shift(-11); p.Label() != "December 2023" {
+		t.Errorf("expected December 2023, got %s", p.Label())
+	}
+	if p := PeriodOf(PeriodWeek, at, time.Monday).Shift(-1); p.Label() != "Week 46 · Nov 11 – Nov 17, 2024" {
+		t.Errorf("unexpected previous week %s", p.Label())
+	}
+
+	custom, err := CustomPeriod(time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("CustomPeriod: %v", err)
+	}
+	if custom.Days() != 4 || custom.Label() != "Dec 30, 2024 – Jan 2, 2025" {
+		t.Errorf("unexpected custom period %d days, %s", custom.Days(), custom.Label())
+	}
+	if next := custom.Shift(1); next.Label() != "Jan 3 – Jan 6, 2025" {
+		t.Errorf("expected the next custom period to follow on, got %s", next.Label())
+	}
+	if _, err := CustomPeriod(at, at.AddDate(0, 0, -1)); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("expected ErrInvalidPeriod, got %v", err)
+	}
+}
+
+func TestPeriodOf_WeekStart(t *testing.T) {
+	at := time.Date(2024, 8, 14, 15, 30, 0, 0, time.UTC) // Wednesday
+	tests := []struct {
+		first time.Weekday
+		label string
+	}{
+		{time.Monday, "Week 33 · Aug 12 – Aug 18, 2024"},
+		{time.Sunday, "Week 33 · Aug 11 – Aug 17, 2024"},
+		{time.Saturday, "Week 33 · Aug 10 – Aug 16, 2024"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.first.String(), func(t *testing.T) {
+			p := PeriodOf(PeriodWeek, at, tt.first)
+			if p.Start.Weekday() != tt.first || p.Label() != tt.label {
+				t.Errorf("got %s starting on %s, want %s", p.Label(), p.Start.Weekday(), tt.label)
+			}
+		})
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	if day, err := ParseWeekday("sunday"); err != nil || day != time.Sunday {
+		t.Errorf("ParseWeekday(sunday) = %v, %v", day, err)
+	}
+	if day, err := ParseWeekday("Saturday"); err != nil || day != time.Saturday {
+		t.Errorf("ParseWeekday(Saturday) = %v, %v", day, err)
+	}
+	if _, err := ParseWeekday("someday"); !errors.Is(err, ErrInvalidWeekday) {
+		t.Errorf("expected ErrInvalidWeekday, got %v", err)
+	}
+}
+
+func TestISOWeekOf(t *testing.T) {
+	tests := []struct {
+		weekStart  time.Time
+		year, week int
+	}{
+		{time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), 2025, 1},  // Monday, ISO week 1 of 2025
+		{time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC), 2021, 1},    // Sunday-start week holding Thu Jan 7
+		{time.Date(2020, 12, 26, 0, 0, 0, 0, time.UTC), 2020, 53}, // Saturday-start week holding Thu Dec 31
+	}
+	for _, tt := range tests {
+		if year, week := ISOWeekOf(tt.weekStart); year != tt.year || week != tt.week {
+			t.Errorf("ISOWeekOf(%s) = %d-W%d, want %d-W%d", tt.weekStart.Format(time.DateOnly), year, week, tt.year, tt.week)
+		}
+	}
+}
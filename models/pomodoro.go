@@ -0,0 +1,123 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// PomodorosPerLongBreak is how many work phases come before a long break.
+const PomodorosPerLongBreak = 4
+
+// PomodoroPhase is a stage of the Pomodoro cycle.
+type PomodoroPhase int
+
+const (
+	PhaseWork PomodoroPhase = iota
+	PhaseShortBreak
+	PhaseLongBreak
+)
+
+func (p PomodoroPhase) String() string {
+	switch p {
+	case PhaseShortBreak:
+		return "Short break"
+	case PhaseLongBreak:
+		return "Long break"
+	default:
+		return "Work"
+	}
+}
+
+// IsBreak reports whether p is a short or long break.
+func (p PomodoroPhase) IsBreak() bool {
+	return p != PhaseWork
+}
+
+// PomodoroSettings are the phase lengths of a Pomodoro cycle.
+type PomodoroSettings struct {
+	Work       time.Duration
+	ShortBreak time.Duration
+	LongBreak  time.Duration
+}
+
+// Pomodoro tracks the current phase of a running Pomodoro cycle.
+type Pomodoro struct {
+	Settings   PomodoroSettings
+	Phase      PomodoroPhase
+	PhaseStart time.Time
+	// Completed counts the work phases finished since the cycle started.
+	Completed int
+}
+
+// NewPomodoro starts a cycle with a work phase at now.
+func NewPomodoro(settings PomodoroSettings, now time.Time) *Pomodoro {
+	return &Pomodoro{Settings: settings, Phase: PhaseWork, PhaseStart: now}
+}
+
+func (p *Pomodoro) length(phase PomodoroPhase) time.Duration {
+	switch phase {
+	case PhaseShortBreak:
+		return p.Settings.ShortBreak
+	case PhaseLongBreak:
+		return p.Settings.LongBreak
+	default:
+		return p.Settings.Work
+	}
+}
+
+// PhaseEnd returns when the current phase is due to end.
+func (p *Pomodoro) PhaseEnd() time.Time {
+	return p.PhaseStart.Add(p.length(p.Phase))
+}
+
+// Remaining returns the time left in the current phase at now.
+func (p *Pomodoro) Remaining(now time.Time) time.Duration {
+	return max(p.PhaseEnd().Sub(now), 0)
+}
+
+// Advance moves to the next phase once the current one has ended, returning
+// when the transition happened and true. A cycle that fell far behind, e.g.
+// across a suspend, restarts the next phase at now rather than replaying
+// the missed ones.
+func (p *Pomodoro) Advance(now time.Time) (at time.Time, ok bool) {
+	end := p.PhaseEnd()
+	if now.Before(end) {
+		return time.Time{}, false
+	}
+	p.SkipTo(p.next(), end)
+	if p.PhaseEnd().Before(now) {
+		p.PhaseStart = now
+	}
+	return end, true
+}
+
+// SkipTo ends the current phase early at now and starts phase. Finishing a
+// work phase counts as a completed pomodoro.
+func (p *Pomodoro) SkipTo(phase PomodoroPhase, now time.Time) {
+	if p.Phase == PhaseWork && phase.IsBreak() {
+		p.Completed++
+	}
+	p.Phase, p.PhaseStart = phase, now
+}
+
+func (p *Pomodoro) next() PomodoroPhase {
+	if p.Phase.IsBreak() {
+		return PhaseWork
+	}
+	if (p.Completed+1)%PomodorosPerLongBreak == 0 {
+		return PhaseLongBreak
+	}
+	return PhaseShortBreak
+}
+
+// FormatPomodoros describes a pomodoro count, or returns "" for none.
+func FormatPomodoros(n int) string {
+	switch {
+	case n == 1:
+		return "1 pomodoro"
+	case n > 1:
+		return fmt.Sprintf("%d pomodoros", n)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPomodoro_Cycle(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	p := NewPomodoro(PomodoroSettings{Work: 25 * time.Minute, ShortBreak: 5 * time.Minute, LongBreak: 15 * time.Minute}, start)
+
+	if got := p.Remaining(start.Add(10 * time.Minute)); got != 15*time.Minute {
+		t.Errorf("expected 15m remaining, got %v", got)
+	}
+	if _, ok := p.Advance(start.Add(24 * time.Minute)); ok {
+		t.Fatal("expected no transition before the work phase ends")
+	}
+
+	now := start
+	var phases []PomodoroPhase
+	for range 8 {
+		now = p.PhaseEnd()
+		at, ok := p.Advance(now)
+		if !ok || !at.Equal(now) {
+			t.Fatalf("expected a transition at %v, got %v (%v)", now, at, ok)
+		}
+		phases = append(phases, p.Phase)
+	}
+	want := []PomodoroPhase{PhaseShortBreak, PhaseWork, PhaseShortBreak, PhaseWork, PhaseShortBreak, PhaseWork, PhaseLongBreak, PhaseWork}
+	for i := range want {
+		if phases[i] != want[i] {
+			t.Fatalf("expected phases %v, got %v", want, phases)
+		}
+	}
+	if p.Completed != PomodorosPerLongBreak {
+		t.Errorf("expected %d completed pomodoros, got %d", PomodorosPerLongBreak, p.Completed)
+	}
+
+	// Falling far behind restarts the next phase now.
+	late := now.Add(3 * time.Hour)
+	p.Advance(late)
+	if p.Phase != PhaseShortBreak || !p.PhaseStart.Equal(late) {
+		t.Errorf("expected a short break starting now, got %v at %v", p.Phase, p.PhaseStart)
+	}
+
+	// Skipping a break does not count a pomodoro.
+	p.SkipTo(PhaseWork, late.Add(time.Minute))
+	if p.Completed != PomodorosPerLongBreak+1 || p.Phase != PhaseWork {
+		t.Errorf("unexpected state after skipping the break: %+v", p)
+	}
+}
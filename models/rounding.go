@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+// Rounding modes: which way a duration that is not a multiple of the
+// increment moves.
+const (
+	RoundNearest = "nearest"
+	RoundUp      = "up"
+	RoundDown    = "down"
+)
+
+// Rounding scopes: each entry is rounded on its own, or the entries of a
+// project are summed per day and the daily total is rounded.
+const (
+	RoundPerEntry = "entry"
+	RoundPerDay   = "day"
+)
+
+// RoundingRule rounds reported durations to a multiple of Increment. It is
+// applied to summaries only, never to a stored Task.Duration.
+type RoundingRule struct {
+	Increment time.Duration // zero disables rounding
+	Mode      string        // RoundNearest, RoundUp or RoundDown
+	Per       string        // RoundPerEntry or RoundPerDay
+}
+
+// Enabled reports whether the rule changes any duration.
+func (r RoundingRule) Enabled() bool {
+	return r.Increment > 0
+}
+
+// Round rounds d to a multiple of the increment. Unknown modes round to the
+// nearest multiple.
+func (r RoundingRule) Round(d time.Duration) time.Duration {
+	if !r.Enabled() || d <= 0 {
+		return d
+	}
+	switch r.Mode {
+	case RoundUp:
+		if rest := d % r.Increment; rest != 0 {
+			return d - rest + r.Increment
+		}
+		return d
+	case RoundDown:
+		return d.Truncate(r.Increment)
+	default:
+		return d.Round(r.Increment)
+	}
+}
+
+// perDay reports whether the rule rounds daily totals instead of entries.
+func (r RoundingRule) perDay() bool {
+	return r.Per == RoundPerDay
+}
+
+// Rounding rule scopes: a rule overrides the default for one project, or for
+// every project billed to a client.
+const (
+	RoundingForProject = "project"
+	RoundingForClient  = "client"
+)
+
+// ScopedRoundingRule is a rule for the project or client called Name.
+type ScopedRoundingRule struct {
+	Scope string // RoundingForProject or RoundingForClient
+	Name  string
+	RoundingRule
+}
+
+// RoundingRules holds the rule for all projects and overrides per client and
+// per project, which replace it entirely. A project's own rule wins over its
+// client's.
+type RoundingRules struct {
+	Default  RoundingRule
+	Clients  map[string]RoundingRule
+	Projects map[string]RoundingRule
+	// ProjectClients gives the client each project is billed to.
+	ProjectClients map[string]string
+}
+
+// For returns the rule that applies to project.
+func (r RoundingRules) For(project string) RoundingRule {
+	if rule, ok := r.Projects[project]; ok {
+		return rule
+	}
+	if client, ok := r.ProjectClients[project]; ok {
+		if rule, ok := r.Clients[client]; ok {
+			return rule
+		}
+	}
+	return r.Default
+}
+
+// Enabled reports whether any project's durations are rounded.
+func (r RoundingRules) Enabled() bool {
+	if r.Default.Enabled() {
+		return true
+	}
+	for _, rules := range []map[string]RoundingRule{r.Clients, r.Projects} {
+		for _, rule := range rules {
+			if rule.Enabled() {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoundingRule_Round(t *testing.T) {
+	tests := []struct {
+		name string
+		rule RoundingRule
+		in   time.Duration
+		want time.Duration
+	}{
+		{"disabled", RoundingRule{}, 7 * time.Minute, 7 * time.Minute},
+		{"nearest down", RoundingRule{Increment: 15 * time.Minute, Mode: RoundNearest}, 22 * time.Minute, 15 * time.Minute},
+		{"nearest up", RoundingRule{Increment: 15 * time.Minute, Mode: RoundNearest}, 23 * time.Minute, 30 * time.Minute},
+		{"nearest halfway", RoundingRule{Increment: 6 * time.Minute, Mode: RoundNearest}, 9 * time.Minute, 12 * time.Minute},
+		{"up", RoundingRule{Increment: 6 * time.Minute, Mode: RoundUp}, 61 * time.Second, 6 * time.Minute},
+		{"up exact", RoundingRule{Increment: 6 * time.Minute, Mode: RoundUp}, 12 * time.Minute, 12 * time.Minute},
+		{"down", RoundingRule{Increment: 15 * time.Minute, Mode: RoundDown}, 29 * time.Minute, 15 * time.Minute},
+		{"zero stays zero", RoundingRule{Increment: 15 * time.Minute, Mode: RoundUp}, 0, 0},
+		{"unknown mode is nearest", RoundingRule{Increment: 10 * time.Minute, Mode: "sideways"}, 16 * time.Minute, 20 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Round(tt.in); got != tt.want {
+				t.Errorf("Round(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundingRules_For(t *testing.T) {
+	rules := RoundingRules{
+		Default:  RoundingRule{Increment: 15 * time.Minute},
+		Projects: map[string]RoundingRule{"Internal": {}},
+	}
+	if rules.For("Client").Increment != 15*time.Minute {
+		t.Error("expected the default rule for projects without an override")
+	}
+	if rules.For("Internal").Enabled() {
+		t.Error("expected the override to replace the default rule")
+	}
+	if !rules.Enabled() || (RoundingRules{Projects: map[string]RoundingRule{"Internal": {}}}).Enabled() {
+		t.Error("unexpected Enabled result")
+	}
+}
+
+func TestRoundingRules_ForClient(t *testing.T) {
+	rules := RoundingRules{
+		Default:        RoundingRule{Increment: 15 * time.Minute},
+		Clients:        map[string]RoundingRule{"Acme Corp": {Increment: 6 * time.Minute, Mode: RoundUp}},
+		Projects:       map[string]RoundingRule{"Acme Support": {}},
+		ProjectClients: map[string]string{"Acme Website": "Acme Corp", "Acme Support": "Acme Corp", "Beta App": "Beta Ltd"},
+	}
+	if rules.For("Acme Website").Increment != 6*time.Minute {
+		t.Error("expected the client's rule for its projects")
+	}
+	if rules.For("Acme Support").Enabled() {
+		t.Error("expected a project's own rule to win over its client's")
+	}
+	if rules.For("Beta App").Increment != 15*time.Minute {
+		t.Error("expected the default rule for clients without a rule")
+	}
+	if !(RoundingRules{Clients: rules.Clients}).Enabled() {
+		t.Error("expected a client rule to enable rounding")
+	}
+}
+
+func TestComputeSummary_Rounding(t *testing.T) {
+	loc := time.UTC
+	windowStart := time.Date(2024, 3, 4, 0, 0, 0, 0, loc) // Monday
+	now := windowStart.AddDate(0, 0, 3)
+	at := func(day, hour, minute int) time.Time {
+		return windowStart.AddDate(0, 0, day).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	// Three 10-minute entries on Monday and one on Tuesday for each project.
+	var tasks []*Task
+	for _, project := range []string{"Entry", "Day"} {
+		for _, start := range []time.Time{at(0, 9, 0), at(0, 10, 0), at(0, 11, 0), at(1, 9, 0)} {
+			tasks = append(tasks, &Task{ProjectName: project, StartTime: start, EndTime: start.Add(10 * time.Minute), Duration: 10 * time.Minute})
+		}
+	}
+	rules := RoundingRules{
+		Default: RoundingRule{Increment: 15 * time.Minute, Mode: RoundUp, Per: RoundPerEntry},
+		Projects: map[string]RoundingRule{
+			"Day": {Increment: 15 * time.Minute, Mode: RoundUp, Per: RoundPerDay},
+		},
+	}
+
+	summary := ComputeSummary(tasks, windowStart, now, BucketDay, time.Monday, rules)
+	if len(summary.Projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d", len(summary.Projects))
+	}
+	byProject := map[string]ProjectSeries{}
+	for _, s := range summary.Projects {
+		byProject[s.ProjectName] = s
+	}
+
+	// Per entry: each 10 minutes becomes 15.
+	entry := byProject["Entry"]
+	if entry.Duration != time.Hour || entry.Buckets[0] != 45*time.Minute || entry.Buckets[1] != 15*time.Minute {
+		t.Errorf("unexpected per-entry rounding: %v %v", entry.Duration, entry.Buckets)
+	}
+	// Per day: Monday's 30 minutes stay, Tuesday's 10 become 15.
+	day := byProject["Day"]
+	if day.Duration != 45*time.Minute || day.Buckets[0] != 30*time.Minute || day.Buckets[1] != 15*time.Minute {
+		t.Errorf("unexpected per-day rounding: %v %v", day.Duration, day.Buckets)
+	}
+	if summary.Projects[0].ProjectName != "Entry" || summary.Projects[1].Percentage != 0.75 {
+		t.Errorf("expected sorting and percentages from rounded totals, got %+v", summary.Projects)
+	}
+
+	// The stored durations are untouched.
+	for _, task := range tasks {
+		if task.Duration != 10*time.Minute {
+			t.Fatalf("expected task durations unchanged, got %v", task.Duration)
+		}
+	}
+	raw := ComputeSummary(tasks, windowStart, now, BucketDay, time.Monday, RoundingRules{}).Projects
+	if raw[0].Duration != 40*time.Minute {
+		t.Errorf("expected 40m unrounded, got %v", raw[0].Duration)
+	}
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTargets is returned by ParseWeekdayTargets for input that is not
+// seven daily targets.
+var ErrInvalidTargets = errors.New("targets must be seven hours from Monday to Sunday, such as 8 8 8 8 6 0 0")
+
+// WorkSchedule gives the target working time of each day.
+type WorkSchedule struct {
+	Weekdays [7]time.Duration // target of each day of the week, indexed by time.Weekday
+	Holidays []Holiday        // days off, whatever their weekday's target
+	Absences []Absence        // days or half-days away, crediting the target
+}
+
+// DefaultWeekdays returns daily as the target from Monday to Friday, with
+// the weekend off.
+func DefaultWeekdays(daily time.Duration) [7]time.Duration {
+	var weekdays [7]time.Duration
+	for day := time.Monday; day <= time.Friday; day++ {
+		weekdays[day] = daily
+	}
+	return weekdays
+}
+
+// Target returns the working time expected on day: its weekday's target, or
+// none on holidays, less the time credited by absences.
+func (s WorkSchedule) Target(day time.Time) time.Duration {
+	scheduled := s.scheduled(day)
+	var credit float64
+	for _, a := range s.AbsencesOn(day) {
+		if a.credits() {
+			credit += a.Days()
+		}
+	}
+	return scheduled - time.Duration(math.Min(credit, 1)*float64(scheduled))
+}
+
+// scheduled returns day's target before absences.
+func (s WorkSchedule) scheduled(day time.Time) time.Duration {
+	if _, ok := s.HolidayOn(day); ok {
+		return 0
+	}
+	return s.Weekdays[day.Weekday()]
+}
+
+// BucketTargets returns the working time expected in each bucket, the sum
+// of the targets of its days.
+func (s WorkSchedule) BucketTargets(buckets []Bucket) []time.Duration {
+	targets := make([]time.Duration, len(buckets))
+	for i, b := range buckets {
+		for day := b.Start; day.Before(b.End); day = day.AddDate(0, 0, 1) {
+			targets[i] += s.Target(day)
+		}
+	}
+	return targets
+}
+
+// Workday reports whether day has a target before absences, which is when
+// an absence on it takes from the allowance.
+func (s WorkSchedule) Workday(day time.Time) bool {
+	return s.scheduled(day) > 0
+}
+
+// LastWorkday returns midnight of the last workday in the week containing
+// day, or false when the week has none.
+func (s WorkSchedule) LastWorkday(day time.Time, weekStart time.Weekday) (time.Time, bool) {
+	start := StartOfWeek(day, weekStart)
+	for i := 6; i >= 0; i-- {
+		if d := start.AddDate(0, 0, i); s.Workday(d) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// AbsencesOn returns the absences recorded for day.
+func (s WorkSchedule) AbsencesOn(day time.Time) []Absence {
+	var absences []Absence
+	y, m, d := day.Date()
+	for _, a := range s.Absences {
+		if ay, am, ad := a.Date.In(day.Location()).Date(); ay == y && am == m && ad == d {
+			absences = append(absences, a)
+		}
+	}
+	return absences
+}
+
+// HolidayOn returns the holiday falling on day, if any.
+func (s WorkSchedule) HolidayOn(day time.Time) (Holiday, bool) {
+	for _, h := range s.Holidays {
+		if h.On(day) {
+			return h, true
+		}
+	}
+	return Holiday{}, false
+}
+
+// ParseWeekdayTargets parses seven daily targets in hours from Monday to
+// Sunday, separated by spaces or commas, e.g. "8 8 8 8 6 0 0".
+func ParseWeekdayTargets(s string) ([7]time.Duration, error) {
+	var weekdays [7]time.Duration
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	if len(fields) != 7 {
+		return weekdays, ErrInvalidTargets
+	}
+	for i, field := range fields {
+		hours, err := strconv.ParseFloat(field, 64)
+		if err != nil || hours < 0 || hours > 24 || math.IsNaN(hours) {
+			return weekdays, ErrInvalidTargets
+		}
+		weekdays[(i+1)%7] = time.Duration(hours * float64(time.Hour)).Round(time.Minute)
+	}
+	return weekdays, nil
+}
+
+// FormatWeekdayTargets formats daily targets as ParseWeekdayTargets reads
+// them.
+func FormatWeekdayTargets(weekdays [7]time.Duration) string {
+	fields := make([]string, 7)
+	for i := range fields {
+		fields[i] = strconv.FormatFloat(weekdays[(i+1)%7].Hours(), 'f', -1, 64)
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkSchedule_Target(t *testing.T) {
+	weekdays, err := ParseWeekdayTargets("8 8 8 8 6 0 0")
+	if err != nil {
+		t.Fatalf("ParseWeekdayTargets: %v", err)
+	}
+	schedule := WorkSchedule{
+		Weekdays: weekdays,
+		Holidays: []Holiday{
+			{Date: time.Date(2020, 12, 25, 0, 0, 0, 0, time.UTC), Name: "Christmas Day", Yearly: true},
+			{Date: time.Date(2024, 8, 15, 0, 0, 0, 0, time.UTC), Name: "Assumption Day"},
+		},
+	}
+	tests := []struct {
+		day  time.Time
+		want time.Duration
+	}{
+		{time.Date(2024, 8, 12, 0, 0, 0, 0, time.UTC), 8 * time.Hour},  // Monday
+		{time.Date(2024, 8, 15, 9, 0, 0, 0, time.UTC), 0},              // Thursday holiday
+		{time.Date(2024, 8, 16, 0, 0, 0, 0, time.UTC), 6 * time.Hour},  // Friday
+		{time.Date(2024, 8, 17, 0, 0, 0, 0, time.UTC), 0},              // Saturday
+		{time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), 0},             // yearly holiday
+		{time.Date(2019, 12, 25, 0, 0, 0, 0, time.UTC), 8 * time.Hour}, // before it started
+	}
+	for _, tt := range tests {
+		if got := schedule.Target(tt.day); got != tt.want {
+			t.Errorf("Target(%s) = %v, want %v", tt.day.Format("Mon 2006-01-02"), got, tt.want)
+		}
+	}
+	if h, ok := schedule.HolidayOn(time.Date(2030, 12, 25, 0, 0, 0, 0, time.UTC)); !ok || h.Name != "Christmas Day" {
+		t.Errorf("expected Christmas Day, got %+v", h)
+	}
+}
+
+func TestWorkSchedule_BucketTargets(t *testing.T) {
+	schedule := WorkSchedule{
+		Weekdays: DefaultWeekdays(8 * time.Hour),
+		Holidays: []Holiday{{Date: time.Date(2024, 8, 15, 0, 0, 0, 0, time.UTC), Name: "Assumption Day"}},
+	}
+	// August 2024 by weeks starting Monday: Thu 1 – Sun 4, then 5 – 11, 12 – 18, ...
+	buckets := Buckets(time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 8, 19, 0, 0, 0, 0, time.UTC), BucketWeek, time.Monday)
+	want := []time.Duration{16 * time.Hour, 40 * time.Hour, 32 * time.Hour}
+	got := schedule.BucketTargets(buckets)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d targets, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("target of bucket %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseWeekdayTargets(t *testing.T) {
+	weekdays, err := ParseWeekdayTargets("7.5, 7.5, 7.5, 7.5, 4, 0, 0")
+	if err != nil {
+		t.Fatalf("ParseWeekdayTargets: %v", err)
+	}
+	if weekdays[time.Monday] != 450*time.Minute || weekdays[time.Friday] != 4*time.Hour || weekdays[time.Sunday] != 0 {
+		t.Errorf("unexpected targets %v", weekdays)
+	}
+	if got := FormatWeekdayTargets(weekdays); got != "7.5 7.5 7.5 7.5 4 0 0" {
+		t.Errorf("FormatWeekdayTargets() = %q", got)
+	}
+	if got := FormatWeekdayTargets(DefaultWeekdays(8 * time.Hour)); got != "8 8 8 8 8 0 0" {
+		t.Errorf("unexpected default targets %q", got)
+	}
+	for _, in := range []string{"", "8 8 8 8 8", "8 8 8 8 8 0 -1", "8 8 8 8 8 0 25", "a b c d e f g"} {
+		if _, err := ParseWeekdayTargets(in); !errors.Is(err, ErrInvalidTargets) {
+			t.Errorf("ParseWeekdayTargets(%q): expected ErrInvalidTargets, got %v", in, err)
+		}
+	}
+}
+
+func TestParseICS(t *testing.T) {
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20241224",
+		"DTEND;VALUE=DATE:20241227",
+		"SUMMARY:Christmas\\, Boxing",
+		"  Day",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20200101",
+		"RRULE:FREQ=YEARLY",
+		"SUMMARY:New Year's Day",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;TZID=Europe/Berlin:20240815T000000",
+		"DTEND;TZID=Europe/Berlin:20240815T235900",
+		"SUMMARY:Assumption Day",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	holidays, err := ParseICS(strings.NewReader(calendar), time.UTC)
+	if err != nil {
+		t.Fatalf("ParseICS: %v", err)
+	}
+	if len(holidays) != 5 {
+		t.Fatalf("expected 5 holidays, got %+v", holidays)
+	}
+	if h := holidays[2]; h.Name != "Christmas, Boxing Day" || !h.Date.Equal(time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the multi-day event to end on Dec 26, got %+v", h)
+	}
+	if h := holidays[3]; !h.Yearly || !h.On(time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected a yearly New Year's Day, got %+v", h)
+	}
+	if h := holidays[4]; h.Name != "Assumption Day" || h.Date.Day() != 15 {
+		t.Errorf("unexpected timed event %+v", h)
+	}
+
+	if _, err := ParseICS(strings.NewReader("DTSTART:20240101"), time.UTC); !errors.Is(err, ErrInvalidCalendar) {
+		t.Errorf("expected ErrInvalidCalendar, got %v", err)
+	}
+	broken := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:2024\nEND:VEVENT\nEND:VCALENDAR\n"
+	if _, err := ParseICS(strings.NewReader(broken), time.UTC); err == nil {
+		t.Error("expected an error for an invalid DTSTART")
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidStartTime is returned by ParseStartTime for input that is
+// neither an offset nor a clock time.
+var ErrInvalidStartTime = errors.New("start must be an offset such as -20m or a time such as 09:15")
+
+// ParseStartTime reads a back-dated start relative to now: an offset into
+// the past such as "-20m" or "-1h15m" (a bare number counts minutes), or a
+// clock time such as "09:15", today or, when that is still to come,
+// yesterday. Empty input means now.
+func ParseStartTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return now, nil
+	}
+	if offset, ok := strings.CutPrefix(s, "-"); ok {
+		d, err := ParseEstimate(offset)
+		if err != nil || d == 0 {
+			return time.Time{}, ErrInvalidStartTime
+		}
+		return now.Add(-d), nil
+	}
+	clock, err := ParseTimeOfDay(s)
+	if err != nil {
+		return time.Time{}, ErrInvalidStartTime
+	}
+	y, m, d := now.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, now.Location()).Add(clock)
+	if start.After(now) {
+		start = time.Date(y, m, d-1, 0, 0, 0, 0, now.Location()).Add(clock)
+	}
+	return start, nil
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseStartTime(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"", now},
+		{"-20m", now.Add(-20 * time.Minute)},
+		{" -1h15m ", now.Add(-75 * time.Minute)},
+		{"-45", now.Add(-45 * time.Minute)},
+		{"09:15", time.Date(2026, 3, 2, 9, 15, 0, 0, time.UTC)},
+		{"10:30", now},
+		// A clock time still to come today is yesterday's.
+		{"11:00", time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)},
+		{"23:45", time.Date(2026, 3, 1, 23, 45, 0, 0, time.UTC)},
+	}
+	for _, tc := range tests {
+		got, err := ParseStartTime(tc.input, now)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("ParseStartTime(%q) = %v, %v; want %v", tc.input, got, err, tc.want)
+		}
+	}
+	for _, input := range []string{"-", "-0m", "20m", "9.15", "yesterday"} {
+		if _, err := ParseStartTime(input, now); !errors.Is(err, ErrInvalidStartTime) {
+			t.Errorf("ParseStartTime(%q): expected ErrInvalidStartTime, got %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// BucketSize is the length of the slots a summary splits its window into.
+type BucketSize int
+
+const (
+	BucketDay BucketSize = iota
+	BucketWeek
+	BucketMonth
+)
+
+// Bucket is one slot of a summary window. The first and last bucket of a
+// window that does not start or end on a bucket boundary are shorter.
+type Bucket struct {
+	Start time.Time
+	End   time.Time
+	Week  int // ISO 8601 week number of weekly buckets
+}
+
+// Label names the bucket for charts: "Mon 4" for days, "W10 Mar 4" for the
+// week starting then and "Mar" for months.
+func (b Bucket) Label(size BucketSize) string {
+	switch size {
+	case BucketWeek:
+		return fmt.Sprintf("W%d %s", b.Week, b.Start.Format("Jan 2"))
+	case BucketMonth:
+		return b.Start.Format("Jan")
+	default:
+		return b.Start.Format("Mon 2")
+	}
+}
+
+// Buckets splits [start, end) into buckets of size, with day boundaries at
+// midnight in start's timezone and weeks starting on weekStart.
+func Buckets(start, end time.Time, size BucketSize, weekStart time.Weekday) []Bucket {
+	var buckets []Bucket
+	for bucketStart := start; bucketStart.Before(end); {
+		next := nextBucketStart(bucketStart, size, weekStart)
+		if next.After(end) {
+			next = end
+		}
+		bucket := Bucket{Start: bucketStart, End: next}
+		if size == BucketWeek {
+			_, bucket.Week = ISOWeekOf(StartOfWeek(bucketStart, weekStart))
+		}
+		buckets = append(buckets, bucket)
+		bucketStart = next
+	}
+	return buckets
+}
+
+// nextBucketStart returns the first bucket boundary after t.
+func nextBucketStart(t time.Time, size BucketSize, weekStart time.Weekday) time.Time {
+	y, m, d := t.Date()
+	switch size {
+	case BucketWeek:
+		return StartOfWeek(t, weekStart).AddDate(0, 0, 7)
+	case BucketMonth:
+		return time.Date(y, m+1, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// ProjectSeries holds a project's tracked time over a summary window, in
+// total and per bucket.
+type ProjectSeries struct {
+	ProjectName string
+	Duration    time.Duration
+	Buckets     []time.Duration // one per bucket of the summary
+	Percentage  float64         // fraction of the largest project's duration (0.0–1.0)
+	Pomodoros   int             // completed pomodoros of tasks started in the window
+
+	// Estimated sums the estimates of tasks started in the window, and
+	// EstimatedActual the time those same tasks took.
+	Estimated       time.Duration
+	EstimatedActual time.Duration
+}
+
+// Summary is the per-project breakdown of a window.
+type Summary struct {
+	Start    time.Time
+	End      time.Time
+	Size     BucketSize
+	Buckets  []Bucket
+	Projects []ProjectSeries // largest first, name ascending as a tiebreaker
+	Absences []AbsenceTotal  // days away, kept apart from the projects
+	Targets  []time.Duration // working time expected in each bucket, if known
+	Deltas   []ProjectDelta  // the projects against a compared period, if any
+	Budgets  []BudgetUsage   // the project budgets as of now, if any
+}
+
+// ComputeSummary aggregates task durations per project over [start, end),
+// clipping each task's active intervals to that range and splitting them into
+// buckets of size, with weeks starting on weekStart. Each project's time is
+// rounded by its rule in rules: entry rules round each task's time per day,
+// so a task running past midnight is rounded on both days, and day rules
+// round the project's daily totals.
+func ComputeSummary(tasks []*Task, start, end time.Time, size BucketSize, weekStart time.Weekday, rules RoundingRules) Summary {
+	summary := Summary{Start: start, End: end, Size: size, Buckets: Buckets(start, end, size, weekStart)}
+
+	projectSeries := make(map[string]*ProjectSeries)
+	seriesFor := func(project string) *ProjectSeries {
+		series, ok := projectSeries[project]
+		if !ok {
+			series = &ProjectSeries{ProjectName: project, Buckets: make([]time.Duration, len(summary.Buckets))}
+			projectSeries[project] = series
+		}
+		return series
+	}
+	addDay := func(series *ProjectSeries, dayStart time.Time, d time.Duration) {
+		if i := bucketIndex(summary.Buckets, dayStart); i >= 0 {
+			series.Buckets[i] += d
+		}
+		series.Duration += d
+	}
+	// projectDays collects the unrounded daily totals of projects rounded
+	// per day.
+	projectDays := make(map[string]map[time.Time]time.Duration)
+	for _, task := range tasks {
+		startedInWindow := !task.StartTime.Before(start) && !task.StartTime.After(end)
+		if task.Pomodoros > 0 && startedInWindow {
+			seriesFor(task.ProjectName).Pomodoros += task.Pomodoros
+		}
+		if task.Estimate > 0 && startedInWindow {
+			series := seriesFor(task.ProjectName)
+			series.Estimated += task.Estimate
+			series.EstimatedActual += task.Duration
+		}
+		taskDays := taskDayDurations(task, start, end)
+		if len(taskDays) == 0 {
+			continue
+		}
+
+		series := seriesFor(task.ProjectName)
+		rule := rules.For(task.ProjectName)
+		for dayStart, d := range taskDays {
+			if !rule.perDay() {
+				addDay(series, dayStart, rule.Round(d))
+				continue
+			}
+			days := projectDays[task.ProjectName]
+			if days == nil {
+				days = make(map[time.Time]time.Duration)
+				projectDays[task.ProjectName] = days
+			}
+			days[dayStart] += d
+		}
+	}
+	for project, days := range projectDays {
+		rule := rules.For(project)
+		for dayStart, d := range days {
+			addDay(projectSeries[project], dayStart, rule.Round(d))
+		}
+	}
+
+	if len(projectSeries) == 0 {
+		return summary
+	}
+
+	summary.Projects = make([]ProjectSeries, 0, len(projectSeries))
+	var maxDuration time.Duration
+	for _, series := range projectSeries {
+		summary.Projects = append(summary.Projects, *series)
+		if series.Duration > maxDuration {
+			maxDuration = series.Duration
+		}
+	}
+
+	projects := summary.Projects
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Duration != projects[j].Duration {
+			return projects[i].Duration > projects[j].Duration
+		}
+		return projects[i].ProjectName < projects[j].ProjectName
+	})
+
+	if maxDuration > 0 {
+		for i := range projects {
+			projects[i].Percentage = float64(projects[i].Duration) / float64(maxDuration)
+		}
+	}
+
+	return summary
+}
+
+// taskDayDurations clips task's active intervals to [start, end) and sums
+// them per day, keyed by midnight in the task's timezone.
+func taskDayDurations(task *Task, start, end time.Time) map[time.Time]time.Duration {
+	days := make(map[time.Time]time.Duration)
+	for _, interval := range task.Intervals() {
+		intervalStart := interval.Start
+		if intervalStart.Before(start) {
+			intervalStart = start
+		}
+		intervalEnd := interval.End
+		if intervalEnd.After(end) {
+			intervalEnd = end
+		}
+		if !intervalEnd.After(intervalStart) {
+			continue
+		}
+
+		// Split each clipped interval into day-sized segments so each segment
+		// can be accumulated into the correct bucket.
+		segmentDayStart := time.Date(intervalStart.Year(), intervalStart.Month(), intervalStart.Day(), 0, 0, 0, 0, intervalStart.Location())
+		for segmentDayStart.Before(intervalEnd) {
+			nextDay := segmentDayStart.AddDate(0, 0, 1)
+			segmentStart := intervalStart
+			if segmentStart.Before(segmentDayStart) {
+				segmentStart = segmentDayStart
+			}
+			segmentEnd := intervalEnd
+			if segmentEnd.After(nextDay) {
+				segmentEnd = nextDay
+			}
+			if segmentEnd.After(segmentStart) {
+				days[segmentDayStart] += segmentEnd.Sub(segmentStart)
+			}
+			segmentDayStart = nextDay
+		}
+	}
+	return days
+}
+
+// bucketIndex returns the index of the bucket holding the calendar day that
+// starts at dayStart, or -1 when no bucket does.
+func bucketIndex(buckets []Bucket, dayStart time.Time) int {
+	if len(buckets) == 0 {
+		return -1
+	}
+	loc := buckets[0].Start.Location()
+	y, m, d := dayStart.In(loc).Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	for i, bucket := range buckets {
+		by, bm, bd := bucket.Start.Date()
+		if !day.Before(time.Date(by, bm, bd, 0, 0, 0, 0, loc)) && day.Before(bucket.End) {
+			return i
+		}
+	}
+	return -1
+}
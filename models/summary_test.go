@@ -0,0 +1,222 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuckets(t *testing.T) {
+	date := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+
+	// August 2024 starts on a Thursday, so the first week is partial.
+	weeks := Buckets(date(8, 1), date(9, 1), BucketWeek, time.Monday)
+	if len(weeks) != 5 {
+		t.Fatalf("expected 5 weekly buckets, got %d", len(weeks))
+	}
+	if !weeks[0].End.Equal(date(8, 5)) || !weeks[4].Start.Equal(date(8, 26)) || !weeks[4].End.Equal(date(9, 1)) {
+		t.Errorf("unexpected weekly buckets %v", weeks)
+	}
+	if weeks[1].Label(BucketWeek) != "W32 Aug 5" {
+		t.Errorf("unexpected label %q", weeks[1].Label(BucketWeek))
+	}
+
+	// Sunday-start weeks put the boundaries a day earlier.
+	sundayWeeks := Buckets(date(8, 1), date(9, 1), BucketWeek, time.Sunday)
+	if len(sundayWeeks) != 5 || !sundayWeeks[1].Start.Equal(date(8, 4)) || sundayWeeks[0].Week != 31 {
+		t.Errorf("unexpected Sunday-start buckets %v", sundayWeeks)
+	}
+
+	months := Buckets(date(1, 1), date(1, 1).AddDate(1, 0, 0), BucketMonth, time.Monday)
+	if len(months) != 12 || months[11].Label(BucketMonth) != "Dec" {
+		t.Errorf("expected 12 monthly buckets ending in Dec, got %d", len(months))
+	}
+	if days := Buckets(date(8, 12), date(8, 19), BucketDay, time.Monday); len(days) != 7 || days[0].Label(BucketDay) != "Mon 12" {
+		t.Errorf("unexpected daily buckets %v", days)
+	}
+}
+
+func TestComputeSummary_Buckets(t *testing.T) {
+	period := PeriodOf(PeriodMonth, time.Date(2024, 8, 14, 0, 0, 0, 0, time.UTC), time.Monday)
+	task := func(project string, day, hours int) *Task {
+		start := time.Date(2024, 8, day, 9, 0, 0, 0, time.UTC)
+		return &Task{ProjectName: project, StartTime: start, Duration: time.Duration(hours) * time.Hour}
+	}
+	tasks := []*Task{
+		task("Alpha", 2, 1),  // Fri, first partial week
+		task("Alpha", 6, 2),  // Tue, second week
+		task("Alpha", 30, 3), // Fri, last week
+		task("Beta", 7, 1),
+		task("Beta", 31, 0), // empty entries are left out
+		{ProjectName: "Gamma", StartTime: time.Date(2024, 7, 31, 9, 0, 0, 0, time.UTC), Duration: time.Hour}, // before the window
+	}
+
+	summary := ComputeSummary(tasks, period.Start, period.End, period.BucketSize(), time.Monday, RoundingRules{})
+	if summary.Size != BucketWeek || len(summary.Buckets) != 5 {
+		t.Fatalf("expected 5 weekly buckets, got %d of size %v", len(summary.Buckets), summary.Size)
+	}
+	if len(summary.Projects) != 2 {
+		t.Fatalf("expected 2 projects, got %+v", summary.Projects)
+	}
+	alpha := summary.Projects[0]
+	if alpha.ProjectName != "Alpha" || alpha.Duration != 6*time.Hour {
+		t.Fatalf("expected Alpha with 6h first, got %+v", alpha)
+	}
+	want := []time.Duration{time.Hour, 2 * time.Hour, 0, 0, 3 * time.Hour}
+	for i, d := range want {
+		if alpha.Buckets[i] != d {
+			t.Errorf("bucket %d: got %v, want %v", i, alpha.Buckets[i], d)
+		}
+	}
+	if beta := summary.Projects[1]; beta.Buckets[1] != time.Hour || beta.Percentage != 1.0/6 {
+		t.Errorf("unexpected Beta series %+v", beta)
+	}
+
+	if empty := ComputeSummary(nil, period.Start, period.End, BucketDay, time.Monday, RoundingRules{}); empty.Projects != nil || len(empty.Buckets) != 31 {
+		t.Errorf("expected 31 empty day buckets, got %d and %v", len(empty.Buckets), empty.Projects)
+	}
+}
+
+// summaryWeek returns the Sunday-start week holding Thursday, Oct 10 2024 at
+// 17:00 UTC, and that time.
+func summaryWeek() (start, now time.Time) {
+	now = time.Date(2024, 10, 10, 17, 0, 0, 0, time.UTC)
+	return StartOfWeek(now, time.Sunday), now
+}
+
+func TestComputeSummary_Projects(t *testing.T) {
+	start, now := summaryWeek()
+	if empty := ComputeSummary(nil, start, now, BucketDay, time.Sunday, RoundingRules{}); empty.Projects != nil {
+		t.Errorf("expected no projects for no tasks, got %v", empty.Projects)
+	}
+
+	tasks := []*Task{
+		{ProjectName: "B", StartTime: now.Add(-1 * time.Hour), Duration: 1 * time.Hour},
+		{ProjectName: "A", StartTime: now.Add(-3 * time.Hour), Duration: 3 * time.Hour},
+		{ProjectName: "C", StartTime: now.Add(-2 * time.Hour), Duration: 2 * time.Hour},
+		{ProjectName: "Zebra", StartTime: now.Add(-4 * time.Hour), Duration: time.Hour},
+	}
+	summary := ComputeSummary(tasks, start, now, BucketDay, time.Sunday, RoundingRules{})
+	var names []string
+	for _, p := range summary.Projects {
+		names = append(names, p.ProjectName)
+	}
+	if strings.Join(names, " ") != "A C B Zebra" {
+		t.Errorf("expected the largest first, names breaking ties, got %v", names)
+	}
+	if summary.Projects[0].Percentage != 1.0 || summary.Projects[1].Percentage != 2.0/3 {
+		t.Errorf("unexpected percentages %+v", summary.Projects)
+	}
+}
+
+// Sunday-start weeks hold the Sunday before Monday; the Saturday before is
+// last week.
+func TestComputeSummary_SundayWeekBoundary(t *testing.T) {
+	start, now := summaryWeek()
+	tasks := []*Task{
+		{ProjectName: "ThisWeek", StartTime: time.Date(2024, 10, 6, 9, 0, 0, 0, time.UTC), Duration: time.Hour},
+		{ProjectName: "ThisWeek", StartTime: time.Date(2024, 10, 8, 9, 0, 0, 0, time.UTC), Duration: time.Hour},
+		{ProjectName: "LastWeek", StartTime: time.Date(2024, 10, 5, 9, 0, 0, 0, time.UTC), Duration: time.Hour},
+	}
+	summary := ComputeSummary(tasks, start, now, BucketDay, time.Sunday, RoundingRules{})
+	if len(summary.Projects) != 1 || summary.Projects[0].ProjectName != "ThisWeek" {
+		t.Fatalf("expected only this week's project, got %+v", summary.Projects)
+	}
+	if days := summary.Projects[0].Buckets; days[0] != time.Hour || days[2] != time.Hour {
+		t.Errorf("expected 1h on Sunday and Tuesday, got %v", days)
+	}
+	if summary.Buckets[0].Label(BucketDay) != "Sun 6" {
+		t.Errorf("expected the week to start on Sunday, got %q", summary.Buckets[0].Label(BucketDay))
+	}
+}
+
+func TestComputeSummary_Clipping(t *testing.T) {
+	start, now := summaryWeek()
+	tasks := []*Task{
+		// Ended before the window.
+		{ProjectName: "Old", StartTime: start.Add(-72 * time.Hour), Duration: time.Hour},
+		// Crosses the window start: only the hour after it counts.
+		{ProjectName: "Cross", StartTime: start.Add(-time.Hour), Duration: 2 * time.Hour},
+		// Wednesday 23:00 for 3 hours spills into Thursday.
+		{ProjectName: "Night", StartTime: time.Date(2024, 10, 9, 23, 0, 0, 0, time.UTC), Duration: 3 * time.Hour},
+		// Runs past now: clipped at now.
+		{ProjectName: "Current", StartTime: now.Add(-2 * time.Hour), Duration: 5 * time.Hour},
+	}
+	summary := ComputeSummary(tasks, start, now, BucketDay, time.Sunday, RoundingRules{})
+	byProject := map[string]ProjectSeries{}
+	for _, p := range summary.Projects {
+		byProject[p.ProjectName] = p
+	}
+	if _, ok := byProject["Old"]; ok {
+		t.Error("expected the task before the window left out")
+	}
+	if cross := byProject["Cross"]; cross.Duration != time.Hour || cross.Buckets[0] != time.Hour {
+		t.Errorf("expected 1h on Sunday after clipping, got %+v", cross)
+	}
+	if night := byProject["Night"]; night.Buckets[3] != time.Hour || night.Buckets[4] != 2*time.Hour {
+		t.Errorf("expected 1h Wednesday and 2h Thursday, got %v", night.Buckets)
+	}
+	if current := byProject["Current"]; current.Duration != 2*time.Hour || current.Buckets[4] != 2*time.Hour {
+		t.Errorf("expected 2h up to now, got %+v", current)
+	}
+}
+
+func TestComputeSummary_SegmentsSplitAcrossDays(t *testing.T) {
+	start, now := summaryWeek()
+	// Paused overnight: 22:00–23:00 Monday, then 08:00–09:30 Tuesday.
+	monday := start.AddDate(0, 0, 1)
+	task := &Task{ProjectName: "Night"}
+	if err := task.SetSegments([]Segment{
+		{Start: monday.Add(22 * time.Hour), End: monday.Add(23 * time.Hour)},
+		{Start: monday.Add(32 * time.Hour), End: monday.Add(33*time.Hour + 30*time.Minute)},
+	}); err != nil {
+		t.Fatalf("failed to set segments: %v", err)
+	}
+
+	summary := ComputeSummary([]*Task{task}, start, now, BucketDay, time.Sunday, RoundingRules{})
+	if len(summary.Projects) != 1 || summary.Projects[0].Duration != 150*time.Minute {
+		t.Fatalf("expected only active time (2h30m), got %+v", summary.Projects)
+	}
+	if days := summary.Projects[0].Buckets; days[1] != time.Hour || days[2] != 90*time.Minute {
+		t.Errorf("expected 1h Monday and 1h30m Tuesday, got %v", days)
+	}
+}
+
+func TestComputeSummary_Timezone(t *testing.T) {
+	loc := time.FixedZone("TZ-5", -5*60*60)
+	now := time.Date(2024, 10, 10, 12, 0, 0, 0, loc)
+	start := StartOfWeek(now, time.Saturday)
+	// 22:00 on Friday in TZ-5 is already Saturday in UTC, but last week here.
+	tasks := []*Task{
+		{ProjectName: "Friday", StartTime: time.Date(2024, 10, 4, 22, 0, 0, 0, loc), Duration: time.Hour},
+		{ProjectName: "Saturday", StartTime: time.Date(2024, 10, 5, 10, 0, 0, 0, loc), Duration: time.Hour},
+	}
+	summary := ComputeSummary(tasks, start, now, BucketDay, time.Saturday, RoundingRules{})
+	if len(summary.Projects) != 1 || summary.Projects[0].ProjectName != "Saturday" || summary.Projects[0].Buckets[0] != time.Hour {
+		t.Errorf("expected only Saturday's hour in its first bucket, got %+v", summary.Projects)
+	}
+}
+
+func TestComputeSummary_PomodorosAndEstimates(t *testing.T) {
+	start, now := summaryWeek()
+	tasks := []*Task{
+		{ProjectName: "Alpha", StartTime: start.Add(9 * time.Hour), EndTime: start.Add(10 * time.Hour), Duration: time.Hour, Pomodoros: 2, Estimate: 45 * time.Minute},
+		{ProjectName: "Alpha", StartTime: start.Add(33 * time.Hour), EndTime: start.Add(35 * time.Hour), Duration: 2 * time.Hour, Pomodoros: 1, Estimate: 3 * time.Hour},
+		// Unestimated time stays out of the comparison.
+		{ProjectName: "Alpha", StartTime: start.Add(40 * time.Hour), EndTime: start.Add(41 * time.Hour), Duration: time.Hour},
+		// Started before the window: its pomodoros and estimate belong to
+		// last week.
+		{ProjectName: "Alpha", StartTime: start.Add(-time.Hour), EndTime: start.Add(time.Hour), Duration: 2 * time.Hour, Pomodoros: 3, Estimate: time.Hour},
+	}
+	summary := ComputeSummary(tasks, start, now, BucketDay, time.Sunday, RoundingRules{})
+	if len(summary.Projects) != 1 {
+		t.Fatalf("expected 1 project, got %+v", summary.Projects)
+	}
+	s := summary.Projects[0]
+	if s.Pomodoros != 3 {
+		t.Errorf("expected 3 pomodoros this week, got %d", s.Pomodoros)
+	}
+	if s.Estimated != 225*time.Minute || s.EstimatedActual != 3*time.Hour || s.Duration != 5*time.Hour {
+		t.Errorf("expected 3h45m estimated against 3h actual of 5h, got %+v", s)
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 // Task represents a time tracking task
 type Task struct {
@@ -10,6 +13,23 @@ type Task struct {
 	StartTime   time.Time
 	EndTime     time.Time
 	Duration    time.Duration
+
+	// Segments holds the active intervals of a task that was paused; a zero
+	// End marks the open segment of a resumed running task. Tasks that were
+	// never paused leave it empty.
+	Segments []Segment
+
+	// Pomodoros counts the Pomodoro work phases completed during the task.
+	Pomodoros int
+
+	// Estimate is the time the task was expected to take, zero for none.
+	Estimate time.Duration
+}
+
+// Segment is an interval of active work within a task.
+type Segment struct {
+	Start time.Time
+	End   time.Time
 }
 
 // NewTask creates a new task with the current time as start time
@@ -26,7 +46,29 @@ func NewTask(projectName, description string) *Task {
 
 // StopTask marks the task as completed and calculates the duration
 func (t *Task) StopTask() {
-	t.EndTime = time.Now().Round(0)
+	t.StopTaskAt(time.Now().Round(0))
+}
+
+// StopTaskAt marks the task as completed at end, dropping any active time
+// after it so that a forgotten timer can be trimmed.
+func (t *Task) StopTaskAt(end time.Time) {
+	t.EndTime = end
+	if len(t.Segments) > 0 {
+		segments := make([]Segment, 0, len(t.Segments))
+		for i, seg := range t.Segments {
+			if i > 0 && seg.Start.After(end) {
+				break
+			}
+			if seg.End.IsZero() || seg.End.After(end) {
+				seg.End = latest(end, seg.Start)
+			}
+			segments = append(segments, seg)
+		}
+		// Stopping while paused ends the task when the last segment did.
+		t.Segments = nil
+		t.SetSegments(segments)
+		return
+	}
 	d := t.EndTime.Sub(t.StartTime)
 	if d < 0 {
 		d = 0
@@ -34,11 +76,170 @@ func (t *Task) StopTask() {
 	t.Duration = d
 }
 
-// UpdateDuration updates the task duration based on start and end times
+// UpdateDuration updates the task duration based on start and end times,
+// or on the segments when the task has them
 func (t *Task) UpdateDuration() {
+	if len(t.Segments) > 0 {
+		t.Duration = SumWithin(t.Segments, time.Time{}, time.Time{})
+		return
+	}
 	d := t.EndTime.Sub(t.StartTime)
 	if d < 0 {
 		d = 0
 	}
 	t.Duration = d
 }
+
+// IsPaused reports whether a running task is paused.
+func (t *Task) IsPaused() bool {
+	return len(t.Segments) > 0 && !t.Segments[len(t.Segments)-1].End.IsZero()
+}
+
+// Pause ends the running task's current active interval at now.
+func (t *Task) Pause(now time.Time) {
+	if t.IsPaused() {
+		return
+	}
+	if len(t.Segments) == 0 {
+		t.Segments = []Segment{{Start: t.StartTime}}
+	}
+	last := &t.Segments[len(t.Segments)-1]
+	if now.Before(last.Start) {
+		now = last.Start
+	}
+	last.End = now
+}
+
+// Resume starts a new active interval of a paused task at now.
+func (t *Task) Resume(now time.Time) {
+	if !t.IsPaused() {
+		return
+	}
+	t.Segments = append(t.Segments, Segment{Start: now})
+}
+
+// ActiveSince returns when a running task's current active interval began.
+func (t *Task) ActiveSince() time.Time {
+	if len(t.Segments) == 0 {
+		return t.StartTime
+	}
+	return t.Segments[len(t.Segments)-1].Start
+}
+
+// Discard removes [from, to) from a running task's current active interval,
+// as if it had been paused for that time. A range reaching back to the
+// interval's start moves the start instead of leaving an empty segment.
+func (t *Task) Discard(from, to time.Time) {
+	if t.IsPaused() {
+		return
+	}
+	from = latest(from, t.ActiveSince())
+	if !to.After(from) {
+		return
+	}
+	if !from.After(t.ActiveSince()) {
+		if len(t.Segments) <= 1 {
+			t.StartTime = to
+		}
+		if len(t.Segments) > 0 {
+			t.Segments[len(t.Segments)-1].Start = to
+		}
+		return
+	}
+	t.Pause(from)
+	t.Resume(to)
+}
+
+// Intervals returns the active intervals of a completed task. A task
+// without segments spans [StartTime, StartTime+Duration).
+func (t *Task) Intervals() []Segment {
+	if len(t.Segments) == 0 {
+		return []Segment{{Start: t.StartTime, End: t.StartTime.Add(t.Duration)}}
+	}
+	return t.Segments
+}
+
+// RunningIntervals returns the active intervals of a running task, ending
+// the current one at now.
+func (t *Task) RunningIntervals(now time.Time) []Segment {
+	if len(t.Segments) == 0 {
+		return []Segment{{Start: t.StartTime, End: latest(now, t.StartTime)}}
+	}
+	intervals := make([]Segment, len(t.Segments))
+	copy(intervals, t.Segments)
+	if last := &intervals[len(intervals)-1]; last.End.IsZero() {
+		last.End = latest(now, last.Start)
+	}
+	return intervals
+}
+
+// Elapsed returns a running task's active time up to now.
+func (t *Task) Elapsed(now time.Time) time.Duration {
+	return SumWithin(t.RunningIntervals(now), time.Time{}, time.Time{})
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// SumWithin totals the parts of intervals inside [from, to). A zero from or
+// to leaves that side unbounded.
+func SumWithin(intervals []Segment, from, to time.Time) time.Duration {
+	var total time.Duration
+	for _, seg := range intervals {
+		start, end := seg.Start, seg.End
+		if !from.IsZero() && start.Before(from) {
+			start = from
+		}
+		if !to.IsZero() && end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// SetSegments replaces a completed task's active intervals, which must be
+// ordered and must not overlap, and updates the start, end and duration to
+// match. A single interval is stored as a plain start and end.
+func (t *Task) SetSegments(segments []Segment) error {
+	if len(segments) == 0 {
+		return errors.New("at least one segment is required")
+	}
+	for i, seg := range segments {
+		if seg.End.IsZero() || seg.End.Before(seg.Start) {
+			return errors.New("segment end must be after or equal to its start")
+		}
+		if i > 0 && seg.Start.Before(segments[i-1].End) {
+			return errors.New("segments must be in order and must not overlap")
+		}
+	}
+	t.StartTime = segments[0].Start
+	t.EndTime = segments[len(segments)-1].End
+	t.Segments = nil
+	if len(segments) > 1 {
+		t.Segments = segments
+	}
+	t.UpdateDuration()
+	return nil
+}
+
+// Overlaps reports whether the task's [StartTime, StartTime+Duration) interval
+// intersects [from, to). A zero from or to leaves that side unbounded.
+func (t *Task) Overlaps(from, to time.Time) bool {
+	if !to.IsZero() && !t.StartTime.Before(to) {
+		return false
+	}
+	if from.IsZero() {
+		return true
+	}
+	intervals := t.Intervals()
+	end := intervals[len(intervals)-1].End
+	// Zero-length entries count as inside the window they start in.
+	return end.After(from) || (t.Duration == 0 && !t.StartTime.Before(from))
+}
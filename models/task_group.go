@@ -6,120 +6,20 @@ import (
 	"time"
 )
 
-// WeeklySummary holds the total tracked duration for a project over a time window.
-type WeeklySummary struct {
-	ProjectName    string
-	Duration       time.Duration
-	DailyDurations [7]time.Duration // Monday (index 0) through Sunday (index 6)
-	Percentage     float64          // fraction of the largest project's duration (0.0–1.0)
+// StartOfWeek returns midnight on the first day of the week that contains t,
+// for weeks starting on first, using t's timezone.
+func StartOfWeek(t time.Time, first time.Weekday) time.Time {
+	y, m, d := t.Date()
+	back := (int(t.Weekday()) - int(first) + 7) % 7
+	return time.Date(y, m, d-back, 0, 0, 0, 0, t.Location())
 }
 
-// StartOfCurrentWeek returns midnight on the Monday of the week that contains
-// now, using now's timezone.
-func StartOfCurrentWeek(now time.Time) time.Time {
-	y, m, d := now.Date()
-	wd := int(now.Weekday()) // Sunday=0, Monday=1, …, Saturday=6
-	if wd == 0 {
-		wd = 7 // treat Sunday as day 7 so Monday is always day 1
-	}
-	return time.Date(y, m, d-(wd-1), 0, 0, 0, 0, now.Location())
-}
-
-// ComputeWeeklySummaries aggregates completed task durations per project for
-// the window [windowStart … now], clipping each task's duration to that range.
-// Returns summaries sorted by duration descending, name ascending as a
-// tiebreaker.
-func ComputeWeeklySummaries(tasks []*Task, now time.Time, windowStart time.Time) []WeeklySummary {
-	windowEnd := now
-
-	projectSummaries := make(map[string]*WeeklySummary)
-	for _, task := range tasks {
-		taskStart := task.StartTime
-		taskEnd := task.StartTime.Add(task.Duration)
-
-		start := taskStart
-		if start.Before(windowStart) {
-			start = windowStart
-		}
-		end := taskEnd
-		if end.After(windowEnd) {
-			end = windowEnd
-		}
-		if !end.After(start) {
-			continue
-		}
-
-		summary, ok := projectSummaries[task.ProjectName]
-		if !ok {
-			summary = &WeeklySummary{ProjectName: task.ProjectName}
-			projectSummaries[task.ProjectName] = summary
-		}
-
-		// Split each clipped task overlap into day-sized segments so each segment
-		// can be accumulated into the correct Monday–Sunday bucket.
-		segmentDayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
-		for segmentDayStart.Before(end) {
-			nextDay := segmentDayStart.AddDate(0, 0, 1)
-			segmentStart := start
-			if segmentStart.Before(segmentDayStart) {
-				segmentStart = segmentDayStart
-			}
-			segmentEnd := end
-			if segmentEnd.After(nextDay) {
-				segmentEnd = nextDay
-			}
-			if segmentEnd.After(segmentStart) {
-				segmentDuration := segmentEnd.Sub(segmentStart)
-				if dayIdx := weekDayIndex(segmentDayStart, windowStart); dayIdx >= 0 {
-					summary.DailyDurations[dayIdx] += segmentDuration
-				}
-				summary.Duration += segmentDuration
-			}
-			segmentDayStart = nextDay
-		}
-	}
-
-	if len(projectSummaries) == 0 {
-		return nil
-	}
-
-	summaries := make([]WeeklySummary, 0, len(projectSummaries))
-	var maxDuration time.Duration
-	for _, summary := range projectSummaries {
-		summaries = append(summaries, *summary)
-		if summary.Duration > maxDuration {
-			maxDuration = summary.Duration
-		}
-	}
-
-	sort.Slice(summaries, func(i, j int) bool {
-		if summaries[i].Duration != summaries[j].Duration {
-			return summaries[i].Duration > summaries[j].Duration
-		}
-		return summaries[i].ProjectName < summaries[j].ProjectName
-	})
-
-	if maxDuration > 0 {
-		for i := range summaries {
-			summaries[i].Percentage = float64(summaries[i].Duration) / float64(maxDuration)
-		}
-	}
-
-	return summaries
-}
-
-func weekDayIndex(dayStart time.Time, weekStart time.Time) int {
-	loc := weekStart.Location()
-	dayStart = dayStart.In(loc)
-	dayYear, dayMonth, dayDate := dayStart.Date()
-	for i := 0; i < 7; i++ {
-		candidate := weekStart.AddDate(0, 0, i)
-		cy, cm, cd := candidate.Date()
-		if dayYear == cy && dayMonth == cm && dayDate == cd {
-			return i
-		}
-	}
-	return -1
+// ISOWeekOf returns the ISO 8601 year and number of the week starting at
+// weekStart. Weeks not starting on Monday take the number of the ISO week
+// holding their Thursday, and so most of their days.
+func ISOWeekOf(weekStart time.Time) (year, week int) {
+	thursday := weekStart.AddDate(0, 0, (int(time.Thursday)-int(weekStart.Weekday())+7)%7)
+	return thursday.ISOWeek()
 }
 
 type TaskGroup struct {
@@ -139,6 +39,7 @@ type FlatListItem struct {
 	Title    string
 	Subtitle string
 	Task     *Task
+	Date     time.Time // the day of a header
 }
 
 func FlattenTaskGroups(groups []TaskGroup) []FlatListItem {
@@ -158,6 +59,7 @@ func FlattenTaskGroups(groups []TaskGroup) []FlatListItem {
 			Type:     ItemTypeHeader,
 			Title:    group.Date.Format("Monday, January 2"),
 			Subtitle: fmt.Sprintf("Total: %v", totalDuration.Round(time.Second)),
+			Date:     group.Date,
 		})
 
 		// Tasks
@@ -165,7 +67,7 @@ func FlattenTaskGroups(groups []TaskGroup) []FlatListItem {
 			items = append(items, FlatListItem{
 				Type:     ItemTypeTask,
 				Title:    task.ProjectName,
-				Subtitle: fmt.Sprintf("%s (%v)", task.Description, task.Duration.Round(time.Second)),
+				Subtitle: taskSubtitle(task),
 				Task:     task,
 			})
 		}
@@ -173,6 +75,34 @@ func FlattenTaskGroups(groups []TaskGroup) []FlatListItem {
 	return items
 }
 
+// DayHeaderIndex returns the index in items, newest first as returned by
+// FlattenTaskGroups, of the header of day or, when nothing was started that
+// day, of the closest day before it. It returns -1 when there is none.
+func DayHeaderIndex(items []FlatListItem, day time.Time) int {
+	y, m, d := day.Date()
+	for i, item := range items {
+		if item.Type != ItemTypeHeader {
+			continue
+		}
+		hy, hm, hd := item.Date.In(day.Location()).Date()
+		if !time.Date(hy, hm, hd, 0, 0, 0, 0, time.UTC).After(time.Date(y, m, d, 0, 0, 0, 0, time.UTC)) {
+			return i
+		}
+	}
+	return -1
+}
+
+func taskSubtitle(task *Task) string {
+	subtitle := fmt.Sprintf("%s (%v)", task.Description, task.Duration.Round(time.Second))
+	if pomodoros := FormatPomodoros(task.Pomodoros); pomodoros != "" {
+		subtitle += " · " + pomodoros
+	}
+	if task.Estimate > 0 {
+		subtitle += " · estimate " + FormatEstimate(task.Estimate)
+	}
+	return subtitle
+}
+
 func GroupTasksByDate(tasks []*Task) []TaskGroup {
 	// Create a map to group tasks by date
 	type dateKey struct {
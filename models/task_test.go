@@ -87,3 +87,168 @@ func TestUpdateDurationWithClockRollback(t *testing.T) {
 		t.Errorf("expected duration 0 due to clock rollback, got %v", task.Duration)
 	}
 }
+
+func TestOverlaps(t *testing.T) {
+	base := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	task := &Task{StartTime: base, EndTime: base.Add(time.Hour), Duration: time.Hour}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		expected bool
+	}{
+		{"unbounded", time.Time{}, time.Time{}, true},
+		{"inside", base.Add(-time.Hour), base.Add(2 * time.Hour), true},
+		{"overlaps start", base.Add(-time.Hour), base.Add(time.Minute), true},
+		{"overlaps end", base.Add(59 * time.Minute), time.Time{}, true},
+		{"ends at from", base.Add(time.Hour), time.Time{}, false},
+		{"starts at to", time.Time{}, base, false},
+		{"before window", base.Add(2 * time.Hour), base.Add(3 * time.Hour), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := task.Overlaps(tc.from, tc.to); got != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+
+	instant := &Task{StartTime: base, EndTime: base}
+	if !instant.Overlaps(base, base.Add(time.Hour)) {
+		t.Error("expected zero-length task at window start to overlap")
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	task := &Task{ProjectName: "P", StartTime: start, EndTime: start}
+
+	if task.IsPaused() {
+		t.Fatal("expected a new task not to be paused")
+	}
+	if got := task.Elapsed(start.Add(10 * time.Minute)); got != 10*time.Minute {
+		t.Errorf("expected 10m elapsed before pausing, got %v", got)
+	}
+
+	task.Pause(start.Add(20 * time.Minute))
+	if !task.IsPaused() {
+		t.Fatal("expected task to be paused")
+	}
+	if got := task.Elapsed(start.Add(time.Hour)); got != 20*time.Minute {
+		t.Errorf("expected elapsed to stop while paused, got %v", got)
+	}
+
+	task.Resume(start.Add(30 * time.Minute))
+	if task.IsPaused() {
+		t.Fatal("expected task to be resumed")
+	}
+	if got := task.Elapsed(start.Add(40 * time.Minute)); got != 30*time.Minute {
+		t.Errorf("expected 30m elapsed after resuming, got %v", got)
+	}
+	if got := SumWithin(task.RunningIntervals(start.Add(40*time.Minute)), start.Add(15*time.Minute), start.Add(35*time.Minute)); got != 10*time.Minute {
+		t.Errorf("expected 10m inside the window, got %v", got)
+	}
+
+	task.StopTask()
+	if len(task.Segments) != 2 || !task.EndTime.Equal(task.Segments[1].End) {
+		t.Fatalf("expected two closed segments ending at EndTime, got %+v", task.Segments)
+	}
+	if want := 20*time.Minute + task.EndTime.Sub(start.Add(30*time.Minute)); task.Duration != want {
+		t.Errorf("expected duration %v to exclude the pause, got %v", want, task.Duration)
+	}
+}
+
+func TestStopTaskWhilePaused(t *testing.T) {
+	start := time.Now().Add(-time.Hour).Round(0)
+	task := &Task{ProjectName: "P", StartTime: start, EndTime: start}
+	task.Pause(start.Add(15 * time.Minute))
+	task.StopTask()
+
+	if len(task.Segments) != 0 {
+		t.Errorf("expected a single interval to be stored without segments, got %+v", task.Segments)
+	}
+	if !task.EndTime.Equal(start.Add(15*time.Minute)) || task.Duration != 15*time.Minute {
+		t.Errorf("expected the task to end when paused, got end %v duration %v", task.EndTime, task.Duration)
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	task := &Task{ProjectName: "P", StartTime: start, EndTime: start}
+
+	task.Discard(start.Add(20*time.Minute), start.Add(50*time.Minute))
+	if task.IsPaused() {
+		t.Fatal("expected the task to keep running after discarding")
+	}
+	if got := task.Elapsed(start.Add(time.Hour)); got != 30*time.Minute {
+		t.Errorf("expected 30m elapsed after discarding 30m, got %v", got)
+	}
+	if !task.ActiveSince().Equal(start.Add(50 * time.Minute)) {
+		t.Errorf("expected the active interval to restart at 09:50, got %v", task.ActiveSince())
+	}
+
+	// A range starting before the active interval only trims its start.
+	task.Discard(start.Add(40*time.Minute), start.Add(55*time.Minute))
+	if len(task.Segments) != 2 || !task.Segments[1].Start.Equal(start.Add(55*time.Minute)) {
+		t.Fatalf("expected the second segment to start at 09:55, got %+v", task.Segments)
+	}
+
+	fresh := &Task{ProjectName: "P", StartTime: start, EndTime: start}
+	fresh.Discard(start.Add(-time.Hour), start.Add(10*time.Minute))
+	if len(fresh.Segments) != 0 || !fresh.StartTime.Equal(start.Add(10*time.Minute)) {
+		t.Errorf("expected the start to move to 09:10 without segments, got %v %+v", fresh.StartTime, fresh.Segments)
+	}
+}
+
+func TestSetSegments(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	task := &Task{}
+
+	invalid := [][]Segment{
+		nil,
+		{{Start: base, End: base.Add(-time.Minute)}},
+		{{Start: base, End: base.Add(time.Hour)}, {Start: base.Add(30 * time.Minute), End: base.Add(2 * time.Hour)}},
+		{{Start: base}},
+	}
+	for i, segments := range invalid {
+		if err := task.SetSegments(segments); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+
+	if err := task.SetSegments([]Segment{
+		{Start: base, End: base.Add(time.Hour)},
+		{Start: base.Add(2 * time.Hour), End: base.Add(150 * time.Minute)},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !task.StartTime.Equal(base) || !task.EndTime.Equal(base.Add(150*time.Minute)) || task.Duration != 90*time.Minute {
+		t.Errorf("unexpected task after SetSegments: %+v", task)
+	}
+	if !task.Overlaps(base.Add(140*time.Minute), time.Time{}) {
+		t.Error("expected the last segment to count for overlap")
+	}
+}
+
+func TestStopTaskAt_Trims(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	task := &Task{ProjectName: "P", StartTime: start, EndTime: start}
+	task.Pause(start.Add(time.Hour))
+	task.Resume(start.Add(2 * time.Hour))
+	task.Pause(start.Add(3 * time.Hour))
+	task.Resume(start.Add(4 * time.Hour))
+
+	task.StopTaskAt(start.Add(150 * time.Minute))
+	if len(task.Segments) != 2 || !task.EndTime.Equal(start.Add(150*time.Minute)) {
+		t.Fatalf("expected two segments ending at 11:30, got %+v", task.Segments)
+	}
+	if task.Duration != 90*time.Minute {
+		t.Errorf("expected 90m after trimming, got %v", task.Duration)
+	}
+
+	plain := &Task{ProjectName: "P", StartTime: start, EndTime: start}
+	plain.StopTaskAt(start.Add(-time.Minute))
+	if plain.Duration != 0 {
+		t.Errorf("expected a zero duration for an end before the start, got %v", plain.Duration)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"trackyou/database"
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+)
+
+// breakProject is the project Pomodoro breaks are recorded under when they
+// are not left as gaps.
+const breakProject = "Break"
+
+// pomodoroRun is the Pomodoro cycle of the running timer.
+type pomodoroRun struct {
+	*models.Pomodoro
+	// project and description are the work task's, restarted after a break
+	// recorded under breakProject.
+	project, description string
+	breaks               string
+	// pausedForBreak is set while a break leaves a gap in the work task.
+	pausedForBreak bool
+}
+
+// startPomodoroUnlocked begins a Pomodoro cycle for task when Pomodoro mode
+// is on. Callers hold a.mu.
+func (a *App) startPomodoroUnlocked(task *models.Task) {
+	a.pomodoro = nil
+	if !a.prefs.PomodoroEnabled {
+		return
+	}
+	a.pomodoro = &pomodoroRun{
+		Pomodoro:    models.NewPomodoro(a.prefs.PomodoroSettings(), task.StartTime),
+		project:     task.ProjectName,
+		description: task.Description,
+		breaks:      a.prefs.PomodoroBreaks,
+	}
+}
+
+// tickPomodoro moves the cycle on when the current phase has ended,
+// recording the break and notifying the user. Returns true on a transition.
+func (a *App) tickPomodoro(now time.Time) bool {
+	a.mu.Lock()
+	run := a.pomodoro
+	task := a.currentTask
+	if run == nil || task == nil {
+		a.mu.Unlock()
+		return false
+	}
+	at, ok := run.Advance(now)
+	if !ok {
+		a.mu.Unlock()
+		return false
+	}
+	phase := run.Phase
+	if phase.IsBreak() {
+		task.Pomodoros++
+	}
+	gaps := run.breaks != database.PomodoroBreaksProject
+	if gaps && phase.IsBreak() && !task.IsPaused() {
+		task.Pause(at)
+		run.pausedForBreak = true
+	} else if gaps && !phase.IsBreak() && run.pausedForBreak {
+		task.Resume(at)
+		run.pausedForBreak = false
+	}
+	a.mu.Unlock()
+
+	if !gaps {
+		project, description := run.project, run.description
+		if phase.IsBreak() {
+			project, description = breakProject, ""
+		}
+		if _, err := a.handOverAt(at, project, description); err != nil {
+			a.showDialogError(err)
+		}
+	}
+	a.mu.RLock()
+	paused := a.currentTask != nil && a.currentTask.IsPaused()
+	a.mu.RUnlock()
+	a.updatePauseButton(paused)
+	a.refreshTimerSummary()
+
+	message := fmt.Sprintf("Break over. Back to %s!", run.project)
+	if phase.IsBreak() {
+		message = fmt.Sprintf("Pomodoro %d done. Take a %d minute %s.",
+			run.Completed, int(run.PhaseEnd().Sub(run.PhaseStart).Minutes()), phaseNoun(phase))
+	}
+	a.app.SendNotification(fyne.NewNotification("TrackYou", message))
+	return true
+}
+
+func phaseNoun(phase models.PomodoroPhase) string {
+	if phase == models.PhaseLongBreak {
+		return "long break"
+	}
+	return "break"
+}
+
+// endPomodoroBreakUnlocked starts the next work phase early when the user
+// resumes during a break that left a gap. Callers hold a.mu.
+func (a *App) endPomodoroBreakUnlocked(now time.Time) {
+	if run := a.pomodoro; run != nil && run.pausedForBreak {
+		run.SkipTo(models.PhaseWork, now)
+		run.pausedForBreak = false
+	}
+}
+
+// pomodoroStatus returns the countdown shown in the timer label, or "" when
+// Pomodoro mode is off.
+func (a *App) pomodoroStatus(now time.Time) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	run := a.pomodoro
+	if run == nil {
+		return ""
+	}
+	remaining := run.Remaining(now).Round(time.Second)
+	return fmt.Sprintf("%s %02d:%02d", run.Phase, int(remaining.Minutes()), int(remaining.Seconds())%60)
+}
+
+// handOverAt stops the running task at at and starts project in its place
+// at the same instant, so the two entries neither overlap nor leave a gap.
+// A running Pomodoro cycle carries over.
+func (a *App) handOverAt(at time.Time, project, description string) (*models.Task, error) {
+	a.mu.Lock()
+	if a.currentTask == nil {
+		a.mu.Unlock()
+		return nil, errNoTaskRunning
+	}
+	if project == "" {
+		a.mu.Unlock()
+		return nil, errProjectRequired
+	}
+	// The running task stays in place until its stopped copy is saved, so
+	// a failed save loses nothing.
+	previous := *a.currentTask
+	previous.StopTaskAt(at)
+	a.mu.Unlock()
+
+	if err := a.db.SaveTask(&previous); err != nil {
+		return nil, err
+	}
+	next := &models.Task{ProjectName: project, Description: description, StartTime: at, EndTime: at}
+	a.mu.Lock()
+	a.currentTask = next
+	a.awaySince = time.Time{}
+	a.lastActivity = time.Time{}
+	a.forgottenNotified = false
+	a.estimateNotified = false
+	a.tasks = append([]*models.Task{&previous}, a.tasks...)
+	a.updateTaskGroups()
+	a.mu.Unlock()
+
+	a.projectEntry.SetText(project)
+	a.descriptionEntry.SetText(description)
+	a.estimateEntry.SetText("")
+	a.showEstimateProgress(0, 0)
+	a.updatePauseButton(false)
+	a.refreshTaskViews()
+	return next, nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"trackyou/config"
+
+	"fyne.io/fyne/v2"
+)
+
+// loadConfigFile reads config.toml. On error the previously loaded contents
+// stay in effect.
+func (a *App) loadConfigFile() error {
+	if a.configPath == "" {
+		return nil
+	}
+	file, err := config.Load(a.configPath)
+	if err != nil {
+		return err
+	}
+	for _, key := range file.Unknown {
+		fmt.Fprintf(os.Stderr, "Ignoring unknown key %q in %s\n", key, a.configPath)
+	}
+	a.mu.Lock()
+	a.configFile = file
+	a.mu.Unlock()
+	return nil
+}
+
+// loadPreferences resolves the preferences from config.toml, the open
+// database and the defaults, and applies them.
+func (a *App) loadPreferences() {
+	a.mu.RLock()
+	file := a.configFile
+	a.mu.RUnlock()
+
+	prefs, err := config.Resolve(file, a.db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load preferences: %v\n", err)
+	}
+	holidays, err := a.loadHolidays(prefs.HolidaysFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load holidays: %v\n", err)
+	}
+	absences, err := a.db.GetAbsences()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load absences: %v\n", err)
+	}
+	schedule := prefs.WorkSchedule(holidays, absences)
+	budgets, err := a.db.GetBudgets()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load budgets: %v\n", err)
+	}
+	digestSent, err := a.db.GetDigestSent()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load the last digest: %v\n", err)
+	}
+
+	a.mu.Lock()
+	a.prefs = prefs
+	a.idleThreshold = prefs.IdleThreshold
+	a.workdayLength = prefs.WorkdayLength
+	a.schedule = schedule
+	a.budgets = budgets
+	a.digestSent = digestSent
+	// A raised goal that is no longer met should notify again when it is.
+	if a.calculateTotalDurationTodayUnlocked() < schedule.Target(time.Now()) {
+		a.goalReachedToday = false
+	}
+	a.mu.Unlock()
+
+	a.useTheme(prefs.Theme)
+}
+
+// reloadPreferences re-resolves and applies the preferences, refreshing the
+// summary and restarting the local API if its settings changed.
+func (a *App) reloadPreferences() {
+	a.mu.RLock()
+	oldEnabled, oldPort := a.prefs.APIEnabled, a.prefs.APIPort
+	a.mu.RUnlock()
+
+	a.loadPreferences()
+	if a.totalLabel != nil {
+		a.updateSummaryUI(false)
+	}
+	// The rounding rules may have changed under rounded totals.
+	a.refreshWeeklyChart()
+	// The workday length and flex-time start set the balance.
+	a.refreshFlex()
+	// Holidays, absences and allowances set the absence balances.
+	a.refreshAbsencesView()
+	// The first day of the week and the targets shape the heatmap.
+	a.refreshActivityView()
+	// Budgets and the first day of the week set the budget shown in the
+	// Start area.
+	a.showBudgetProgress(time.Now())
+	// Task switching decides whether the Start area stays editable.
+	a.mu.RLock()
+	running := a.currentTask != nil
+	paused := running && a.currentTask.IsPaused()
+	a.mu.RUnlock()
+	a.updateButtonsState(running)
+	a.updatePauseButton(paused)
+	a.writeStateFile()
+
+	a.mu.RLock()
+	apiChanged := a.prefs.APIEnabled != oldEnabled || a.prefs.APIPort != oldPort
+	a.mu.RUnlock()
+	if apiChanged {
+		a.stopAPIServer()
+		if err := a.startAPIServer(); err != nil {
+			a.showDialogError(err)
+		}
+	}
+}
+
+// reloadConfig applies config.toml after it changes on disk.
+func (a *App) reloadConfig() {
+	if err := a.loadConfigFile(); err != nil {
+		a.showDialogError(err)
+		return
+	}
+	a.reloadPreferences()
+}
+
+// watchConfig reloads config.toml whenever it changes until ctx is done.
+func (a *App) watchConfig(ctx context.Context) {
+	if a.configPath == "" {
+		return
+	}
+	err := config.Watch(ctx, a.configPath, func() {
+		fyne.Do(a.reloadConfig)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to watch %s: %v\n", a.configPath, err)
+	}
+}
+
+// preferenceHint describes where the setting for key comes from.
+func (a *App) preferenceHint(key string) string {
+	a.mu.RLock()
+	source := a.prefs.Sources[key]
+	a.mu.RUnlock()
+	switch source {
+	case config.SourceFile:
+		return "Set in " + config.FileName
+	case config.SourceDatabase:
+		return "Saved setting"
+	default:
+		return "Default"
+	}
+}
+
+// preferenceFromFile reports whether config.toml sets key, in which case
+// the Settings dialog cannot change it.
+func (a *App) preferenceFromFile(key string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.prefs.Sources[key] == config.SourceFile
+}
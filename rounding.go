@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"trackyou/config"
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Rounding modes and scopes in display order, with their labels.
+var (
+	roundingModes      = []string{models.RoundNearest, models.RoundUp, models.RoundDown}
+	roundingModeLabels = []string{"Nearest", "Up", "Down"}
+	roundingPers       = []string{models.RoundPerEntry, models.RoundPerDay}
+	roundingPerLabels  = []string{"Entry", "Day"}
+)
+
+// roundingRuleLabel describes a rule, e.g. "6 min, up, per entry".
+func roundingRuleLabel(r models.RoundingRule) string {
+	if !r.Enabled() {
+		return "Off"
+	}
+	return fmt.Sprintf("%d min, %s, per %s", int(r.Increment/time.Minute), r.Mode, r.Per)
+}
+
+// showRoundingDialog lists the rounding rules of clients and projects and
+// the client of each project, with controls to set and remove them. Rules
+// and clients set in config.toml are listed but can only be changed there.
+func (a *App) showRoundingDialog() {
+	// row shows text with a button calling remove, or where it is set when
+	// key comes from config.toml.
+	row := func(text, key string, remove func() error, refresh func()) fyne.CanvasObject {
+		if a.preferenceFromFile(key) {
+			hint := widget.NewLabel(a.preferenceHint(key))
+			hint.Importance = widget.LowImportance
+			return container.NewHBox(widget.NewLabel(text), layout.NewSpacer(), hint)
+		}
+		button := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			if err := remove(); err != nil {
+				a.showDialogError(err)
+			}
+			refresh()
+		})
+		button.Importance = widget.LowImportance
+		return container.NewHBox(widget.NewLabel(text), layout.NewSpacer(), button)
+	}
+
+	rows := container.NewVBox()
+	var refresh func()
+	refresh = func() {
+		rows.RemoveAll()
+		a.mu.RLock()
+		rules := map[string]map[string]models.RoundingRule{
+			models.RoundingForClient:  a.prefs.RoundingClients,
+			models.RoundingForProject: a.prefs.RoundingProjects,
+		}
+		projectClients := a.prefs.ProjectClients
+		a.mu.RUnlock()
+
+		rows.Add(widget.NewLabelWithStyle("Rules", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		if len(rules[models.RoundingForClient])+len(rules[models.RoundingForProject]) == 0 {
+			rows.Add(widget.NewLabel("No rules yet; every project uses the rule in Settings."))
+		}
+		for _, scope := range []string{models.RoundingForClient, models.RoundingForProject} {
+			for _, name := range slices.Sorted(maps.Keys(rules[scope])) {
+				text := fmt.Sprintf("%s %s · %s", strings.ToUpper(scope[:1])+scope[1:], name, roundingRuleLabel(rules[scope][name]))
+				rows.Add(row(text, config.RoundingRuleKey(scope, name), func() error {
+					return a.removeRoundingRule(scope, name)
+				}, refresh))
+			}
+		}
+
+		rows.Add(widget.NewLabelWithStyle("Clients", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		if len(projectClients) == 0 {
+			rows.Add(widget.NewLabel("No projects assigned to clients yet."))
+		}
+		for _, project := range slices.Sorted(maps.Keys(projectClients)) {
+			text := fmt.Sprintf("%s → %s", project, projectClients[project])
+			rows.Add(row(text, config.ClientKey(project), func() error {
+				return a.setProjectClient(project, "")
+			}, refresh))
+		}
+		rows.Refresh()
+	}
+	refresh()
+
+	projects, err := a.db.GetProjectNames()
+	if err != nil {
+		a.showDialogError(err)
+	}
+	clients := func() []string {
+		a.mu.RLock()
+		defer a.mu.RUnlock()
+		names := slices.Collect(maps.Keys(a.prefs.RoundingClients))
+		names = append(names, slices.Collect(maps.Values(a.prefs.ProjectClients))...)
+		slices.Sort(names)
+		return slices.Compact(names)
+	}
+
+	nameEntry := widget.NewSelectEntry(projects)
+	nameEntry.SetPlaceHolder("Project")
+	scopeSelect := widget.NewSelect([]string{"Project", "Client"}, func(label string) {
+		if label == "Client" {
+			nameEntry.SetOptions(clients())
+			nameEntry.SetPlaceHolder("Client")
+		} else {
+			nameEntry.SetOptions(projects)
+			nameEntry.SetPlaceHolder("Project")
+		}
+	})
+	scopeSelect.SetSelectedIndex(0)
+	minutesEntry := widget.NewEntry()
+	minutesEntry.SetPlaceHolder("Minutes")
+	modeSelect := widget.NewSelect(roundingModeLabels, nil)
+	modeSelect.SetSelectedIndex(0)
+	perSelect := widget.NewSelect(roundingPerLabels, nil)
+	perSelect.SetSelectedIndex(0)
+	setRule := widget.NewButtonWithIcon("Set", theme.ContentAddIcon(), func() {
+		scope := models.RoundingForProject
+		if scopeSelect.SelectedIndex() == 1 {
+			scope = models.RoundingForClient
+		}
+		err := a.setRoundingRule(scope, nameEntry.Text, minutesEntry.Text,
+			roundingModes[modeSelect.SelectedIndex()], roundingPers[perSelect.SelectedIndex()])
+		if err != nil {
+			a.showDialogError(err)
+			return
+		}
+		nameEntry.SetText("")
+		minutesEntry.SetText("")
+		refresh()
+	})
+
+	projectEntry := widget.NewSelectEntry(projects)
+	projectEntry.SetPlaceHolder("Project")
+	clientEntry := widget.NewSelectEntry(clients())
+	clientEntry.SetPlaceHolder("Client")
+	assign := widget.NewButtonWithIcon("Assign", theme.ContentAddIcon(), func() {
+		if strings.TrimSpace(clientEntry.Text) == "" {
+			a.showDialogError(fmt.Errorf("enter the client to bill the project to"))
+			return
+		}
+		if err := a.setProjectClient(projectEntry.Text, clientEntry.Text); err != nil {
+			a.showDialogError(err)
+			return
+		}
+		projectEntry.SetText("")
+		clientEntry.SetText("")
+		clientEntry.SetOptions(clients())
+		refresh()
+	})
+
+	noteLabel := widget.NewLabel("A project's own rule wins over its client's, which wins over the rule in Settings. Rules apply to reports, summaries and exports only.")
+	noteLabel.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(420, 240))
+	content := container.NewBorder(
+		noteLabel,
+		container.NewVBox(
+			container.NewBorder(nil, nil, nil, setRule, container.NewGridWithColumns(5, scopeSelect, nameEntry, minutesEntry, modeSelect, perSelect)),
+			container.NewBorder(nil, nil, nil, assign, container.NewGridWithColumns(2, projectEntry, clientEntry)),
+		),
+		nil, nil,
+		scroll,
+	)
+	dialog.ShowCustom("Rounding Rules", "Close", content, a.window)
+}
+
+// setRoundingRule rounds the durations of a project or client to minutes,
+// a whole number where 0 turns rounding off, in mode, per entry or day.
+func (a *App) setRoundingRule(scope, name, minutes, mode, per string) error {
+	name = strings.TrimSpace(name)
+	if a.preferenceFromFile(config.RoundingRuleKey(scope, name)) {
+		return fmt.Errorf("the rule for %s is set in %s", name, config.FileName)
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(minutes))
+	if err != nil || value < 0 {
+		return fmt.Errorf("invalid rounding value")
+	}
+	r := models.ScopedRoundingRule{
+		Scope:        scope,
+		Name:         name,
+		RoundingRule: models.RoundingRule{Increment: time.Duration(value) * time.Minute, Mode: mode, Per: per},
+	}
+	if err := a.db.SetRoundingRule(r); err != nil {
+		return err
+	}
+	a.reloadPreferences()
+	return nil
+}
+
+// removeRoundingRule deletes the rounding rule of a project or client.
+func (a *App) removeRoundingRule(scope, name string) error {
+	if err := a.db.DeleteRoundingRule(scope, name); err != nil {
+		return err
+	}
+	a.reloadPreferences()
+	return nil
+}
+
+// setProjectClient bills project to client, or to none when client is
+// empty.
+func (a *App) setProjectClient(project, client string) error {
+	project = strings.TrimSpace(project)
+	if project == "" {
+		return fmt.Errorf("enter the project to assign")
+	}
+	if a.preferenceFromFile(config.ClientKey(project)) {
+		return fmt.Errorf("the client of %s is set in %s", project, config.FileName)
+	}
+	if err := a.db.SetProjectClient(project, strings.TrimSpace(client)); err != nil {
+		return err
+	}
+	a.reloadPreferences()
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trackyou/database"
+	"trackyou/idle"
+
+	"fyne.io/fyne/v2"
+)
+
+// minSleep is the shortest suspend or screen lock the sleep policy applies
+// to. It is also how far the wall clock must jump between two timer ticks
+// for the gap to count as a suspend the session did not announce.
+const minSleep = time.Minute
+
+// monitorSleep applies the sleep policy to the suspends and screen locks
+// reported by the session.
+func (a *App) monitorSleep(ctx context.Context) {
+	if a.sleepWatcher == nil {
+		return
+	}
+	events := a.sleepWatcher.Events()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			fyne.Do(func() { a.handleSleepEvent(ev) })
+		}
+	}
+}
+
+// handleSleepEvent tracks whether the machine is suspended or locked. A
+// suspend usually happens inside a lock, so the period without the user ends
+// only once both are over.
+func (a *App) handleSleepEvent(ev idle.SleepEvent) {
+	a.mu.Lock()
+	wasAsleep := a.suspended || a.locked
+	switch ev.Kind {
+	case idle.Suspend:
+		a.suspended = true
+	case idle.Resume:
+		a.suspended = false
+	case idle.Lock:
+		a.locked = true
+	case idle.Unlock:
+		a.locked = false
+	}
+	asleep := a.suspended || a.locked
+	from := a.sleepSince
+	if asleep && !wasAsleep {
+		a.sleepSince = ev.At
+	} else if !asleep {
+		a.sleepSince = time.Time{}
+	}
+	a.mu.Unlock()
+
+	if wasAsleep && !asleep && !from.IsZero() {
+		a.applySleepPolicy(from, ev.At)
+	}
+}
+
+// checkClockJump treats a jump in the wall clock between the timer ticks at
+// last and now as a suspend, for sessions that do not announce them. Both
+// times must be wall-clock readings: the monotonic clock stops while the
+// machine sleeps.
+func (a *App) checkClockJump(last, now time.Time) {
+	if last.IsZero() || now.Sub(last) < minSleep {
+		return
+	}
+	a.mu.RLock()
+	// An announced suspend is handled when the session reports the wake-up.
+	announced := !a.sleepSince.IsZero()
+	a.mu.RUnlock()
+	if !announced {
+		a.applySleepPolicy(last, now)
+	}
+}
+
+// applySleepPolicy deals with the running task's time over [from, to), while
+// the machine slept or was locked: it pauses the task from the start of the
+// period, asks what to do with it, or leaves it counted.
+func (a *App) applySleepPolicy(from, to time.Time) {
+	a.mu.Lock()
+	// The same sleep may be reported by the session and by the clock.
+	if from.Before(a.sleepHandledUntil) {
+		from = a.sleepHandledUntil
+	}
+	task := a.currentTask
+	if task == nil || task.IsPaused() {
+		a.mu.Unlock()
+		return
+	}
+	if since := task.ActiveSince(); from.Before(since) {
+		from = since
+	}
+	if to.Sub(from) < minSleep {
+		a.mu.Unlock()
+		return
+	}
+	a.sleepHandledUntil = to
+	// The away prompt must not offer the same time again.
+	a.awaySince = time.Time{}
+
+	switch a.prefs.SleepPolicy {
+	case database.SleepPolicyPause:
+		task.Pause(from)
+		project := task.ProjectName
+		a.mu.Unlock()
+
+		a.updatePauseButton(true)
+		a.refreshTimerSummary()
+		a.app.SendNotification(fyne.NewNotification(
+			"Timer Paused",
+			fmt.Sprintf("%s was paused at %s while the computer was asleep or locked.", project, from.Format("15:04")),
+		))
+	case database.SleepPolicyAsk:
+		if a.awayPrompting {
+			a.mu.Unlock()
+			return
+		}
+		a.awayPrompting = true
+		a.lastActivity = from
+		a.mu.Unlock()
+		a.showAwayDialog(from, to)
+	default:
+		a.mu.Unlock()
+	}
+}
@@ -0,0 +1,135 @@
+// Package state reads and writes the small running-timer snapshot that lets
+// shell prompts and status bars show TrackYou's state without opening the
+// database.
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Suffix replaces the database file's extension in the name of its state
+// file, e.g. tasks.db keeps its state in tasks.state.json.
+const Suffix = ".state.json"
+
+// State is the snapshot written whenever the timer starts or stops, the
+// day's completed total changes, or the day rolls over.
+type State struct {
+	Running     bool      `json:"running"`
+	Project     string    `json:"project,omitempty"`
+	Description string    `json:"description,omitempty"`
+	StartTime   time.Time `json:"start_time,omitempty"`
+
+	// A task that was paused records its earlier active time in
+	// ActiveSeconds (of which ActiveTodaySeconds fell on Day) and the start
+	// of its current active interval in ResumedAt.
+	Paused             bool      `json:"paused,omitempty"`
+	ResumedAt          time.Time `json:"resumed_at,omitempty"`
+	ActiveSeconds      int64     `json:"active_seconds,omitempty"`
+	ActiveTodaySeconds int64     `json:"active_today_seconds,omitempty"`
+
+	// Day is the local YYYY-MM-DD date CompletedTodaySeconds belongs to.
+	Day                   string  `json:"day"`
+	CompletedTodaySeconds int64   `json:"completed_today_seconds"`
+	WorkdayGoalHours      float64 `json:"workday_goal_hours"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PathForDB returns the path of dbPath's state file, next to it. Databases
+// sharing a directory each get their own.
+func PathForDB(dbPath string) string {
+	return strings.TrimSuffix(dbPath, filepath.Ext(dbPath)) + Suffix
+}
+
+// Read loads the state file. A missing file yields an idle, zero state.
+func Read(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Write atomically replaces the state file so readers never see a partial
+// document.
+func Write(path string, s *State) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// DayKey formats t's local date the way State.Day stores it.
+func DayKey(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// Elapsed returns the running task's active time at now.
+func (s *State) Elapsed(now time.Time) time.Duration {
+	if !s.Running {
+		return 0
+	}
+	total := time.Duration(s.ActiveSeconds) * time.Second
+	if s.Paused {
+		return total
+	}
+	if d := now.Sub(s.activeSince()); d > 0 {
+		total += d
+	}
+	return total
+}
+
+// activeSince returns when the running task's current active interval
+// began.
+func (s *State) activeSince() time.Time {
+	if !s.ResumedAt.IsZero() {
+		return s.ResumedAt
+	}
+	return s.StartTime
+}
+
+// TotalToday returns the completed total for now's day plus the part of the
+// running task's active time that falls on it. A snapshot from an earlier
+// day contributes no completed time.
+func (s *State) TotalToday(now time.Time) time.Duration {
+	var total time.Duration
+	if s.Day == DayKey(now) {
+		total = time.Duration(s.CompletedTodaySeconds+s.ActiveTodaySeconds) * time.Second
+	}
+	if s.Running && !s.Paused {
+		start := s.activeSince()
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if start.Before(midnight) {
+			start = midnight
+		}
+		if d := now.Sub(start); d > 0 {
+			total += d
+		}
+	}
+	return total
+}
@@ -0,0 +1,147 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadMissingFileIsIdle(t *testing.T) {
+	s, err := Read(filepath.Join(t.TempDir(), "tasks"+Suffix))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Running || s.TotalToday(time.Now()) != 0 {
+		t.Fatalf("expected idle zero state, got %+v", s)
+	}
+}
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks"+Suffix)
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	want := &State{
+		Running:               true,
+		Project:               "Alpha",
+		Description:           "write",
+		StartTime:             start,
+		Day:                   "2024-03-04",
+		CompletedTodaySeconds: 3600,
+		WorkdayGoalHours:      7.5,
+	}
+	if err := Write(path, want); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	got, err := Read(path)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if got.Project != "Alpha" || !got.StartTime.Equal(start) || got.CompletedTodaySeconds != 3600 || got.WorkdayGoalHours != 7.5 {
+		t.Fatalf("unexpected state: %+v", got)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected no leftover temp files, got %d entries", len(entries))
+	}
+}
+
+func TestPathForDB(t *testing.T) {
+	got := PathForDB(filepath.Join("cfg", "TrackYou", "tasks.db"))
+	if want := filepath.Join("cfg", "TrackYou", "tasks.state.json"); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	if got, want := PathForDB("work"), "work"+Suffix; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestPathForDB_SharedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	personal, employer := PathForDB(filepath.Join(dir, "personal.db")), PathForDB(filepath.Join(dir, "employer.db"))
+	if personal == employer {
+		t.Fatalf("expected separate state files, got %s for both", personal)
+	}
+	if err := Write(personal, &State{Running: true, Project: "Hobby"}); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	if err := Write(employer, &State{}); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	if s, err := Read(personal); err != nil || !s.Running || s.Project != "Hobby" {
+		t.Errorf("expected the personal database's running task kept, got %+v (err %v)", s, err)
+	}
+}
+
+func TestTotalToday(t *testing.T) {
+	loc := time.FixedZone("test", 2*60*60)
+	now := time.Date(2024, 3, 4, 10, 0, 0, 0, loc)
+
+	tests := []struct {
+		name     string
+		state    State
+		expected time.Duration
+	}{
+		{
+			name:     "idle same day",
+			state:    State{Day: "2024-03-04", CompletedTodaySeconds: 3600},
+			expected: time.Hour,
+		},
+		{
+			name:     "stale day ignored",
+			state:    State{Day: "2024-03-03", CompletedTodaySeconds: 3600},
+			expected: 0,
+		},
+		{
+			name:     "running adds elapsed",
+			state:    State{Running: true, StartTime: now.Add(-30 * time.Minute), Day: "2024-03-04", CompletedTodaySeconds: 3600},
+			expected: 90 * time.Minute,
+		},
+		{
+			name:     "running since yesterday clipped at midnight",
+			state:    State{Running: true, StartTime: now.Add(-12 * time.Hour), Day: "2024-03-03", CompletedTodaySeconds: 3600},
+			expected: 10 * time.Hour,
+		},
+		{
+			name:     "paused adds earlier active time only",
+			state:    State{Running: true, Paused: true, StartTime: now.Add(-2 * time.Hour), ActiveSeconds: 2400, ActiveTodaySeconds: 1800, Day: "2024-03-04", CompletedTodaySeconds: 3600},
+			expected: 90 * time.Minute,
+		},
+		{
+			name:     "resumed adds current interval",
+			state:    State{Running: true, StartTime: now.Add(-2 * time.Hour), ResumedAt: now.Add(-10 * time.Minute), ActiveSeconds: 1800, ActiveTodaySeconds: 1800, Day: "2024-03-04"},
+			expected: 40 * time.Minute,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.state.TotalToday(now); got != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestElapsed(t *testing.T) {
+	now := time.Now()
+	s := State{Running: true, StartTime: now.Add(-5 * time.Minute)}
+	if got := s.Elapsed(now); got != 5*time.Minute {
+		t.Errorf("expected 5m, got %v", got)
+	}
+	s.StartTime = now.Add(time.Minute)
+	if got := s.Elapsed(now); got != 0 {
+		t.Errorf("expected 0 for a start in the future, got %v", got)
+	}
+	s = State{Running: true, StartTime: now.Add(-time.Hour), ResumedAt: now.Add(-5 * time.Minute), ActiveSeconds: 600}
+	if got := s.Elapsed(now); got != 15*time.Minute {
+		t.Errorf("expected earlier active time plus the current interval, got %v", got)
+	}
+	s.Paused = true
+	if got := s.Elapsed(now); got != 10*time.Minute {
+		t.Errorf("expected elapsed to stop while paused, got %v", got)
+	}
+	if got := (&State{}).Elapsed(now); got != 0 {
+		t.Errorf("expected 0 when idle, got %v", got)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// summaryPeriodKinds are the periods offered by the Summary tab's picker, in
+// display order.
+var summaryPeriodKinds = []models.PeriodKind{
+	models.PeriodDay,
+	models.PeriodWeek,
+	models.PeriodMonth,
+	models.PeriodQuarter,
+	models.PeriodYear,
+	models.PeriodCustom,
+}
+
+// summaryComparisons are the comparisons offered by the Summary tab, in
+// display order.
+var summaryComparisons = []models.Comparison{
+	models.CompareNone,
+	models.ComparePrevious,
+	models.CompareLastYear,
+}
+
+// summaryPeriod returns the period shown in the Summary tab. Calendar periods
+// are kept relative to now so that "this week" moves on when the next one
+// starts while the app stays open.
+func (a *App) summaryPeriod(now time.Time) models.Period {
+	if a.summaryKind == models.PeriodCustom {
+		return a.customPeriod.Shift(a.summaryOffset)
+	}
+	a.mu.RLock()
+	weekStart := a.prefs.WeekStart
+	a.mu.RUnlock()
+	return models.PeriodOf(a.summaryKind, now, weekStart).Shift(a.summaryOffset)
+}
+
+// makePeriodPicker returns the Summary tab's period selector with
+// previous/next navigation and the choice of period to compare with.
+func (a *App) makePeriodPicker() fyne.CanvasObject {
+	labels := make([]string, len(summaryPeriodKinds))
+	for i, kind := range summaryPeriodKinds {
+		labels[i] = kind.String()
+	}
+	a.periodSelect = widget.NewSelect(labels, func(label string) {
+		for _, kind := range summaryPeriodKinds {
+			if kind.String() == label {
+				a.selectPeriodKind(kind)
+				return
+			}
+		}
+	})
+	a.summaryKind = models.PeriodWeek
+	a.periodSelect.SetSelected(a.summaryKind.String())
+
+	previous := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		a.stepSummaryPeriod(-1)
+	})
+	next := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() {
+		a.stepSummaryPeriod(1)
+	})
+	current := widget.NewButton("Today", func() {
+		a.summaryOffset = 0
+		a.refreshWeeklyChart()
+	})
+
+	comparisons := make([]string, len(summaryComparisons))
+	for i, c := range summaryComparisons {
+		comparisons[i] = c.String()
+	}
+	a.compareSelect = widget.NewSelect(comparisons, func(label string) {
+		for _, c := range summaryComparisons {
+			if c.String() == label && c != a.summaryCompare {
+				a.summaryCompare = c
+				a.refreshWeeklyChart()
+			}
+		}
+	})
+	a.compareSelect.SetSelected(a.summaryCompare.String())
+
+	return container.NewHBox(previous, a.periodSelect, next, current, layout.NewSpacer(), a.compareSelect, a.roundedCheck)
+}
+
+// selectPeriodKind switches the Summary tab to the current period of kind,
+// asking for the range when kind is PeriodCustom.
+func (a *App) selectPeriodKind(kind models.PeriodKind) {
+	if kind == a.summaryKind {
+		return
+	}
+	if kind == models.PeriodCustom {
+		a.showCustomPeriodDialog()
+		return
+	}
+	a.summaryKind, a.summaryOffset = kind, 0
+	a.refreshWeeklyChart()
+}
+
+// stepSummaryPeriod moves the Summary tab n periods forward, or back for
+// negative n.
+func (a *App) stepSummaryPeriod(n int) {
+	a.summaryOffset += n
+	a.refreshWeeklyChart()
+}
+
+// showCustomPeriodDialog asks for the first and last day of a custom
+// Summary period, starting from the period currently shown.
+func (a *App) showCustomPeriodDialog() {
+	shown := a.summaryPeriod(time.Now())
+	fromEntry := widget.NewEntry()
+	fromEntry.SetPlaceHolder("YYYY-MM-DD")
+	fromEntry.SetText(shown.Start.Format(time.DateOnly))
+	toEntry := widget.NewEntry()
+	toEntry.SetPlaceHolder("YYYY-MM-DD")
+	toEntry.SetText(shown.End.AddDate(0, 0, -1).Format(time.DateOnly))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("From", fromEntry),
+		widget.NewFormItem("To", toEntry),
+	}
+	dialog.ShowForm("Custom Period", "Show", "Cancel", items, func(confirmed bool) {
+		var err error
+		if confirmed {
+			err = a.setCustomPeriod(fromEntry.Text, toEntry.Text)
+		}
+		if err != nil {
+			a.showDialogError(err)
+		}
+		// Keep the picker on the period still shown.
+		a.periodSelect.SetSelected(a.summaryKind.String())
+	}, a.window)
+}
+
+// setCustomPeriod shows the days from first through last, given as
+// YYYY-MM-DD, in the Summary tab.
+func (a *App) setCustomPeriod(first, last string) error {
+	from, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(first), time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid first day %q, expected YYYY-MM-DD", first)
+	}
+	to, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(last), time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid last day %q, expected YYYY-MM-DD", last)
+	}
+	period, err := models.CustomPeriod(from, to)
+	if err != nil {
+		return err
+	}
+	a.customPeriod = period
+	a.summaryKind, a.summaryOffset = models.PeriodCustom, 0
+	if a.periodSelect != nil {
+		a.periodSelect.SetSelected(models.PeriodCustom.String())
+	}
+	a.refreshWeeklyChart()
+	return nil
+}
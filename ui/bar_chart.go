@@ -0,0 +1,404 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// chartPalette colors the projects of a chart in order. The shades are
+// mid-tones that stay readable on both the light and the dark theme.
+var chartPalette = []color.NRGBA{
+	{R: 0x42, G: 0x85, B: 0xF4, A: 0xFF}, // blue
+	{R: 0xEF, G: 0x6C, B: 0x00, A: 0xFF}, // orange
+	{R: 0x43, G: 0xA0, B: 0x47, A: 0xFF}, // green
+	{R: 0xE5, G: 0x39, B: 0x35, A: 0xFF}, // red
+	{R: 0x8E, G: 0x24, B: 0xAA, A: 0xFF}, // purple
+	{R: 0x00, G: 0xAC, B: 0xC1, A: 0xFF}, // cyan
+	{R: 0xF9, G: 0xA8, B: 0x25, A: 0xFF}, // amber
+	{R: 0xD8, G: 0x1B, B: 0x60, A: 0xFF}, // pink
+	{R: 0x6D, G: 0x4C, B: 0x41, A: 0xFF}, // brown
+	{R: 0x54, G: 0x6E, B: 0x7A, A: 0xFF}, // blue grey
+}
+
+// projectColor returns the color of the i-th project of a summary.
+func projectColor(i int) color.Color {
+	return chartPalette[i%len(chartPalette)]
+}
+
+const (
+	// chartPlotHeight is the minimum height of the bars' area.
+	chartPlotHeight = 160
+	// chartBarFill is the fraction of its bucket's width a bar fills.
+	chartBarFill = 0.6
+)
+
+// StackedBarChart draws one bar per bucket of a summary, stacked by project,
+// with each bucket's target as a reference line. Hovering over or tapping a
+// bar shows its exact values.
+type StackedBarChart struct {
+	widget.BaseWidget
+
+	summary   models.Summary
+	top, step time.Duration // value at the top of the axis and between gridlines
+	selected  int           // bar whose values are shown, -1 for none
+}
+
+var (
+	_ desktop.Hoverable = (*StackedBarChart)(nil)
+	_ fyne.Tappable     = (*StackedBarChart)(nil)
+)
+
+// NewStackedBarChart creates a chart of summary's projects per bucket.
+func NewStackedBarChart(summary models.Summary) *StackedBarChart {
+	c := &StackedBarChart{summary: summary, selected: -1}
+	var highest time.Duration
+	for i := range summary.Buckets {
+		highest = max(highest, bucketTotal(summary, i))
+		if i < len(summary.Targets) {
+			highest = max(highest, summary.Targets[i])
+		}
+	}
+	c.top, c.step = chartScale(highest)
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// CreateRenderer implements fyne.Widget.
+func (c *StackedBarChart) CreateRenderer() fyne.WidgetRenderer {
+	r := &barChartRenderer{chart: c}
+	r.build()
+	return r
+}
+
+// Tapped shows the values of the tapped bar, for devices without a pointer
+// to hover with.
+func (c *StackedBarChart) Tapped(e *fyne.PointEvent) {
+	c.setSelected(c.barAt(e.Position))
+}
+
+// MouseIn implements desktop.Hoverable.
+func (c *StackedBarChart) MouseIn(e *desktop.MouseEvent) {
+	c.setSelected(c.barAt(e.Position))
+}
+
+// MouseMoved implements desktop.Hoverable.
+func (c *StackedBarChart) MouseMoved(e *desktop.MouseEvent) {
+	c.setSelected(c.barAt(e.Position))
+}
+
+// MouseOut implements desktop.Hoverable.
+func (c *StackedBarChart) MouseOut() {
+	c.setSelected(-1)
+}
+
+func (c *StackedBarChart) setSelected(i int) {
+	if i == c.selected {
+		return
+	}
+	c.selected = i
+	c.Refresh()
+}
+
+// barAt returns the index of the bar whose column contains pos, or -1.
+func (c *StackedBarChart) barAt(pos fyne.Position) int {
+	origin, plot := c.plotArea(c.Size())
+	n := len(c.summary.Buckets)
+	x := pos.X - origin.X
+	if n == 0 || x < 0 || x >= plot.Width {
+		return -1
+	}
+	return min(int(x/(plot.Width/float32(n))), n-1)
+}
+
+// plotArea returns where the bars are drawn within size, leaving room for
+// the value axis on the left and the bucket labels below.
+func (c *StackedBarChart) plotArea(size fyne.Size) (fyne.Position, fyne.Size) {
+	th := c.Theme()
+	textSize, pad := th.Size(theme.SizeNameCaptionText), th.Size(theme.SizeNameInnerPadding)
+	label := fyne.MeasureText(formatAxisDuration(c.top), textSize, fyne.TextStyle{})
+	left := label.Width + pad
+	top := label.Height / 2 // room for the top gridline's label
+	bottom := label.Height + pad/2
+	return fyne.NewPos(left, top), fyne.NewSize(max(size.Width-left, 0), max(size.Height-top-bottom, 0))
+}
+
+// barChartRenderer draws a StackedBarChart. It rebuilds its objects on every
+// refresh, which picks up theme changes and the selected bar.
+type barChartRenderer struct {
+	chart   *StackedBarChart
+	objects []fyne.CanvasObject
+
+	highlight    *canvas.Rectangle
+	gridlines    []*canvas.Line
+	axisLabels   []*canvas.Text
+	segments     [][]*canvas.Rectangle // per bucket, per project
+	targets      []*canvas.Line
+	bucketLabels []*canvas.Text
+
+	tooltip         *canvas.Rectangle
+	tooltipSwatches []*canvas.Rectangle // nil for lines without a project
+	tooltipTexts    []*canvas.Text
+}
+
+func (r *barChartRenderer) build() {
+	c := r.chart
+	th := c.Theme()
+	variant := fyne.CurrentApp().Settings().ThemeVariant()
+	foreground := th.Color(theme.ColorNameForeground, variant)
+	textSize := th.Size(theme.SizeNameCaptionText)
+
+	r.objects = nil
+	r.highlight = canvas.NewRectangle(th.Color(theme.ColorNameHover, variant))
+	r.highlight.Hidden = c.selected < 0
+	r.objects = append(r.objects, r.highlight)
+
+	r.gridlines, r.axisLabels = nil, nil
+	for v := time.Duration(0); v <= c.top; v += c.step {
+		line := canvas.NewLine(th.Color(theme.ColorNameSeparator, variant))
+		label := canvas.NewText(formatAxisDuration(v), foreground)
+		label.TextSize = textSize
+		label.Alignment = fyne.TextAlignTrailing
+		r.gridlines = append(r.gridlines, line)
+		r.axisLabels = append(r.axisLabels, label)
+		r.objects = append(r.objects, line, label)
+	}
+
+	r.segments, r.targets, r.bucketLabels = nil, nil, nil
+	for i, bucket := range c.summary.Buckets {
+		var bar []*canvas.Rectangle
+		for j := range c.summary.Projects {
+			segment := canvas.NewRectangle(projectColor(j))
+			bar = append(bar, segment)
+			r.objects = append(r.objects, segment)
+		}
+		r.segments = append(r.segments, bar)
+
+		if i < len(c.summary.Targets) {
+			target := canvas.NewLine(foreground)
+			target.StrokeWidth = 2
+			r.targets = append(r.targets, target)
+			r.objects = append(r.objects, target)
+		}
+
+		label := canvas.NewText(bucket.Label(c.summary.Size), foreground)
+		label.TextSize = textSize
+		label.Alignment = fyne.TextAlignCenter
+		r.bucketLabels = append(r.bucketLabels, label)
+		r.objects = append(r.objects, label)
+	}
+
+	r.tooltip, r.tooltipSwatches, r.tooltipTexts = nil, nil, nil
+	if c.selected < 0 || c.selected >= len(c.summary.Buckets) {
+		return
+	}
+	r.tooltip = canvas.NewRectangle(th.Color(theme.ColorNameOverlayBackground, variant))
+	r.tooltip.StrokeColor = th.Color(theme.ColorNameSeparator, variant)
+	r.tooltip.StrokeWidth = 1
+	r.tooltip.CornerRadius = th.Size(theme.SizeNameInputRadius)
+	r.objects = append(r.objects, r.tooltip)
+	for _, line := range barTooltip(c.summary, c.selected) {
+		var swatch *canvas.Rectangle
+		if line.color != nil {
+			swatch = canvas.NewRectangle(line.color)
+			r.objects = append(r.objects, swatch)
+		}
+		text := canvas.NewText(line.text, foreground)
+		text.TextSize = textSize
+		text.TextStyle = fyne.TextStyle{Bold: line.bold}
+		r.tooltipSwatches = append(r.tooltipSwatches, swatch)
+		r.tooltipTexts = append(r.tooltipTexts, text)
+		r.objects = append(r.objects, text)
+	}
+}
+
+// Layout implements fyne.WidgetRenderer.
+func (r *barChartRenderer) Layout(size fyne.Size) {
+	c := r.chart
+	origin, plot := c.plotArea(size)
+	pad := c.Theme().Size(theme.SizeNameInnerPadding)
+	y := func(v time.Duration) float32 {
+		return origin.Y + plot.Height*(1-float32(v)/float32(c.top))
+	}
+
+	for i, line := range r.gridlines {
+		v := time.Duration(i) * c.step
+		line.Position1 = fyne.NewPos(origin.X, y(v))
+		line.Position2 = fyne.NewPos(origin.X+plot.Width, y(v))
+		label := r.axisLabels[i]
+		labelSize := label.MinSize()
+		label.Resize(fyne.NewSize(origin.X-pad/2, labelSize.Height))
+		label.Move(fyne.NewPos(0, y(v)-labelSize.Height/2))
+	}
+
+	n := len(c.summary.Buckets)
+	if n == 0 {
+		return
+	}
+	slot := plot.Width / float32(n)
+	barWidth := slot * chartBarFill
+	// Only every labelStep-th bucket is labelled when their labels would
+	// overlap.
+	labelStep := 1
+	for _, label := range r.bucketLabels {
+		labelStep = max(labelStep, int(math.Ceil(float64((label.MinSize().Width+pad)/slot))))
+	}
+	for i := range c.summary.Buckets {
+		left := origin.X + slot*float32(i)
+		barLeft := left + (slot-barWidth)/2
+		var stacked time.Duration
+		for j, segment := range r.segments[i] {
+			d := c.summary.Projects[j].Buckets[i]
+			segment.Resize(fyne.NewSize(barWidth, y(stacked)-y(stacked+d)))
+			segment.Move(fyne.NewPos(barLeft, y(stacked+d)))
+			stacked += d
+		}
+		if i < len(r.targets) {
+			target := r.targets[i]
+			target.Hidden = c.summary.Targets[i] <= 0
+			target.Position1 = fyne.NewPos(left+slot*0.1, y(c.summary.Targets[i]))
+			target.Position2 = fyne.NewPos(left+slot*0.9, y(c.summary.Targets[i]))
+		}
+		label := r.bucketLabels[i]
+		label.Hidden = i%labelStep != 0
+		label.Resize(fyne.NewSize(slot*float32(labelStep), label.MinSize().Height))
+		label.Move(fyne.NewPos(left+slot/2-slot*float32(labelStep)/2, origin.Y+plot.Height+pad/2))
+	}
+
+	if c.selected < 0 || c.selected >= n {
+		return
+	}
+	left := origin.X + slot*float32(c.selected)
+	r.highlight.Resize(fyne.NewSize(slot, plot.Height))
+	r.highlight.Move(fyne.NewPos(left, origin.Y))
+	r.layoutTooltip(size, left, slot)
+}
+
+// layoutTooltip places the tooltip beside the selected bar, on its right
+// unless it would leave the chart.
+func (r *barChartRenderer) layoutTooltip(size fyne.Size, left, slot float32) {
+	if r.tooltip == nil {
+		return
+	}
+	pad := r.chart.Theme().Size(theme.SizeNameInnerPadding)
+	var lineHeight, textWidth float32
+	for _, text := range r.tooltipTexts {
+		textSize := text.MinSize()
+		lineHeight = max(lineHeight, textSize.Height)
+		textWidth = max(textWidth, textSize.Width)
+	}
+	swatchSize := lineHeight * 0.6
+	width := pad*2 + swatchSize + pad/2 + textWidth
+	height := pad*2 + lineHeight*float32(len(r.tooltipTexts))
+
+	x := left + slot
+	if x+width > size.Width {
+		x = max(left-width, 0)
+	}
+	y := min(max(size.Height-height, 0), pad)
+	r.tooltip.Resize(fyne.NewSize(width, height))
+	r.tooltip.Move(fyne.NewPos(x, y))
+	for i, text := range r.tooltipTexts {
+		lineY := y + pad + lineHeight*float32(i)
+		if swatch := r.tooltipSwatches[i]; swatch != nil {
+			swatch.Resize(fyne.NewSquareSize(swatchSize))
+			swatch.Move(fyne.NewPos(x+pad, lineY+(lineHeight-swatchSize)/2))
+		}
+		text.Move(fyne.NewPos(x+pad+swatchSize+pad/2, lineY))
+	}
+}
+
+// MinSize implements fyne.WidgetRenderer.
+func (r *barChartRenderer) MinSize() fyne.Size {
+	origin, plot := r.chart.plotArea(fyne.NewSize(0, chartPlotHeight))
+	// The margins around the plot come on top of its height.
+	height := 2*chartPlotHeight - plot.Height
+	return fyne.NewSize(origin.X+float32(len(r.chart.summary.Buckets))*8, height)
+}
+
+// Refresh implements fyne.WidgetRenderer.
+func (r *barChartRenderer) Refresh() {
+	r.build()
+	r.Layout(r.chart.Size())
+	canvas.Refresh(r.chart)
+}
+
+// Objects implements fyne.WidgetRenderer.
+func (r *barChartRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+// Destroy implements fyne.WidgetRenderer.
+func (r *barChartRenderer) Destroy() {}
+
+// chartLine is a line of a bar's tooltip, with the color of the project it
+// stands for.
+type chartLine struct {
+	text  string
+	color color.Color // nil for lines without a project
+	bold  bool
+}
+
+// barTooltip lists the exact values of the i-th bar of summary: the time of
+// each project tracked in the bucket, its total and its target.
+func barTooltip(summary models.Summary, i int) []chartLine {
+	lines := []chartLine{{text: summary.Buckets[i].Label(summary.Size), bold: true}}
+	for j, p := range summary.Projects {
+		if d := p.Buckets[i]; d > 0 {
+			lines = append(lines, chartLine{text: fmt.Sprintf("%s: %s", p.ProjectName, formatWeeklyDuration(d)), color: projectColor(j)})
+		}
+	}
+	lines = append(lines, chartLine{text: "Total: " + formatWeeklyDuration(bucketTotal(summary, i))})
+	if i < len(summary.Targets) {
+		lines = append(lines, chartLine{text: "Target: " + formatWeeklyDuration(summary.Targets[i])})
+	}
+	return lines
+}
+
+// bucketTotal returns the time tracked on all projects in the i-th bucket.
+func bucketTotal(summary models.Summary, i int) time.Duration {
+	var total time.Duration
+	for _, p := range summary.Projects {
+		total += p.Buckets[i]
+	}
+	return total
+}
+
+// chartScale returns the value at the top of a chart's axis and the step
+// between its gridlines for values up to highest, with about four steps of
+// a quarter or half hour or 1, 2 or 5 × 10ⁿ hours.
+func chartScale(highest time.Duration) (top, step time.Duration) {
+	step = 15 * time.Minute
+	for _, next := range []time.Duration{30 * time.Minute, time.Hour, 2 * time.Hour, 5 * time.Hour} {
+		if 4*step >= highest {
+			break
+		}
+		step = next
+	}
+	for scale := time.Duration(10); 4*step < highest; scale *= 10 {
+		for _, next := range []time.Duration{1, 2, 5} {
+			if step = next * scale * time.Hour; 4*step >= highest {
+				break
+			}
+		}
+	}
+	steps := max((highest+step-1)/step, 1)
+	return steps * step, step
+}
+
+// formatAxisDuration labels a gridline, e.g. "4h" or "30m".
+func formatAxisDuration(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return formatWeeklyDuration(d)
+}
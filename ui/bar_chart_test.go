@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+)
+
+func TestChartScale(t *testing.T) {
+	tests := []struct {
+		highest   time.Duration
+		top, step time.Duration
+	}{
+		{0, 15 * time.Minute, 15 * time.Minute},
+		{50 * time.Minute, time.Hour, 15 * time.Minute},
+		{7*time.Hour + 20*time.Minute, 8 * time.Hour, 2 * time.Hour},
+		{9 * time.Hour, 10 * time.Hour, 5 * time.Hour},
+		{38 * time.Hour, 40 * time.Hour, 10 * time.Hour},
+		{170 * time.Hour, 200 * time.Hour, 50 * time.Hour},
+	}
+	for _, tt := range tests {
+		if top, step := chartScale(tt.highest); top != tt.top || step != tt.step {
+			t.Errorf("chartScale(%v) = %v, %v; want %v, %v", tt.highest, top, step, tt.top, tt.step)
+		}
+	}
+}
+
+func chartSummary() models.Summary {
+	start := time.Date(2024, 8, 12, 0, 0, 0, 0, time.UTC)
+	buckets := models.Buckets(start, start.AddDate(0, 0, 7), models.BucketDay, time.Monday)
+	return models.Summary{
+		Start:   start,
+		End:     start.AddDate(0, 0, 7),
+		Buckets: buckets,
+		Projects: []models.ProjectSeries{
+			{ProjectName: "Alpha", Duration: 5 * time.Hour, Buckets: []time.Duration{3 * time.Hour, 2 * time.Hour, 0, 0, 0, 0, 0}, Percentage: 1},
+			{ProjectName: "Beta", Duration: 90 * time.Minute, Buckets: []time.Duration{0, 90 * time.Minute, 0, 0, 0, 0, 0}, Percentage: 0.3},
+		},
+		Targets: []time.Duration{8 * time.Hour, 8 * time.Hour, 8 * time.Hour, 8 * time.Hour, 6 * time.Hour, 0, 0},
+	}
+}
+
+func TestBarTooltip(t *testing.T) {
+	lines := barTooltip(chartSummary(), 1)
+	want := []string{"Tue 13", "Alpha: 2h 0m", "Beta: 1h 30m", "Total: 3h 30m", "Target: 8h 0m"}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %+v", len(want), lines)
+	}
+	for i, line := range lines {
+		if line.text != want[i] {
+			t.Errorf("line %d = %q, want %q", i, line.text, want[i])
+		}
+	}
+	if lines[1].color != projectColor(0) || lines[2].color != projectColor(1) || lines[3].color != nil {
+		t.Error("expected project lines in their project's color")
+	}
+}
+
+func TestStackedBarChart_Hover(t *testing.T) {
+	for _, variant := range []fyne.ThemeVariant{theme.VariantLight, theme.VariantDark} {
+		app := test.NewApp()
+		app.Settings().SetTheme(NewMaterialTheme(variant))
+
+		chart := NewStackedBarChart(chartSummary())
+		w := test.NewWindow(chart)
+		w.Resize(fyne.NewSize(420, 260))
+
+		origin, plot := chart.plotArea(chart.Size())
+		slot := plot.Width / 7
+		test.MoveMouse(w.Canvas(), fyne.NewPos(origin.X+slot*1.5, origin.Y+plot.Height/2))
+		if chart.selected != 1 {
+			t.Fatalf("expected hovering over Tuesday to select its bar, got %d", chart.selected)
+		}
+		r := test.WidgetRenderer(chart).(*barChartRenderer)
+		if r.tooltip == nil || len(r.tooltipTexts) != 5 {
+			t.Fatalf("expected Tuesday's tooltip to be shown")
+		}
+		if r.tooltipTexts[0].Color != app.Settings().Theme().Color(theme.ColorNameForeground, variant) {
+			t.Errorf("expected the tooltip in the theme's foreground color")
+		}
+		// Alpha's 2h sits at the bottom of the bar, Beta's 1h 30m on top.
+		alpha, beta := r.segments[1][0], r.segments[1][1]
+		if beta.Position().Y+beta.Size().Height != alpha.Position().Y || alpha.Size().Height <= beta.Size().Height {
+			t.Errorf("expected stacked segments, got Alpha %v %v and Beta %v %v", alpha.Position(), alpha.Size(), beta.Position(), beta.Size())
+		}
+
+		test.MoveMouse(w.Canvas(), fyne.NewPos(1, 1))
+		if chart.selected != -1 || r.tooltip != nil {
+			t.Error("expected the tooltip to hide off the bars")
+		}
+		w.Close()
+	}
+}
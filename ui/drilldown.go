@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// tapRow makes a row of the summary clickable, showing a pointer over it.
+type tapRow struct {
+	widget.BaseWidget
+
+	content  fyne.CanvasObject
+	onTapped func()
+}
+
+var (
+	_ fyne.Tappable      = (*tapRow)(nil)
+	_ desktop.Cursorable = (*tapRow)(nil)
+)
+
+func newTapRow(content fyne.CanvasObject, onTapped func()) *tapRow {
+	r := &tapRow{content: content, onTapped: onTapped}
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+// CreateRenderer implements fyne.Widget.
+func (r *tapRow) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(r.content)
+}
+
+// Tapped implements fyne.Tappable.
+func (r *tapRow) Tapped(*fyne.PointEvent) {
+	r.onTapped()
+}
+
+// Cursor implements desktop.Cursorable.
+func (r *tapRow) Cursor() desktop.Cursor {
+	return desktop.PointerCursor
+}
+
+// DescriptionName names a description for display, including the empty one.
+func DescriptionName(description string) string {
+	if description == "" {
+		return "(no description)"
+	}
+	return description
+}
+
+// MakeBreakdownContent lists a project's time by description, largest
+// first, with each row opening that description's entries through
+// onDescription. A project rounding daily totals gets a last line with the
+// time rounding adds or takes off.
+func MakeBreakdownContent(b models.ProjectBreakdown, onDescription func(description string)) fyne.CanvasObject {
+	if len(b.Descriptions) == 0 {
+		lbl := widget.NewLabel(fmt.Sprintf("No time on %s in this period.", b.ProjectName))
+		lbl.Importance = widget.LowImportance
+		lbl.Alignment = fyne.TextAlignCenter
+		return container.NewCenter(lbl)
+	}
+
+	totalLabel := widget.NewLabel("Total")
+	totalLabel.TextStyle = fyne.TextStyle{Bold: true}
+	rows := []fyne.CanvasObject{
+		container.NewBorder(nil, nil, totalLabel, widget.NewLabel(formatWeeklyDuration(b.Duration)), nil),
+	}
+	for i, series := range b.Descriptions {
+		nameLabel := widget.NewLabel(DescriptionName(series.Description))
+		nameLabel.Truncation = fyne.TextTruncateEllipsis
+		entries := "entries"
+		if len(series.Entries) == 1 {
+			entries = "entry"
+		}
+		durLabel := widget.NewLabel(fmt.Sprintf("%s · %d %s", formatWeeklyDuration(series.Duration), len(series.Entries), entries))
+		row := container.NewVBox(
+			container.NewBorder(nil, nil, nil, durLabel, nameLabel),
+			percentageBar(series.Percentage, projectColor(i)),
+		)
+		description := series.Description
+		rows = append(rows, newTapRow(row, func() { onDescription(description) }))
+	}
+	if b.Rounding != 0 {
+		roundingLabel := widget.NewLabel("Rounding per day")
+		roundingLabel.Importance = widget.LowImportance
+		sign := "+"
+		if b.Rounding < 0 {
+			sign = "−"
+		}
+		amountLabel := widget.NewLabel(sign + formatWeeklyDuration(b.Rounding.Abs()))
+		amountLabel.Importance = widget.LowImportance
+		rows = append(rows, container.NewBorder(nil, nil, roundingLabel, amountLabel, nil))
+	}
+	return container.NewVBox(rows...)
+}
+
+// MakeEntriesContent lists the entries of a description, earliest first,
+// with the time each has inside the window and a button to edit it through
+// onEdit.
+func MakeEntriesContent(series models.DescriptionSeries, onEdit func(task *models.Task)) fyne.CanvasObject {
+	rows := make([]fyne.CanvasObject, 0, len(series.Entries))
+	for _, entry := range series.Entries {
+		task := entry.Task
+		whenLabel := widget.NewLabel(formatEntryTime(task))
+		durLabel := widget.NewLabel(formatWeeklyDuration(entry.Duration))
+		if entry.Clipped {
+			durLabel.SetText(fmt.Sprintf("%s of %s", formatWeeklyDuration(entry.Duration), formatWeeklyDuration(task.Duration)))
+		}
+		edit := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() { onEdit(task) })
+		edit.Importance = widget.LowImportance
+		rows = append(rows, container.NewBorder(nil, nil, nil, container.NewHBox(durLabel, edit), whenLabel))
+	}
+	return container.NewVBox(rows...)
+}
+
+// formatEntryTime describes when a task ran, e.g. "Mon, Oct 19 · 09:00–09:50".
+func formatEntryTime(task *models.Task) string {
+	start := task.StartTime.In(time.Local)
+	end := task.EndTime.In(time.Local)
+	if !task.EndTime.After(task.StartTime) {
+		end = task.StartTime.Add(task.Duration).In(time.Local)
+	}
+	layout := "15:04"
+	if y, m, d := end.Date(); y != start.Year() || m != start.Month() || d != start.Day() {
+		layout = "Mon 15:04"
+	}
+	return start.Format("Mon, Jan 2 · 15:04") + "–" + end.Format(layout)
+}
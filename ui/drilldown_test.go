@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestMakeBreakdownContent_Tap(t *testing.T) {
+	test.NewApp()
+	b := models.ProjectBreakdown{
+		ProjectName: "Acme",
+		Duration:    90 * time.Minute,
+		Descriptions: []models.DescriptionSeries{
+			{Description: "API", Duration: time.Hour, Percentage: 1, Entries: make([]models.EntryTime, 2)},
+			{Description: "", Duration: 30 * time.Minute, Percentage: 0.5, Entries: make([]models.EntryTime, 1)},
+		},
+	}
+	var opened []string
+	content := MakeBreakdownContent(b, func(description string) { opened = append(opened, description) }).(*fyne.Container)
+	w := test.NewWindow(content)
+	defer w.Close()
+
+	// The total comes first, then a row per description.
+	if len(content.Objects) != 3 {
+		t.Fatalf("expected a total and 2 rows, got %d objects", len(content.Objects))
+	}
+	for _, obj := range content.Objects[1:] {
+		test.Tap(obj.(*tapRow))
+	}
+	if len(opened) != 2 || opened[0] != "API" || opened[1] != "" {
+		t.Errorf("expected both descriptions opened in order, got %q", opened)
+	}
+}
+
+func TestMakeEntriesContent(t *testing.T) {
+	test.NewApp()
+	start := time.Date(2026, 10, 19, 23, 0, 0, 0, time.Local)
+	task := &models.Task{ProjectName: "Acme", StartTime: start, EndTime: start.Add(2 * time.Hour), Duration: 2 * time.Hour}
+	series := models.DescriptionSeries{Entries: []models.EntryTime{{Task: task, Duration: time.Hour, Clipped: true}}}
+	var edited *models.Task
+	content := MakeEntriesContent(series, func(t *models.Task) { edited = t }).(*fyne.Container)
+
+	row := content.Objects[0].(*fyne.Container)
+	when := row.Objects[0].(*widget.Label)
+	if when.Text != "Mon, Oct 19 · 23:00–Tue 01:00" {
+		t.Errorf("unexpected entry time %q", when.Text)
+	}
+	buttons := row.Objects[1].(*fyne.Container)
+	if dur := buttons.Objects[0].(*widget.Label); dur.Text != "1h 0m of 2h 0m" {
+		t.Errorf("expected the clipped time of the whole entry, got %q", dur.Text)
+	}
+	test.Tap(buttons.Objects[1].(*widget.Button))
+	if edited != task {
+		t.Error("expected the edit button to open the entry")
+	}
+}
+
+func TestMakeBreakdownContent_Rounding(t *testing.T) {
+	test.NewApp()
+	b := models.ProjectBreakdown{
+		ProjectName: "Acme",
+		Duration:    2 * time.Hour,
+		Descriptions: []models.DescriptionSeries{
+			{Description: "API", Duration: 95 * time.Minute, Percentage: 1, Entries: make([]models.EntryTime, 2)},
+		},
+		Rounding: 25 * time.Minute,
+	}
+	content := MakeBreakdownContent(b, func(string) {}).(*fyne.Container)
+	w := test.NewWindow(content)
+	defer w.Close()
+
+	// The rounding follows the descriptions, so the rows add up to the total.
+	if len(content.Objects) != 3 {
+		t.Fatalf("expected a total, a row and the rounding, got %d objects", len(content.Objects))
+	}
+	line := content.Objects[2].(*fyne.Container)
+	var texts []string
+	for _, obj := range line.Objects {
+		texts = append(texts, obj.(*widget.Label).Text)
+	}
+	if !slices.Contains(texts, "Rounding per day") || !slices.Contains(texts, "+25m") {
+		t.Errorf("expected the rounding line to show +25m, got %q", texts)
+	}
+
+	b.Rounding = -5 * time.Minute
+	content = MakeBreakdownContent(b, func(string) {}).(*fyne.Container)
+	line = content.Objects[2].(*fyne.Container)
+	texts = nil
+	for _, obj := range line.Objects {
+		texts = append(texts, obj.(*widget.Label).Text)
+	}
+	if !slices.Contains(texts, "−5m") {
+		t.Errorf("expected rounding down shown as −5m, got %q", texts)
+	}
+}
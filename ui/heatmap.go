@@ -0,0 +1,278 @@
+package ui
+
+import (
+	"image/color"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// heatmapCell is the side of a day's square and heatmapGap the space
+	// between squares.
+	heatmapCell = 12
+	heatmapGap  = 3
+)
+
+// Heatmap draws days as a calendar of squares, one column per week and one
+// row per weekday, shaded by how much of each day's goal was tracked.
+type Heatmap struct {
+	widget.BaseWidget
+
+	// OnTapped is called with a tapped day.
+	OnTapped func(day models.HeatmapDay)
+	// OnHovered is called with the day under the pointer, or with ok false
+	// once the pointer leaves the days.
+	OnHovered func(day models.HeatmapDay, ok bool)
+
+	days    []models.HeatmapDay // from the first day of a week on
+	hovered int                 // index of the day under the pointer, -1 for none
+}
+
+var (
+	_ desktop.Hoverable = (*Heatmap)(nil)
+	_ fyne.Tappable     = (*Heatmap)(nil)
+)
+
+// NewHeatmap creates a heatmap of days, which must start on the first day
+// of a week.
+func NewHeatmap(days []models.HeatmapDay) *Heatmap {
+	h := &Heatmap{days: days, hovered: -1}
+	h.ExtendBaseWidget(h)
+	return h
+}
+
+// CreateRenderer implements fyne.Widget.
+func (h *Heatmap) CreateRenderer() fyne.WidgetRenderer {
+	r := &heatmapRenderer{heatmap: h}
+	r.build()
+	return r
+}
+
+// Tapped implements fyne.Tappable.
+func (h *Heatmap) Tapped(e *fyne.PointEvent) {
+	if i := h.dayAt(e.Position); i >= 0 && h.OnTapped != nil {
+		h.OnTapped(h.days[i])
+	}
+}
+
+// MouseIn implements desktop.Hoverable.
+func (h *Heatmap) MouseIn(e *desktop.MouseEvent) {
+	h.setHovered(h.dayAt(e.Position))
+}
+
+// MouseMoved implements desktop.Hoverable.
+func (h *Heatmap) MouseMoved(e *desktop.MouseEvent) {
+	h.setHovered(h.dayAt(e.Position))
+}
+
+// MouseOut implements desktop.Hoverable.
+func (h *Heatmap) MouseOut() {
+	h.setHovered(-1)
+}
+
+func (h *Heatmap) setHovered(i int) {
+	if i == h.hovered {
+		return
+	}
+	h.hovered = i
+	h.Refresh()
+	if h.OnHovered == nil {
+		return
+	}
+	if i < 0 {
+		h.OnHovered(models.HeatmapDay{}, false)
+		return
+	}
+	h.OnHovered(h.days[i], true)
+}
+
+// dayAt returns the index of the day whose square contains pos, or -1.
+func (h *Heatmap) dayAt(pos fyne.Position) int {
+	origin := h.gridOrigin()
+	x, y := pos.X-origin.X, pos.Y-origin.Y
+	if x < 0 || y < 0 {
+		return -1
+	}
+	column, row := int(x/(heatmapCell+heatmapGap)), int(y/(heatmapCell+heatmapGap))
+	if row >= 7 {
+		return -1
+	}
+	if i := column*7 + row; i < len(h.days) {
+		return i
+	}
+	return -1
+}
+
+// gridOrigin returns the top left corner of the first day's square, below
+// the month labels and right of the weekday labels.
+func (h *Heatmap) gridOrigin() fyne.Position {
+	th := h.Theme()
+	textSize, pad := th.Size(theme.SizeNameCaptionText), th.Size(theme.SizeNameInnerPadding)
+	label := fyne.MeasureText("Wed", textSize, fyne.TextStyle{})
+	return fyne.NewPos(label.Width+pad/2, label.Height+heatmapGap)
+}
+
+// heatColor returns the shade of a day of level from 0 to
+// models.HeatLevels: the separator color for no time, else the success
+// color, more opaque the higher the level.
+func heatColor(th fyne.Theme, variant fyne.ThemeVariant, level int) color.Color {
+	if level <= 0 {
+		return th.Color(theme.ColorNameSeparator, variant)
+	}
+	r, g, b, _ := th.Color(theme.ColorNameSuccess, variant).RGBA()
+	alpha := 0x40 + (0xFF-0x40)*(level-1)/(models.HeatLevels-1)
+	return color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(alpha)}
+}
+
+// heatmapRenderer draws a Heatmap, rebuilding its objects on every refresh
+// to pick up theme changes and the day under the pointer.
+type heatmapRenderer struct {
+	heatmap *Heatmap
+	objects []fyne.CanvasObject
+
+	cells         []*canvas.Rectangle
+	monthLabels   []*canvas.Text
+	monthColumns  []int
+	weekdayLabels []*canvas.Text
+	outline       *canvas.Rectangle
+	legend        []fyne.CanvasObject // "Less", a square per level, "More"
+}
+
+func (r *heatmapRenderer) build() {
+	h := r.heatmap
+	th := h.Theme()
+	variant := fyne.CurrentApp().Settings().ThemeVariant()
+	foreground := th.Color(theme.ColorNameForeground, variant)
+	textSize := th.Size(theme.SizeNameCaptionText)
+	caption := func(text string) *canvas.Text {
+		label := canvas.NewText(text, foreground)
+		label.TextSize = textSize
+		return label
+	}
+
+	r.objects, r.cells = nil, nil
+	for _, day := range h.days {
+		cell := canvas.NewRectangle(heatColor(th, variant, day.Level()))
+		cell.CornerRadius = 2
+		r.cells = append(r.cells, cell)
+		r.objects = append(r.objects, cell)
+	}
+
+	// Each month is named above the first week starting in it, and the
+	// month of the first week only if there is room before the next.
+	r.monthLabels, r.monthColumns = nil, nil
+	for i := 0; i < len(h.days); i += 7 {
+		date := h.days[i].Date
+		if i > 0 && date.Month() == h.days[i-7].Date.Month() {
+			continue
+		}
+		if i == 0 && len(h.days) > 21 && h.days[21].Date.Month() != date.Month() {
+			continue
+		}
+		label := caption(date.Format("Jan"))
+		r.monthLabels = append(r.monthLabels, label)
+		r.monthColumns = append(r.monthColumns, i/7)
+		r.objects = append(r.objects, label)
+	}
+
+	// Every other weekday is named, as on a wall calendar.
+	r.weekdayLabels = nil
+	for row := 1; row < 7 && row < len(h.days); row += 2 {
+		label := caption(h.days[row].Date.Format("Mon"))
+		r.weekdayLabels = append(r.weekdayLabels, label)
+		r.objects = append(r.objects, label)
+	}
+
+	r.outline = canvas.NewRectangle(color.Transparent)
+	r.outline.StrokeColor = foreground
+	r.outline.StrokeWidth = 1
+	r.outline.CornerRadius = 2
+	r.outline.Hidden = h.hovered < 0
+	r.objects = append(r.objects, r.outline)
+
+	r.legend = []fyne.CanvasObject{caption("Less")}
+	for level := 0; level <= models.HeatLevels; level++ {
+		square := canvas.NewRectangle(heatColor(th, variant, level))
+		square.CornerRadius = 2
+		r.legend = append(r.legend, square)
+	}
+	r.legend = append(r.legend, caption("More"))
+	r.objects = append(r.objects, r.legend...)
+}
+
+// cellPosition returns the top left corner of the i-th day's square.
+func (r *heatmapRenderer) cellPosition(origin fyne.Position, i int) fyne.Position {
+	return fyne.NewPos(
+		origin.X+float32(i/7)*(heatmapCell+heatmapGap),
+		origin.Y+float32(i%7)*(heatmapCell+heatmapGap),
+	)
+}
+
+// Layout implements fyne.WidgetRenderer.
+func (r *heatmapRenderer) Layout(fyne.Size) {
+	h := r.heatmap
+	origin := h.gridOrigin()
+	for i, cell := range r.cells {
+		cell.Resize(fyne.NewSquareSize(heatmapCell))
+		cell.Move(r.cellPosition(origin, i))
+	}
+	for i, label := range r.monthLabels {
+		label.Move(fyne.NewPos(origin.X+float32(r.monthColumns[i])*(heatmapCell+heatmapGap), 0))
+	}
+	for i, label := range r.weekdayLabels {
+		row := 1 + 2*i
+		label.Move(fyne.NewPos(0, r.cellPosition(origin, row).Y+(heatmapCell-label.MinSize().Height)/2))
+	}
+	if h.hovered >= 0 && h.hovered < len(r.cells) {
+		r.outline.Resize(fyne.NewSquareSize(heatmapCell))
+		r.outline.Move(r.cellPosition(origin, h.hovered))
+	}
+
+	// The legend sits below the grid on the right.
+	x := r.gridSize(origin).Width
+	y := origin.Y + 7*(heatmapCell+heatmapGap) + heatmapGap
+	for i := len(r.legend) - 1; i >= 0; i-- {
+		item := r.legend[i]
+		size := fyne.NewSquareSize(heatmapCell)
+		if text, ok := item.(*canvas.Text); ok {
+			size = text.MinSize()
+		}
+		x -= size.Width
+		item.Resize(size)
+		item.Move(fyne.NewPos(x, y+(heatmapCell-size.Height)/2))
+		x -= heatmapGap
+	}
+}
+
+// gridSize returns the size of the labels and squares, without the legend.
+func (r *heatmapRenderer) gridSize(origin fyne.Position) fyne.Size {
+	columns := (len(r.heatmap.days) + 6) / 7
+	return fyne.NewSize(origin.X+float32(columns)*(heatmapCell+heatmapGap), origin.Y+7*(heatmapCell+heatmapGap))
+}
+
+// MinSize implements fyne.WidgetRenderer.
+func (r *heatmapRenderer) MinSize() fyne.Size {
+	grid := r.gridSize(r.heatmap.gridOrigin())
+	return fyne.NewSize(grid.Width, grid.Height+heatmapGap+max(heatmapCell, r.legend[0].MinSize().Height))
+}
+
+// Refresh implements fyne.WidgetRenderer.
+func (r *heatmapRenderer) Refresh() {
+	r.build()
+	r.Layout(r.heatmap.Size())
+	canvas.Refresh(r.heatmap)
+}
+
+// Objects implements fyne.WidgetRenderer.
+func (r *heatmapRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+// Destroy implements fyne.WidgetRenderer.
+func (r *heatmapRenderer) Destroy() {}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+)
+
+func heatmapDays() []models.HeatmapDay {
+	first := time.Date(2024, 7, 29, 0, 0, 0, 0, time.UTC) // a Monday
+	days := make([]models.HeatmapDay, 24)
+	for i := range days {
+		days[i] = models.HeatmapDay{Date: first.AddDate(0, 0, i), Tracked: time.Duration(i) * time.Hour, Goal: 8 * time.Hour}
+	}
+	return days
+}
+
+func TestHeatmap_HoverAndTap(t *testing.T) {
+	test.NewApp()
+	heatmap := NewHeatmap(heatmapDays())
+	var hovered, tapped models.HeatmapDay
+	var over bool
+	heatmap.OnHovered = func(day models.HeatmapDay, ok bool) { hovered, over = day, ok }
+	heatmap.OnTapped = func(day models.HeatmapDay) { tapped = day }
+	w := test.NewWindow(heatmap)
+	defer w.Close()
+
+	// Wednesday of the second week is the second column's third row.
+	origin := heatmap.gridOrigin()
+	cell := fyne.NewPos(origin.X+(heatmapCell+heatmapGap)+heatmapCell/2, origin.Y+2*(heatmapCell+heatmapGap)+heatmapCell/2)
+	if i := heatmap.dayAt(cell); i != 9 {
+		t.Fatalf("expected day 9 under the pointer, got %d", i)
+	}
+	test.MoveMouse(w.Canvas(), heatmap.Position().Add(cell))
+	if !over || !hovered.Date.Equal(time.Date(2024, 8, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected Aug 7 to be hovered, got %v (%v)", hovered.Date, over)
+	}
+	test.TapCanvas(w.Canvas(), heatmap.Position().Add(cell))
+	if !tapped.Date.Equal(hovered.Date) {
+		t.Errorf("expected Aug 7 to be tapped, got %v", tapped.Date)
+	}
+
+	// The last week has only three days.
+	if i := heatmap.dayAt(fyne.NewPos(origin.X+3*(heatmapCell+heatmapGap)+1, origin.Y+5*(heatmapCell+heatmapGap)+1)); i != -1 {
+		t.Errorf("expected no day after the last one, got %d", i)
+	}
+	test.MoveMouse(w.Canvas(), fyne.NewPos(0, 0))
+	if over {
+		t.Error("expected leaving the days to clear the hovered day")
+	}
+}
+
+func TestHeatColor(t *testing.T) {
+	for _, variant := range []fyne.ThemeVariant{theme.VariantLight, theme.VariantDark} {
+		th := NewMaterialTheme(variant)
+		if got := heatColor(th, variant, 0); got != th.Color(theme.ColorNameSeparator, variant) {
+			t.Errorf("expected days without time in the separator color, got %v", got)
+		}
+		previous := uint8(0)
+		for level := 1; level <= models.HeatLevels; level++ {
+			shade := heatColor(th, variant, level).(color.NRGBA)
+			if shade.A <= previous {
+				t.Errorf("expected level %d to be darker than the one before", level)
+			}
+			previous = shade.A
+		}
+		if previous != 0xFF {
+			t.Errorf("expected the top level to be opaque, got alpha %d", previous)
+		}
+	}
+}
@@ -2,50 +2,155 @@ package ui
 
 import (
 	"fmt"
+	"image/color"
 	"strings"
 	"time"
 
 	"trackyou/models"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
-// MakeWeeklyChartContent returns a visual breakdown of hours per project.
-// When summaries is empty it returns a centred empty-state label.
-func MakeWeeklyChartContent(summaries []models.WeeklySummary) fyne.CanvasObject {
-	if len(summaries) == 0 {
-		lbl := widget.NewLabel("No tracked time this week.")
+// MakeSummaryChartContent returns a stacked bar chart of hours per project
+// and bucket with its legend, each project's total and its change against
+// the compared period, the days away and the project budgets. Tapping a
+// project's row calls onProject with it, unless onProject is nil. When the
+// summary has none of them it returns a centred empty-state label.
+func MakeSummaryChartContent(summary models.Summary, onProject func(project string)) fyne.CanvasObject {
+	if len(summary.Projects) == 0 && len(summary.Absences) == 0 && len(summary.Deltas) == 0 && len(summary.Budgets) == 0 {
+		lbl := widget.NewLabel("No tracked time in this period.")
 		lbl.Importance = widget.LowImportance
 		lbl.Alignment = fyne.TextAlignCenter
 		return container.NewCenter(lbl)
 	}
 
-	rows := make([]fyne.CanvasObject, 0, len(summaries))
-	for _, s := range summaries {
+	deltas := make(map[string]models.ProjectDelta, len(summary.Deltas))
+	for _, d := range summary.Deltas {
+		deltas[d.ProjectName] = d
+	}
+
+	rows := make([]fyne.CanvasObject, 0, len(summary.Projects)+4)
+	if len(summary.Projects) > 0 {
+		rows = append(rows, NewStackedBarChart(summary), chartLegend(summary.Projects))
+	}
+	if len(summary.Deltas) > 0 {
+		total := models.TotalDelta(summary.Deltas)
+		nameLabel := widget.NewLabel("Total")
+		nameLabel.TextStyle = fyne.TextStyle{Bold: true}
+		rows = append(rows, container.NewBorder(nil, nil, nameLabel, deltaTotal(total), nil))
+	}
+	for i, s := range summary.Projects {
 		nameLabel := widget.NewLabel(s.ProjectName)
 		nameLabel.TextStyle = fyne.TextStyle{Bold: true}
 
-		durLabel := widget.NewLabel(formatWeeklyDuration(s.Duration))
-		durLabel.Alignment = fyne.TextAlignTrailing
-
-		dailyLabel := widget.NewLabel(formatDailyDurations(s.DailyDurations))
-		dailyLabel.Importance = widget.LowImportance
-		dailyLabel.Wrapping = fyne.TextWrapWord
+		durText := formatWeeklyDuration(s.Duration)
+		if pomodoros := models.FormatPomodoros(s.Pomodoros); pomodoros != "" {
+			durText += " · " + pomodoros
+		}
+		var durLabel fyne.CanvasObject = widget.NewLabel(durText)
+		if d, ok := deltas[s.ProjectName]; ok {
+			durLabel = container.NewHBox(deltaLabel(d), durLabel)
+		}
 
 		row := container.NewVBox(
 			container.NewBorder(nil, nil, nameLabel, durLabel, nil),
-			dailyLabel,
+			percentageBar(s.Percentage, projectColor(i)),
 		)
-		rows = append(rows, row)
+		if comparison := models.FormatEstimateComparison(s.Estimated, s.EstimatedActual); comparison != "" {
+			estimateLabel := widget.NewLabel(comparison)
+			estimateLabel.Importance = widget.LowImportance
+			row.Add(estimateLabel)
+		}
+		if onProject == nil {
+			rows = append(rows, row)
+			continue
+		}
+		project := s.ProjectName
+		rows = append(rows, newTapRow(row, func() { onProject(project) }))
+	}
+	// Projects tracked only in the compared period follow, at no time now.
+	for _, d := range summary.Deltas {
+		if d.Current > 0 {
+			continue
+		}
+		nameLabel := widget.NewLabel(d.ProjectName)
+		nameLabel.Importance = widget.LowImportance
+		rows = append(rows, container.NewBorder(nil, nil, nameLabel, deltaTotal(d), nil))
+	}
+	if len(summary.Absences) > 0 {
+		nameLabel := widget.NewLabel("Absences")
+		nameLabel.TextStyle = fyne.TextStyle{Bold: true}
+		absenceLabel := widget.NewLabel(formatAbsenceTotals(summary.Absences))
+		absenceLabel.Importance = widget.LowImportance
+		absenceLabel.Wrapping = fyne.TextWrapWord
+		rows = append(rows, container.NewVBox(nameLabel, absenceLabel))
+	}
+	if len(summary.Budgets) > 0 {
+		nameLabel := widget.NewLabel("Budgets")
+		nameLabel.TextStyle = fyne.TextStyle{Bold: true}
+		rows = append(rows, nameLabel)
+		for _, u := range summary.Budgets {
+			rows = append(rows, budgetRow(u))
+		}
 	}
 
 	return container.NewVBox(rows...)
 }
 
+// budgetRow shows a budget's project and usage above a bar of the share
+// used, warning once the budget is overrun.
+func budgetRow(u models.BudgetUsage) fyne.CanvasObject {
+	usageLabel := widget.NewLabel(models.FormatBudget(u))
+	usageLabel.Importance = widget.LowImportance
+	if u.Remaining() < 0 {
+		usageLabel.Importance = widget.DangerImportance
+	}
+	usageLabel.Wrapping = fyne.TextWrapWord
+	bar := widget.NewProgressBar()
+	bar.SetValue(min(u.Fraction(), 1))
+	return container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel(u.ProjectName), nil, usageLabel),
+		bar,
+	)
+}
+
+// deltaLabel shows a project's change against the compared period, with an
+// arrow pointing up for more time and down for less.
+func deltaLabel(d models.ProjectDelta) *widget.Label {
+	label := widget.NewLabel(models.FormatDelta(d))
+	switch {
+	case d.Change() > 0:
+		label.Importance = widget.SuccessImportance
+	case d.Change() < 0:
+		label.Importance = widget.WarningImportance
+	default:
+		label.Importance = widget.LowImportance
+	}
+	return label
+}
+
+// deltaTotal shows the current time of d followed by its change.
+func deltaTotal(d models.ProjectDelta) fyne.CanvasObject {
+	return container.NewHBox(deltaLabel(d), widget.NewLabel(formatWeeklyDuration(d.Current)))
+}
+
+// formatAbsenceTotals lists the days taken of each absence type, e.g.
+// "Vacation: 3 days  |  Sick leave: 0.5 days".
+func formatAbsenceTotals(totals []models.AbsenceTotal) string {
+	parts := make([]string, len(totals))
+	for i, total := range totals {
+		parts[i] = fmt.Sprintf("%s: %s", models.AbsenceLabel(total.Type), models.FormatAbsenceDays(total.Days))
+	}
+	return strings.Join(parts, "  |  ")
+}
+
 // formatWeeklyDuration formats a duration as "Xh Ym" or "Ym" for display in
-// the weekly chart.
+// the summary chart.
 func formatWeeklyDuration(d time.Duration) string {
 	d = d.Round(time.Minute)
 	h := int(d.Hours())
@@ -56,17 +161,37 @@ func formatWeeklyDuration(d time.Duration) string {
 	return fmt.Sprintf("%dm", m)
 }
 
-func formatDailyDurations(daily [7]time.Duration) string {
-	labels := [7]string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
-	firstLineParts := make([]string, 0, 4)
-	secondLineParts := make([]string, 0, 3)
-	for i := range daily {
-		part := fmt.Sprintf("%s: %s", labels[i], formatWeeklyDuration(daily[i]))
-		if i < 4 {
-			firstLineParts = append(firstLineParts, part)
-			continue
-		}
-		secondLineParts = append(secondLineParts, part)
+// chartLegend names the color of each project in the chart.
+func chartLegend(projects []models.ProjectSeries) fyne.CanvasObject {
+	items := make([]fyne.CanvasObject, len(projects))
+	for i, p := range projects {
+		swatch := canvas.NewRectangle(projectColor(i))
+		swatch.SetMinSize(fyne.NewSquareSize(theme.Size(theme.SizeNameCaptionText)))
+		items[i] = container.NewHBox(container.NewCenter(swatch), widget.NewLabel(p.ProjectName))
+	}
+	return container.New(layout.NewRowWrapLayout(), items...)
+}
+
+// percentageBarHeight is the thickness of a project's percentage bar.
+const percentageBarHeight = 6
+
+// percentageBar draws a bar across fraction of the available width.
+func percentageBar(fraction float64, c color.Color) fyne.CanvasObject {
+	bar := canvas.NewRectangle(c)
+	bar.CornerRadius = percentageBarHeight / 2
+	return container.New(fractionLayout(fraction), bar)
+}
+
+// fractionLayout sizes its objects to a fraction of the container's width.
+type fractionLayout float64
+
+func (l fractionLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	for _, o := range objects {
+		o.Resize(fyne.NewSize(size.Width*float32(l), size.Height))
+		o.Move(fyne.NewPos(0, 0))
 	}
-	return strings.Join(firstLineParts, "  |  ") + "\n" + strings.Join(secondLineParts, "  |  ")
+}
+
+func (l fractionLayout) MinSize([]fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(0, percentageBarHeight)
 }
@@ -3,6 +3,11 @@ package ui
 import (
 	"testing"
 	"time"
+
+	"trackyou/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
 )
 
 func TestFormatWeeklyDuration(t *testing.T) {
@@ -14,39 +19,43 @@ func TestFormatWeeklyDuration(t *testing.T) {
 	}
 }
 
-func TestFormatDailyDurations(t *testing.T) {
+func TestFormatAbsenceTotals(t *testing.T) {
+	totals := []models.AbsenceTotal{
+		{Type: models.AbsenceVacation, Days: 3},
+		{Type: models.AbsenceSick, Days: 0.5},
+		{Type: models.AbsenceCompTime, Days: 1},
+	}
+	if got := formatAbsenceTotals(totals); got != "Vacation: 3 days  |  Sick leave: 0.5 days  |  Comp time: 1 day" {
+		t.Fatalf("unexpected absences string %q", got)
+	}
+}
+
+func TestDeltaLabel(t *testing.T) {
 	tests := []struct {
-		name     string
-		daily    [7]time.Duration
-		expected string
+		delta      models.ProjectDelta
+		text       string
+		importance widget.Importance
 	}{
-		{
-			name: "mixed values",
-			daily: [7]time.Duration{
-				time.Hour,
-				2 * time.Hour,
-				0,
-				15 * time.Minute,
-				30 * time.Minute,
-				0,
-				3*time.Hour + 10*time.Minute,
-			},
-			expected: "Mon: 1h 0m  |  Tue: 2h 0m  |  Wed: 0m  |  Thu: 15m\nFri: 30m  |  Sat: 0m  |  Sun: 3h 10m",
-		},
-		{
-			name: "all zeros",
-			daily: [7]time.Duration{
-				0, 0, 0, 0, 0, 0, 0,
-			},
-			expected: "Mon: 0m  |  Tue: 0m  |  Wed: 0m  |  Thu: 0m\nFri: 0m  |  Sat: 0m  |  Sun: 0m",
-		},
-	}
-	for _, tc := range tests {
-		tc := tc
-		t.Run(tc.name, func(t *testing.T) {
-			if got := formatDailyDurations(tc.daily); got != tc.expected {
-				t.Fatalf("unexpected daily string:\nexpected: %q\ngot:      %q", tc.expected, got)
-			}
-		})
+		{models.ProjectDelta{Current: 5 * time.Hour, Previous: 4 * time.Hour}, "▲ 1h 0m (+25%)", widget.SuccessImportance},
+		{models.ProjectDelta{Current: 90 * time.Minute, Previous: 2 * time.Hour}, "▼ 30m (-25%)", widget.WarningImportance},
+		{models.ProjectDelta{Current: time.Hour, Previous: time.Hour}, "= 0m", widget.LowImportance},
+	}
+	for _, tt := range tests {
+		label := deltaLabel(tt.delta)
+		if label.Text != tt.text || label.Importance != tt.importance {
+			t.Errorf("deltaLabel(%+v) = %q (%v), want %q (%v)", tt.delta, label.Text, label.Importance, tt.text, tt.importance)
+		}
+	}
+}
+
+func TestBudgetRow(t *testing.T) {
+	over := models.BudgetUsage{Budget: models.Budget{ProjectName: "Acme", Limit: 10 * time.Hour, Period: models.BudgetTotal}, Used: 12 * time.Hour}
+	row := budgetRow(over).(*fyne.Container)
+	usage := row.Objects[0].(*fyne.Container).Objects[0].(*widget.Label)
+	if usage.Text != "12h 0m of 10h 0m (120%) · 2h 0m over" || usage.Importance != widget.DangerImportance {
+		t.Errorf("unexpected usage %q (%v)", usage.Text, usage.Importance)
+	}
+	if bar := row.Objects[1].(*widget.ProgressBar); bar.Value != 1 {
+		t.Errorf("expected a full bar, got %v", bar.Value)
 	}
 }
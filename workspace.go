@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"trackyou/database"
+	"trackyou/state"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// openDatabase connects to and initializes the database at dbPath.
+func openDatabase(dbPath string) (*database.DB, error) {
+	db, err := database.NewDB(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := db.InitDB(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	return db, nil
+}
+
+// workspaceLabel names the open database for display: the workspace name,
+// or the file name when a path was given with --db or TRACKYOU_DB.
+func (a *App) workspaceLabel() string {
+	if a.workspace != "" {
+		return a.workspace
+	}
+	return filepath.Base(a.dbPath)
+}
+
+func (a *App) updateWindowTitle() {
+	if a.window == nil {
+		return
+	}
+	title := "TrackYou"
+	if label := a.workspaceLabel(); label != "" && label != "." {
+		title += " — " + label
+	}
+	a.window.SetTitle(title)
+}
+
+// loadTasks replaces the in-memory task list with the open database's tasks.
+func (a *App) loadTasks() error {
+	tasks, err := a.db.GetTasks()
+	if err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+	a.mu.Lock()
+	a.tasks = tasks
+	a.updateTaskGroups()
+	a.mu.Unlock()
+	return nil
+}
+
+// switchWorkspace closes the current database and opens the named
+// workspace. A running task is either stopped and saved in the current
+// workspace first, or carried over and saved in the new one when stopped.
+func (a *App) switchWorkspace(name string, carryOver bool) error {
+	dbPath, err := database.GetWorkspaceDBPath(name)
+	if err != nil {
+		return err
+	}
+	if dbPath == a.dbPath {
+		return nil
+	}
+	db, err := openDatabase(dbPath)
+	if err != nil {
+		return err
+	}
+
+	if !carryOver {
+		if _, err := a.finishTask(); err != nil && !errors.Is(err, errNoTaskRunning) {
+			db.Close()
+			return err
+		}
+	}
+
+	a.stopAPIServer()
+
+	// The old workspace's prompt state must stop showing a carried task.
+	a.mu.Lock()
+	carried := a.currentTask
+	a.currentTask = nil
+	a.mu.Unlock()
+	a.writeStateFile()
+
+	oldDB := a.db
+	a.mu.Lock()
+	a.db = db
+	a.dbPath = dbPath
+	a.workspace = name
+	a.statePath = state.PathForDB(dbPath)
+	a.currentTask = carried
+	a.goalReachedToday = false
+	a.mu.Unlock()
+	oldDB.Close()
+
+	a.loadPreferences()
+	if err := a.loadTasks(); err != nil {
+		return err
+	}
+
+	a.refreshProjectSuggestions()
+	a.updateSummaryUI(true)
+	if a.taskList != nil {
+		a.taskList.Refresh()
+	}
+	a.refreshWeeklyChart()
+	// Every view showing the old workspace's tasks or preferences is
+	// redrawn from the new one.
+	a.refreshFlex()
+	a.refreshAbsencesView()
+	a.refreshActivityView()
+	a.showBudgetProgress(time.Now())
+	a.writeStateFile()
+	a.updateWindowTitle()
+
+	if err := database.SetActiveWorkspace(name); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remember active workspace: %v\n", err)
+	}
+	return a.startAPIServer()
+}
+
+// showWorkspaceSwitcher lets the user pick an existing workspace or type a
+// new name, asking what to do with a running task.
+func (a *App) showWorkspaceSwitcher() {
+	names, err := database.ListWorkspaces()
+	if err != nil {
+		a.showDialogError(err)
+		return
+	}
+	entry := widget.NewSelectEntry(names)
+	entry.SetPlaceHolder("Workspace name")
+	entry.SetText(a.workspace)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Workspace", entry),
+	}
+
+	dialog.ShowForm("Switch Workspace", "Switch", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		name := strings.TrimSpace(entry.Text)
+		if err := database.ValidateWorkspaceName(name); err != nil {
+			a.showDialogError(err)
+			return
+		}
+
+		a.mu.RLock()
+		running := a.currentTask != nil
+		a.mu.RUnlock()
+		if !running {
+			if err := a.switchWorkspace(name, false); err != nil {
+				a.showDialogError(err)
+			}
+			return
+		}
+
+		// Only the buttons switch: closing the dialog any other way leaves
+		// the running task alone.
+		message := widget.NewLabel(fmt.Sprintf("A task is running. Carry it over to %q, or stop and save it in %q first?", name, a.workspaceLabel()))
+		message.Wrapping = fyne.TextWrapWord
+		var d *dialog.CustomDialog
+		choose := func(carryOver bool) {
+			d.Hide()
+			if err := a.switchWorkspace(name, carryOver); err != nil {
+				a.showDialogError(err)
+			}
+		}
+		cancel := widget.NewButton("Cancel", func() { d.Hide() })
+		stop := widget.NewButton("Stop & Save", func() { choose(false) })
+		carry := widget.NewButton("Carry Over", func() { choose(true) })
+		carry.Importance = widget.HighImportance
+
+		d = dialog.NewCustomWithoutButtons("Task Running", message, a.window)
+		d.SetButtons([]fyne.CanvasObject{cancel, stop, carry})
+		d.Resize(fyne.NewSize(420, d.MinSize().Height))
+		d.Show()
+	}, a.window)
+}